@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/deploy"
+	"github.com/samuel-k-w/meba-cli/internal/generator"
+	"github.com/spf13/cobra"
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add infrastructure to the project",
+	Long:  `Add optional infrastructure such as deployment manifests, tracing or a cache to an existing project.`,
+}
+
+var addDeployCmd = &cobra.Command{
+	Use:       "deploy [k8s|helm|compose|systemd]",
+	Short:     "Generate deployment manifests",
+	ValidArgs: deploy.Targets,
+	Long: `Generate deployment files in deployments/<target>, parameterised from
+configs/config.yaml:
+
+  k8s      Deployment with liveness/readiness probes on
+           monitoring.health_check.path, Service, ConfigMap with the
+           config, Secret for jwt.secret and database.password, HPA and a
+           Prometheus ServiceMonitor on monitoring.prometheus.path
+  helm     the same resources as a Helm chart
+  compose  a production docker compose file using the built image
+  systemd  a hardened unit, its environment file and config
+
+The first deployment also adds the deploy section (image, replicas,
+resources, autoscaling) to configs/config.yaml and the environment
+overrides deployments/env/dev.yaml and prod.yaml. Inspect the result with
+meba deploy render --env prod.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		target := args[0]
+		if err := generator.GenerateDeploy(target, dryRun); err != nil {
+			color.Red("Error generating %s deployment: %v", target, err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		color.Green("✅ %s deployment generated in deployments/%s", target, target)
+		fmt.Printf("📝 Render it with: meba deploy render --env prod --target %s\n", target)
+	},
+}
+
+var addTracingCmd = &cobra.Command{
+	Use:   "tracing",
+	Short: "Set up OpenTelemetry tracing",
+	Long: `Add pkg/tracing and wire it into the project:
+
+  - a tracer provider configured by the tracing section of
+    configs/config.yaml, exporting over OTLP (http or grpc) or to stdout
+    for local testing without a collector, named after app.name
+  - Gin middleware starting a span per request
+  - the GORM plugin recording a span per query
+  - an instrumented *http.Client provider in TracingSet
+  - tracing.Logger(ctx, logger) adding trace_id and span_id to zap logs
+
+The OpenTelemetry modules are added to go.mod unless --skip-install.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := generator.GenerateTracing(dryRun, skipInstall); err != nil {
+			color.Red("Error setting up tracing: %v", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		color.Green("✅ Tracing set up in pkg/tracing")
+		fmt.Println("📝 Regenerate wire with: meba wire")
+		fmt.Println("📝 Try it without a collector: TRACING_EXPORTER=stdout meba start")
+	},
+}
+
+var cacheDriver string
+
+var addCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Set up a cache backed by memory or Redis",
+	Long: `Add pkg/cache and wire it into the project:
+
+  - a Cache interface with an in-memory driver, and a Redis driver
+    connecting with the redis section of configs/config.yaml for
+    --driver redis; cache.driver selects one, cache.default_ttl and
+    cache.cleanup_interval configure them
+  - CacheSet providing it to the app, which closes it on shutdown, and
+    MemoryCacheSet for InitializeTestApp so the e2e tests run offline
+  - cache.Remember, reading a value through the cache
+  - cache.Middleware, caching the responses of GET routes
+
+Resources generated with meba g resource --cache read GetByID and GetAll
+through it. The Redis client is added to go.mod unless --skip-install.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := generator.GenerateCache(cacheDriver, dryRun, skipInstall); err != nil {
+			color.Red("Error setting up the cache: %v", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		color.Green("✅ Cache set up in pkg/cache with the %s driver", cacheDriver)
+		fmt.Println("📝 Regenerate wire with: meba wire")
+		fmt.Println("📝 Cache a resource with: meba g resource <name> --cache")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(addCmd)
+	addCmd.AddCommand(addDeployCmd)
+	addCmd.AddCommand(addTracingCmd)
+	addCmd.AddCommand(addCacheCmd)
+	addDeployCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	addTracingCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	addTracingCmd.Flags().BoolVar(&skipInstall, "skip-install", false, "Skip adding the OpenTelemetry modules to go.mod")
+	addCacheCmd.Flags().StringVar(&cacheDriver, "driver", "memory", "Cache driver: memory or redis")
+	addCacheCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	addCacheCmd.Flags().BoolVar(&skipInstall, "skip-install", false, "Skip adding the Redis client to go.mod")
+	addDeployCmd.Example = "  meba add deploy " + strings.Join(deploy.Targets, "\n  meba add deploy ")
+}
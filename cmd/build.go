@@ -5,24 +5,51 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"time"
 
+	"github.com/samuel-k-w/meba-cli/internal/builder"
+	"github.com/samuel-k-w/meba-cli/internal/generator"
 	"github.com/spf13/cobra"
 )
 
 var (
 	buildWatchFlag bool
+	buildTargets   []string
+	buildOutput    string
+	buildVersion   string
+	buildTags      []string
+	buildLDFlags   string
+	buildTrimpath  bool
+	buildCGO       bool
+
+	buildImageRef      string
+	buildImageOutput   string
+	buildImagePlatform string
+	buildImageBase     string
 )
 
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "Build the application",
-	Long:  "Compile the Go project to executable",
+	Long: `Compile ./cmd/server into dist/server, or into dist/server_<os>_<arch>
+for each --target. The version, commit and date are injected into
+internal/version with -ldflags: the version comes from git describe and
+the date from SOURCE_DATE_EPOCH or the commit time, so the same commit
+builds the same binary.
+
+With --image, a static linux binary is also packed into an OCI image
+tarball (dist/image.tar) on top of the scratch base or a base image read
+from disk, loadable with docker load or podman load. No Docker daemon is
+needed.
+
+dist/manifest.json records the build settings, the SHA-256 and size of
+each binary and image, and the Go modules compiled into them.
+
+Flags default to the build section of configs/config.yaml.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if buildWatchFlag {
-			buildWithWatch()
+			buildWithWatch(cmd)
 		} else {
-			if err := buildApp(); err != nil {
+			if err := buildApp(cmd); err != nil {
 				fmt.Printf("❌ Build failed: %v\n", err)
 				os.Exit(1)
 			}
@@ -34,81 +61,166 @@ var buildCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(buildCmd)
 	buildCmd.Flags().BoolVarP(&buildWatchFlag, "watch", "w", false, "Continuous compilation")
+	buildCmd.Flags().BoolVar(&skipWire, "skip-wire", false, "Use the existing wire_gen.go instead of regenerating it")
+	buildCmd.Flags().StringSliceVar(&buildTargets, "target", nil, "Platforms to build for as os/arch, e.g. linux/amd64,darwin/arm64")
+	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "", "Binary path (default dist/server)")
+	buildCmd.Flags().StringVar(&buildVersion, "version", "", "Version to inject (default git describe)")
+	buildCmd.Flags().StringSliceVar(&buildTags, "tags", nil, "Build tags")
+	buildCmd.Flags().StringVar(&buildLDFlags, "ldflags", "", "Extra linker flags (default build.ldflags)")
+	buildCmd.Flags().BoolVar(&buildTrimpath, "trimpath", true, "Remove file system paths from the binary")
+	buildCmd.Flags().BoolVar(&buildCGO, "cgo", false, "Set CGO_ENABLED (default left to go)")
+	buildCmd.Flags().StringVar(&buildImageRef, "image", "", "Also write an OCI image tarball tagged name[:tag] (tag defaults to the version)")
+	buildCmd.Flags().StringVar(&buildImageOutput, "image-output", "", "Image tarball path (default dist/image.tar)")
+	buildCmd.Flags().StringVar(&buildImagePlatform, "platform", "", "Image platform (default image.platform or linux/<host arch>)")
+	buildCmd.Flags().StringVar(&buildImageBase, "base", "", "Base image: scratch, an OCI layout or docker save archive, or a cached name (default image.base)")
 }
 
-func buildApp() error {
+func buildApp(cmd *cobra.Command) error {
+	opts, err := buildOptions(cmd)
+	if err != nil {
+		return err
+	}
+	manifest, err := runBuild(&opts)
+	if err != nil {
+		return err
+	}
+	if buildImageRef != "" {
+		if err := buildImage(cmd, opts, manifest); err != nil {
+			return err
+		}
+	}
+
+	manifestPath := filepath.Join(filepath.Dir(opts.ArtifactPath(builder.Target{})), "manifest.json")
+	if err := manifest.Write(manifestPath); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	fmt.Printf("📋 Manifest written to %s\n", manifestPath)
+	return nil
+}
+
+// runBuild generates the wire code and builds the binaries, setting the
+// version package of opts
+func runBuild(opts *builder.Options) (*builder.Manifest, error) {
 	fmt.Println("🔨 Building application...")
-	
-	// Create dist directory
-	if err := os.MkdirAll("dist", 0755); err != nil {
-		return fmt.Errorf("failed to create dist directory: %w", err)
-	}
-	
-	// Auto-generate wire before building
-	fmt.Println("🔧 Generating wire dependencies...")
-	wireCmd := exec.Command("wire", "./internal")
-	wireCmd.Run() // Don't fail if wire fails
-	
-	// Build the application
-	outputPath := filepath.Join("dist", "server")
-	cmd := exec.Command("go", "build", "-o", outputPath, "./cmd/server")
+
+	if skipWire {
+		fmt.Println("⏭️  Skipping wire generation")
+	} else if err := runWire(); err != nil {
+		return nil, err
+	}
+
+	versionPkg, created, err := generator.EnsureVersionPackage()
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		fmt.Println("📝 Created internal/version to hold the build information")
+	}
+	opts.VersionPkg = versionPkg
+
+	for _, t := range opts.Targets {
+		fmt.Printf("   %s -> %s\n", t, opts.ArtifactPath(t))
+	}
+	manifest, err := builder.Build(*opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("🏷️  %s (commit %s, %s)\n", manifest.Version, shortCommit(manifest.Commit), manifest.Date)
+	printArtifacts(manifest.Artifacts)
+	return manifest, nil
+}
+
+func printArtifacts(artifacts []builder.Artifact) {
+	for _, a := range artifacts {
+		fmt.Printf("📦 %s  %s/%s  %.1f MB  sha256:%s\n", a.Path, a.OS, a.Arch, float64(a.Size)/(1<<20), a.SHA256[:12])
+	}
+}
+
+// buildOptions merges the build flags with the build section of
+// configs/config.yaml
+func buildOptions(cmd *cobra.Command) (builder.Options, error) {
+	cfg := readProjectConfig()
+	flags := cmd.Flags()
+
+	opts := builder.Options{
+		Output:   buildOutput,
+		Tags:     buildTags,
+		LDFlags:  buildLDFlags,
+		Trimpath: buildTrimpath,
+		Stamp:    builder.Stamp{Version: buildVersion},
+	}
+	if opts.Output == "" {
+		opts.Output = cfg.GetString("build.output")
+	}
+	if !flags.Changed("tags") {
+		opts.Tags = cfg.GetStringSlice("build.tags")
+	}
+	if !flags.Changed("ldflags") {
+		opts.LDFlags = cfg.GetString("build.ldflags")
+	}
+	if !flags.Changed("trimpath") && cfg.IsSet("build.trimpath") {
+		opts.Trimpath = cfg.GetBool("build.trimpath")
+	}
+	if flags.Changed("cgo") {
+		opts.CGO = &buildCGO
+	} else if cfg.IsSet("build.cgo") {
+		cgo := cfg.GetBool("build.cgo")
+		opts.CGO = &cgo
+	}
+
+	targets := buildTargets
+	if !flags.Changed("target") {
+		targets = cfg.GetStringSlice("build.targets")
+	}
+	parsed, err := builder.ParseTargets(targets)
+	if err != nil {
+		return opts, err
+	}
+	opts.Targets = parsed
+	return opts, nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// compileServer builds ./cmd/server into output, printing compiler errors
+// as they come
+func compileServer(output string) error {
+	cmd := exec.Command("go", "build", "-o", output, "./cmd/server")
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	
+
 	return cmd.Run()
 }
 
-func buildWithWatch() {
+func buildWithWatch(cmd *cobra.Command) {
 	fmt.Println("🔄 Starting continuous compilation...")
-	
-	// Initial build
-	if err := buildApp(); err != nil {
-		fmt.Printf("❌ Initial build failed: %v\n", err)
-	}
-	
-	// Watch for changes (simple implementation)
-	fmt.Println("👀 Watching for changes... (Press Ctrl+C to stop)")
-	
-	lastBuild := time.Now()
-	for {
-		time.Sleep(2 * time.Second)
-		
-		// Check if any .go files have been modified
-		if shouldRebuild(lastBuild) {
-			fmt.Println("📝 Changes detected, rebuilding...")
-			if err := buildApp(); err != nil {
-				fmt.Printf("❌ Build failed: %v\n", err)
-			} else {
-				fmt.Println("✅ Build completed!")
-			}
-			lastBuild = time.Now()
-		}
-	}
-}
 
-func shouldRebuild(lastBuild time.Time) bool {
-	// Simple file modification check
-	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		
-		// Skip vendor, node_modules, dist, tmp directories
-		if info.IsDir() {
-			name := info.Name()
-			if name == "vendor" || name == "node_modules" || name == "dist" || name == "tmp" || name == ".git" {
-				return filepath.SkipDir
+	runWatch(watchConfig(), func(changed []string) {
+		if changed == nil {
+			if err := buildApp(cmd); err != nil {
+				fmt.Printf("❌ Initial build failed: %v\n", err)
 			}
-			return nil
+			return
 		}
-		
-		// Check .go files
-		if filepath.Ext(path) == ".go" && info.ModTime().After(lastBuild) {
-			return fmt.Errorf("rebuild needed")
+		if onlyTests(changed) {
+			return
 		}
-		
-		return nil
+		// Rebuild with the same options, running wire only when the
+		// wiring may have changed
+		wireSkipped := skipWire
+		skipWire = skipWire || !needsWire(changed)
+		err := buildApp(cmd)
+		skipWire = wireSkipped
+		if err != nil {
+			fmt.Printf("❌ Build failed: %v\n", err)
+			return
+		}
+		fmt.Println("✅ Build completed!")
 	})
-	
-	return err != nil
-}
\ No newline at end of file
+}
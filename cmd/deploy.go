@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/deploy"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deployEnv     string
+	deployTargets []string
+	deployOut     string
+)
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Work with the deployment manifests",
+}
+
+var deployRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render the deployment manifests for an environment",
+	Long: `Render the templates in deployments/ with configs/config.yaml and
+deployments/env/<env>.yaml merged over it. Secrets are read from
+JWT_SECRET and DATABASE_PASSWORD; missing ones render as CHANGE_ME.
+
+The manifests are printed for inspection or kubectl apply -f -, or
+written to --out. The Helm chart is rendered with helm template; without
+helm it is skipped with a warning, unless --target helm asks for it.`,
+	Example: `  meba deploy render --env prod
+  JWT_SECRET=... DATABASE_PASSWORD=... meba deploy render --env prod --target k8s | kubectl apply -f -
+  meba deploy render --env dev --out dist/deploy/dev`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := renderDeploy(); err != nil {
+			color.Red("❌ Render failed: %v", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.AddCommand(deployRenderCmd)
+	deployRenderCmd.Flags().StringVarP(&deployEnv, "env", "e", "", "Environment in deployments/env (default: configs/config.yaml only)")
+	deployRenderCmd.Flags().StringSliceVarP(&deployTargets, "target", "t", nil, "Targets to render (default: all in deployments/)")
+	deployRenderCmd.Flags().StringVarP(&deployOut, "out", "o", "", "Write the manifests to this directory instead of stdout")
+}
+
+func renderDeploy() error {
+	targets := deployTargets
+	if len(targets) == 0 {
+		targets = deploy.Installed(".")
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no deployments found, generate one with meba add deploy k8s")
+	}
+
+	values, err := deploy.Load(".", deployEnv)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, target := range targets {
+		result, err := deploy.Render(".", target, values)
+		// Helm is only required when the helm target is asked for
+		if errors.Is(err, deploy.ErrHelmNotInstalled) && len(deployTargets) == 0 {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping helm: %v\n", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, env := range result.MissingSecrets {
+			if !contains(missing, env) {
+				missing = append(missing, env)
+			}
+		}
+		for _, file := range result.Files {
+			if deployOut == "" {
+				fmt.Printf("---\n# Source: %s\n%s", file.Path, file.Content)
+				continue
+			}
+			out := filepath.Join(deployOut, strings.TrimPrefix(file.Path, "deployments/"))
+			if target == "helm" {
+				out = filepath.Join(deployOut, "helm", "manifests.yaml")
+			}
+			if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, []byte(file.Content), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "📄 %s\n", out)
+		}
+	}
+
+	// Warnings go to stderr to keep the output pipeable
+	if len(missing) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️  %s not set, rendered as %s\n", strings.Join(missing, ", "), deploy.SecretPlaceholder)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/samuel-k-w/meba-cli/internal/generator"
@@ -13,6 +16,12 @@ var (
 	flat    bool
 	noSpec  bool
 	project string
+
+	metricType   string
+	metricLabels []string
+
+	pagination string
+	cached     bool
 )
 
 var generateCmd = &cobra.Command{
@@ -87,7 +96,7 @@ var resourceCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
-		if err := generator.GenerateResource(name, dryRun, noSpec); err != nil {
+		if err := generator.GenerateResource(name, dryRun, noSpec, pagination, cached); err != nil {
 			color.Red("Error generating resource: %v", err)
 			os.Exit(1)
 		}
@@ -123,6 +132,142 @@ var guardCmd = &cobra.Command{
 	},
 }
 
+var generateE2ECmd = &cobra.Command{
+	Use:   "e2e [name]",
+	Short: "Generate the e2e harness, or CRUD e2e tests for a resource",
+	Long: `Generate the e2e harness in test/e2e, which boots the real app on a
+fresh SQLite database per test. With a resource name, also generate
+test/e2e/<name>_test.go with CRUD tests for it.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		basePath := routesBasePathOrDefault()
+		if len(args) == 0 {
+			if err := generator.GenerateE2EHarness(basePath, dryRun); err != nil {
+				color.Red("Error generating e2e harness: %v", err)
+				os.Exit(1)
+			}
+			color.Green("✅ E2E harness generated in test/e2e!")
+			return
+		}
+
+		name := args[0]
+		if err := generator.GenerateE2EResource(name, basePath, dryRun); err != nil {
+			color.Red("Error generating e2e tests: %v", err)
+			os.Exit(1)
+		}
+		color.Green("✅ E2E tests for '%s' generated in test/e2e/%s_test.go", name, name)
+	},
+}
+
+var generateMocksCmd = &cobra.Command{
+	Use:   "mocks [module]",
+	Short: "Generate testify mocks for the interfaces of a module",
+	Long: `Generate testify mocks for the exported interfaces of internal/<module>,
+or of every module, into internal/<module>/mocks. Mocks are named after
+the interface without the Interface suffix, e.g. mocks.NewService(t)
+for ServiceInterface, and assert their expectations when the test ends.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		fmt.Println("🎭 Generating mocks...")
+		files, err := generator.GenerateMocks(name)
+		if err != nil {
+			color.Red("Error generating mocks: %v", err)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			color.Yellow("No exported interfaces found")
+			return
+		}
+		for _, file := range files {
+			var names []string
+			for mock := range file.Mocks {
+				names = append(names, mock)
+			}
+			sort.Strings(names)
+			color.Green("✅ Wrote %s (%s)", file.Path, strings.Join(names, ", "))
+			for _, skipped := range file.Skipped {
+				color.Yellow("   Skipped %s: it refers to unexported types", skipped)
+			}
+		}
+	},
+}
+
+var generateHealthCheckCmd = &cobra.Command{
+	Use:     "health-check [name]",
+	Aliases: []string{"hc"},
+	Short:   "Generate a custom health checker",
+	Long: `Generate a checker in internal/checks/<name>.go and register it in
+internal/health.go, so wire injects it into the checks served on
+/health/ready. Implement its Check method; it runs within its Timeout.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := generator.GenerateHealthCheck(name, dryRun); err != nil {
+			color.Red("Error generating health check: %v", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		color.Green("✅ Health check '%s' generated in internal/checks/%s.go", name, name)
+		fmt.Println("📝 Run wire (or meba build) to inject it")
+	},
+}
+
+var generateMetricCmd = &cobra.Command{
+	Use:   "metric [module] [name]",
+	Short: "Generate a custom Prometheus metric for a module",
+	Long: `Generate internal/<module>/<name>_metric.go with a counter, gauge or
+histogram registered on the application registry, and add its
+constructor to the module's wire set. Inject it into a service to record
+it; it is served with the HTTP and runtime metrics on
+monitoring.prometheus.path. Counter names get a _total suffix.`,
+	Example: `  meba g metric orders orders_created --labels status
+  meba g metric orders checkout_duration_seconds --type histogram`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		module, name := args[0], args[1]
+		if err := generator.GenerateMetric(module, name, metricType, metricLabels, dryRun); err != nil {
+			color.Red("Error generating metric: %v", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		color.Green("✅ Metric '%s' generated in internal/%s", name, module)
+		fmt.Println("📝 Run wire (or meba build) to inject it")
+	},
+}
+
+var generateValidatorCmd = &cobra.Command{
+	Use:     "validator [name]",
+	Aliases: []string{"va"},
+	Short:   "Generate a custom validation rule",
+	Long: `Generate pkg/validator/<name>.go with a rule registered at startup
+under the validate tag <name>, e.g. validate:"required,<name>". Implement
+its function and edit its message; {0} is the field name.`,
+	Example: `  meba g validator strong_password`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := generator.GenerateValidator(name, dryRun); err != nil {
+			color.Red("Error generating validator: %v", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+		tag := strings.ReplaceAll(name, "-", "_")
+		color.Green("✅ Validator '%s' generated in pkg/validator/%s.go", tag, tag)
+		fmt.Printf("📝 Use it as validate:\"%s\" in your DTOs\n", tag)
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(generateCmd)
 	
@@ -134,6 +279,19 @@ func init() {
 	generateCmd.AddCommand(resourceCmd)
 	generateCmd.AddCommand(middlewareCmd)
 	generateCmd.AddCommand(guardCmd)
+	generateCmd.AddCommand(generateE2ECmd)
+	generateCmd.AddCommand(generateMocksCmd)
+	generateCmd.AddCommand(generateHealthCheckCmd)
+	generateHealthCheckCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	generateCmd.AddCommand(generateMetricCmd)
+	generateCmd.AddCommand(generateValidatorCmd)
+	generateValidatorCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	generateMetricCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
+	resourceCmd.Flags().StringVar(&pagination, "pagination", "offset", "List pagination: offset (page, page_size and a total) or cursor (keyset, no total)")
+	resourceCmd.Flags().BoolVar(&cached, "cache", false, "Read GetByID and GetAll through pkg/cache, invalidated by writes (needs meba add cache)")
+	generateMetricCmd.Flags().StringVar(&metricType, "type", "counter", "Metric type: counter, gauge or histogram")
+	generateMetricCmd.Flags().StringSliceVar(&metricLabels, "labels", nil, "Label names, e.g. status,method")
+	generateE2ECmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show files to be created without writing")
 
 	// Add flags to all generate commands
 	for _, cmd := range []*cobra.Command{moduleCmd, handlerCmd, serviceCmd, repositoryCmd, resourceCmd, middlewareCmd, guardCmd} {
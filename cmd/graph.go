@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/wiregraph"
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat string
+	graphOutput string
+	graphCheck  bool
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Visualise and validate the dependency graph",
+	Long: `Load the wire sets (AppSet and each <module>.Module) and the
+InitializeApp injector, render the provider graph and report problems:
+
+  missing    a provider needs a type nothing provides
+  duplicate  a type is provided more than once
+  cycle      providers depend on each other
+  unused     no injector needs a module provider (warning only)
+
+The graph is written as DOT (default), Mermaid or JSON. Use --check to
+only validate. The command exits with status 1 when wire would fail.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		graph, problems, err := loadWireGraph()
+		if err != nil {
+			color.Red("Error loading wire sets: %v", err)
+			os.Exit(1)
+		}
+
+		if !graphCheck {
+			data, err := graph.Render(graphFormat, problems)
+			if err != nil {
+				color.Red("Error rendering graph: %v", err)
+				os.Exit(1)
+			}
+			if graphOutput == "" {
+				os.Stdout.Write(data)
+			} else {
+				if err := os.WriteFile(graphOutput, data, 0644); err != nil {
+					color.Red("Error writing graph: %v", err)
+					os.Exit(1)
+				}
+				color.Green("✅ Dependency graph written to %s", graphOutput)
+			}
+		}
+
+		if reportWireProblems(problems) > 0 {
+			os.Exit(1)
+		}
+		if graphCheck {
+			color.Green("✅ Dependency graph is valid (%d providers, %d sets)", len(graph.Providers), len(graph.Sets))
+		}
+	},
+}
+
+// loadWireGraph loads the wire graph of the current project and checks it
+func loadWireGraph() (*wiregraph.Graph, []*wiregraph.Problem, error) {
+	graph, err := wiregraph.Load(".")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, w := range graph.Warnings {
+		color.Yellow("⚠️  %s", w)
+	}
+	return graph, graph.Check(), nil
+}
+
+// reportWireProblems prints problems to stderr and returns the number of
+// errors among them
+func reportWireProblems(problems []*wiregraph.Problem) int {
+	errors := 0
+	for _, p := range problems {
+		if p.IsError() {
+			errors++
+			fmt.Fprintln(os.Stderr, color.RedString("❌ %s: %s", p.Kind, p.Message))
+		} else {
+			fmt.Fprintln(os.Stderr, color.YellowString("⚠️  %s: %s", p.Kind, p.Message))
+		}
+		fmt.Fprintf(os.Stderr, "   💡 %s\n", p.Hint)
+	}
+	return errors
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", "dot", "Output format: dot, mermaid or json")
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write the graph to a file instead of stdout")
+	graphCmd.Flags().BoolVar(&graphCheck, "check", false, "Only validate the graph")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/builder"
+	"github.com/spf13/cobra"
+)
+
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// buildImage writes the image tarball for meba build --image from a
+// static binary of the build, or one built for it, and records it in the
+// manifest
+func buildImage(cmd *cobra.Command, opts builder.Options, manifest *builder.Manifest) error {
+	cfg := readProjectConfig()
+	flags := cmd.Flags()
+
+	platform := buildImagePlatform
+	if platform == "" {
+		platform = cfg.GetString("image.platform")
+	}
+	if platform == "" {
+		platform = "linux/" + runtime.GOARCH
+	}
+	targets, err := builder.ParseTargets([]string{platform})
+	if err != nil {
+		return err
+	}
+	if len(targets) != 1 || targets[0].OS != "linux" {
+		return fmt.Errorf("image platform must be a single linux/<arch>, got %q", platform)
+	}
+	target := targets[0]
+
+	ref := imageRef(buildImageRef, manifest.Version)
+	fmt.Printf("🐳 Building image %s for %s...\n", ref, target)
+
+	binary := ""
+	for _, a := range manifest.Artifacts {
+		if a.OS == target.OS && a.Arch == target.Arch {
+			if static, _ := builder.IsStatic(a.Path); static {
+				binary = a.Path
+				break
+			}
+		}
+	}
+	if binary == "" {
+		noCGO := false
+		imageOpts := opts
+		imageOpts.Targets = []builder.Target{target}
+		imageOpts.CGO = &noCGO
+		output := opts.ArtifactPath(builder.Target{})
+		imageOpts.Output = filepath.Join(filepath.Dir(output), "image", filepath.Base(output))
+		extra, err := builder.Build(imageOpts)
+		if err != nil {
+			return err
+		}
+		printArtifacts(extra.Artifacts)
+		manifest.Merge(extra)
+		binary = extra.Artifacts[0].Path
+	}
+
+	base := buildImageBase
+	if !flags.Changed("base") {
+		base = cfg.GetString("image.base")
+	}
+	output := buildImageOutput
+	if output == "" {
+		output = filepath.Join(filepath.Dir(opts.ArtifactPath(builder.Target{})), "image.tar")
+	}
+	port := cfg.GetInt("app.port")
+	if port == 0 {
+		port = 8080
+	}
+
+	imageOpts := builder.ImageOptions{
+		Ref:      ref,
+		Output:   output,
+		Binary:   binary,
+		Platform: target,
+		Base:     base,
+		User:     cfg.GetString("image.user"),
+		Port:     port,
+		Env:      cfg.GetStringSlice("image.env"),
+		Labels:   map[string]string{},
+		Include:  []string{"configs"},
+		Stamp:    builder.Stamp{Version: manifest.Version, Commit: manifest.Commit, Date: manifest.Date},
+	}
+	if cfg.IsSet("image.include") {
+		imageOpts.Include = cfg.GetStringSlice("image.include")
+	}
+	for _, label := range cfg.GetStringSlice("image.labels") {
+		key, value, _ := strings.Cut(label, "=")
+		imageOpts.Labels[key] = value
+	}
+
+	if main, err := os.ReadFile(filepath.Join("cmd", "server", "main.go")); err != nil || !strings.Contains(string(main), `"healthcheck"`) {
+		color.Yellow("⚠️  cmd/server/main.go has no healthcheck command, so the image has no healthcheck")
+	} else {
+		path := cfg.GetString("monitoring.health_check.path")
+		if path == "" {
+			path = routesBasePathOrDefault() + "/health"
+		}
+		imageOpts.HealthcheckURL = fmt.Sprintf("http://127.0.0.1:%d%s", port, path)
+		imageOpts.HealthcheckInterval = 30 * time.Second
+		imageOpts.HealthcheckTimeout = 3 * time.Second
+		imageOpts.HealthcheckRetries = 3
+		if interval := cfg.GetDuration("image.healthcheck.interval"); interval > 0 {
+			imageOpts.HealthcheckInterval = interval
+		}
+		if timeout := cfg.GetDuration("image.healthcheck.timeout"); timeout > 0 {
+			imageOpts.HealthcheckTimeout = timeout
+		}
+		if retries := cfg.GetInt("image.healthcheck.retries"); retries > 0 {
+			imageOpts.HealthcheckRetries = retries
+		}
+	}
+
+	image, err := builder.BuildImage(imageOpts)
+	if err != nil {
+		return err
+	}
+	manifest.Images = append(manifest.Images, *image)
+	fmt.Printf("🐳 %s  %s  %.1f MB  %s\n", image.Path, image.Ref, float64(image.Size)/(1<<20), image.Digest)
+	fmt.Printf("   Load it with: docker load -i %s\n", image.Path)
+	return nil
+}
+
+// imageRef adds the version as tag when ref has none
+func imageRef(ref, version string) string {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref
+	}
+	tag := invalidTagChars.ReplaceAllString(version, "-")
+	if tag == "" || strings.HasPrefix(tag, ".") || strings.HasPrefix(tag, "-") {
+		tag = "latest"
+	}
+	if len(tag) > 128 {
+		tag = tag[:128]
+	}
+	return ref + ":" + tag
+}
@@ -113,11 +113,8 @@ func showDevTools() {
 	fmt.Println("----------------------")
 	
 	tools := map[string]string{
-		"wire":      "github.com/google/wire/cmd/wire",
-		"air":       "github.com/cosmtrek/air",
 		"gotestsum": "gotest.tools/gotestsum",
 		"swag":      "github.com/swaggo/swag/cmd/swag",
-		"mockgen":   "github.com/golang/mock/mockgen",
 	}
 	
 	for tool, pkg := range tools {
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// readProjectConfig loads configs/config.yaml of the project in the current
+// directory. A missing or invalid file yields an empty config so callers can
+// fall back to their defaults.
+func readProjectConfig() *viper.Viper {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join("configs", "config.yaml"))
+	v.ReadInConfig()
+	return v
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	routesJSON     bool
+	routesCheck    bool
+	routesBasePath string
+)
+
+var routesCmd = &cobra.Command{
+	Use:   "routes",
+	Short: "List all HTTP routes",
+	Long: `List every HTTP route of the project without starting it.
+
+Routes are found by statically following internal.Handlers and the
+SetupRoutes methods of each module. Each route shows its method, full
+path, handler, middleware, guards and where it is registered.
+
+Use --check to fail when a route is registered twice or when wildcards
+conflict, which would make gin panic at startup.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		project, err := analyzer.Load(analyzer.Options{Dir: ".", BasePath: routesBasePathOrDefault()})
+		if err != nil {
+			color.Red("Error analyzing routes: %v", err)
+			os.Exit(1)
+		}
+
+		if routesJSON {
+			if err := printRoutesJSON(project.Routes); err != nil {
+				color.Red("Error encoding routes: %v", err)
+				os.Exit(1)
+			}
+		} else {
+			for _, w := range project.Warnings {
+				color.Yellow("⚠️  %s", w)
+			}
+			printRoutesTable(project.Routes)
+		}
+
+		if !routesCheck {
+			return
+		}
+		problems := analyzer.CheckRoutes(project.Routes)
+		if len(problems) == 0 {
+			if !routesJSON {
+				color.Green("✅ No duplicate or conflicting routes")
+			}
+			return
+		}
+		for _, p := range problems {
+			if p.Kind == analyzer.ProblemDuplicate {
+				color.Red("❌ %s %s is registered twice", p.Route.Method, p.Route.Path)
+			} else {
+				color.Red("❌ %s %s conflicts with %s %s", p.Route.Method, p.Route.Path, p.Other.Method, p.Other.Path)
+			}
+			fmt.Fprintf(os.Stderr, "   %s\n   %s\n   %s\n", p.Reason, location(p.Route), location(p.Other))
+		}
+		os.Exit(1)
+	},
+}
+
+// routeInfo is the --json representation of a route
+type routeInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Handler    string   `json:"handler"`
+	Middleware []string `json:"middleware"`
+	Guards     []string `json:"guards"`
+	Mounted    bool     `json:"mounted"`
+	Location   string   `json:"location"`
+}
+
+func newRouteInfo(r *analyzer.Route) routeInfo {
+	info := routeInfo{
+		Method:     r.Method,
+		Path:       r.Path,
+		Handler:    "-",
+		Middleware: []string{},
+		Guards:     []string{},
+		Mounted:    r.Mounted,
+		Location:   location(r),
+	}
+	if r.Handler != nil {
+		info.Handler = r.Handler.Name
+	}
+	for _, mw := range r.Middleware {
+		if analyzer.IsGuard(mw) {
+			info.Guards = append(info.Guards, mw)
+		} else {
+			info.Middleware = append(info.Middleware, mw)
+		}
+	}
+	return info
+}
+
+func printRoutesJSON(routes []*analyzer.Route) error {
+	infos := make([]routeInfo, 0, len(routes))
+	for _, r := range routes {
+		infos = append(infos, newRouteInfo(r))
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(infos)
+}
+
+func printRoutesTable(routes []*analyzer.Route) {
+	if len(routes) == 0 {
+		color.Yellow("No routes found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tHANDLER\tMIDDLEWARE\tGUARDS\tLOCATION")
+	unmounted := 0
+	for _, r := range routes {
+		info := newRouteInfo(r)
+		path := info.Path
+		if !info.Mounted {
+			path += " *"
+			unmounted++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", info.Method, path, info.Handler,
+			orDash(info.Middleware), orDash(info.Guards), info.Location)
+	}
+	w.Flush()
+
+	fmt.Printf("\n📋 %d routes\n", len(routes))
+	if unmounted > 0 {
+		color.Yellow("* %d routes come from SetupRoutes methods not reached from internal.Handlers; their prefix is assumed to be %s", unmounted, routesBasePathOrDefault())
+	}
+}
+
+func routesBasePathOrDefault() string {
+	if routesBasePath != "" {
+		return routesBasePath
+	}
+	if basePath := readProjectConfig().GetString("swagger.base_path"); basePath != "" {
+		return basePath
+	}
+	return "/api/v1"
+}
+
+// location renders where a route is registered relative to the project
+func location(r *analyzer.Route) string {
+	file := r.Position.Filename
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return fmt.Sprintf("%s:%d", file, r.Position.Line)
+}
+
+func orDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(routesCmd)
+	routesCmd.Flags().BoolVar(&routesJSON, "json", false, "Print routes as JSON")
+	routesCmd.Flags().BoolVar(&routesCheck, "check", false, "Fail on duplicate or conflicting routes")
+	routesCmd.Flags().StringVar(&routesBasePath, "base-path", "", "Prefix for modules not mounted from internal.Handlers (default: swagger.base_path or /api/v1)")
+}
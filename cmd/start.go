@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/builder"
+	"github.com/samuel-k-w/meba-cli/internal/watcher"
 	"github.com/spf13/cobra"
 )
 
@@ -21,37 +25,42 @@ var startCmd = &cobra.Command{
 		if watchFlag {
 			startWithWatch()
 		} else {
-			startProduction()
+			startProduction(cmd)
 		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
-	startCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Start with live-reload using Air")
-	startCmd.Flags().BoolVar(&debugFlag, "debug", false, "Enable debug mode")
+	startCmd.Flags().BoolVarP(&watchFlag, "watch", "w", false, "Rebuild and restart the server when files change")
+	startCmd.Flags().BoolVar(&debugFlag, "debug", false, "List the files that trigger each reload")
+	startCmd.Flags().BoolVar(&skipWire, "skip-wire", false, "Use the existing wire_gen.go instead of regenerating it")
 }
 
-func startProduction() {
+func startProduction(cmd *cobra.Command) {
 	fmt.Println("🚀 Starting in production mode...")
 	
-	// Build first
-	if err := buildApp(); err != nil {
+	// Build first, for this machine whatever the configured targets
+	opts, err := buildOptions(cmd)
+	if err == nil {
+		opts.Targets = nil
+		_, err = runBuild(&opts)
+	}
+	if err != nil {
 		fmt.Printf("❌ Build failed: %v\n", err)
 		os.Exit(1)
 	}
 	
 	// Run the binary
-	binaryPath := "./server"
-	if _, err := os.Stat("./dist/server"); err == nil {
-		binaryPath = "./dist/server"
+	binaryPath := opts.ArtifactPath(builder.Target{})
+	if !filepath.IsAbs(binaryPath) {
+		binaryPath = "." + string(filepath.Separator) + binaryPath
 	}
+	server := exec.Command(binaryPath)
+	server.Stdout = os.Stdout
+	server.Stderr = os.Stderr
 	
-	cmd := exec.Command(binaryPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
+	if err := server.Run(); err != nil {
 		fmt.Printf("❌ Failed to start server: %v\n", err)
 		os.Exit(1)
 	}
@@ -59,85 +68,55 @@ func startProduction() {
 
 func startWithWatch() {
 	fmt.Println("🔥 Starting with live-reload...")
-	
-	// Check if .air.toml exists, create if not
-	if _, err := os.Stat(".air.toml"); os.IsNotExist(err) {
-		createAirConfig()
-	}
-	
-	// Auto-generate wire before starting
-	fmt.Println("🔧 Generating wire dependencies...")
-	wireCmd := exec.Command("wire", "./internal")
-	wireCmd.Stdout = os.Stdout
-	wireCmd.Stderr = os.Stderr
-	wireCmd.Run() // Don't fail if wire fails
-	
-	// Start air
-	args := []string{}
 	if debugFlag {
 		fmt.Println("🐛 Debug mode enabled")
-		args = append(args, "-d")
 	}
-	
-	cmd := exec.Command("air", args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("❌ Failed to start with Air: %v\n", err)
-		fmt.Println("💡 Make sure Air is installed: go install github.com/cosmtrek/air@latest")
-		os.Exit(1)
-	}
-}
-
-func createAirConfig() {
-	config := `root = "."
-testdata_dir = "testdata"
-tmp_dir = "tmp"
 
-[build]
-  args_bin = []
-  bin = "./tmp/main"
-  cmd = "go build -o ./tmp/main ./cmd/server"
-  delay = 1000
-  exclude_dir = ["assets", "tmp", "vendor", "testdata"]
-  exclude_file = []
-  exclude_regex = ["_test.go"]
-  exclude_unchanged = false
-  follow_symlink = false
-  full_bin = ""
-  include_dir = []
-  include_ext = ["go", "tpl", "tmpl", "html"]
-  include_file = []
-  kill_delay = "0s"
-  log = "build-errors.log"
-  poll = false
-  poll_interval = 0
-  rerun = false
-  rerun_delay = 500
-  send_interrupt = false
-  stop_on_root = false
-
-[color]
-  app = ""
-  build = "yellow"
-  main = "magenta"
-  runner = "green"
-  watcher = "cyan"
+	config := watchConfig()
+	server := &watcher.Process{
+		Path:        filepath.Join("tmp", "server"),
+		KillTimeout: config.KillTimeout,
+		OnExit: func(err error) {
+			if err != nil {
+				color.Red("💥 Server exited: %v", err)
+			} else {
+				color.Yellow("⏹️  Server exited")
+			}
+		},
+	}
 
-[log]
-  main_only = false
-  time = false
+	runWatch(config, func(changed []string) {
+		if debugFlag {
+			for _, file := range changed {
+				fmt.Printf("   🐛 %s\n", file)
+			}
+		}
+		if onlyTests(changed) {
+			return
+		}
+		if !skipWire && (changed == nil || needsWire(changed)) {
+			if err := runWire(); err != nil {
+				color.Yellow("⏸️  Fix the wire errors above to restart the server")
+				return
+			}
+		}
 
-[misc]
-  clean_on_exit = false
+		fmt.Println("🔨 Building...")
+		if err := compileServer(server.Path); err != nil {
+			color.Yellow("⏸️  Fix the build errors above to restart the server")
+			return
+		}
+		if server.Running() {
+			fmt.Println("🔄 Restarting server...")
+		} else {
+			fmt.Println("🚀 Starting server...")
+		}
+		if err := server.Restart(); err != nil {
+			color.Red("❌ %v", err)
+		}
+	})
 
-[screen]
-  clear_on_rebuild = false
-  keep_scroll = true
-`
-	
-	if err := os.WriteFile(".air.toml", []byte(config), 0644); err != nil {
-		fmt.Printf("Warning: Could not create .air.toml: %v\n", err)
+	if err := server.Stop(); err != nil {
+		color.Red("❌ %v", err)
 	}
-}
\ No newline at end of file
+}
@@ -4,25 +4,143 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/analyzer"
+	"github.com/samuel-k-w/meba-cli/internal/openapi"
 	"github.com/spf13/cobra"
 )
 
+var (
+	swaggerFormat   string
+	swaggerOutput   string
+	swaggerBasePath string
+	swaggerLegacy   bool
+)
+
 var swaggerCmd = &cobra.Command{
 	Use:   "swagger",
 	Short: "Generate Swagger documentation",
-	Long:  `Generate Swagger/OpenAPI documentation for your API endpoints`,
+	Long: `Generate OpenAPI 3.1 documentation for your API endpoints.
+
+Routes, handler bindings and DTO validation tags are read directly from the
+source, so no swag annotations or network access are needed. Use --legacy to
+run swag init and produce Swagger 2.0 docs instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if err := generateSwagger(); err != nil {
-			color.Red("Error generating Swagger docs: %v", err)
+		if swaggerLegacy {
+			if err := generateSwagger(); err != nil {
+				color.Red("Error generating Swagger docs: %v", err)
+				os.Exit(1)
+			}
+			color.Green("✅ Swagger documentation generated successfully!")
+			fmt.Println("📚 Docs available at: http://localhost:8080/swagger/index.html")
+			return
+		}
+
+		files, err := generateOpenAPI()
+		if err != nil {
+			color.Red("Error generating OpenAPI docs: %v", err)
 			os.Exit(1)
 		}
-		color.Green("✅ Swagger documentation generated successfully!")
-		fmt.Println("📚 Docs available at: http://localhost:8080/swagger/index.html")
+		color.Green("✅ OpenAPI documentation generated successfully!")
+		for _, file := range files {
+			fmt.Printf("📚 %s\n", file)
+		}
 	},
 }
 
+// generateOpenAPI analyzes the project in the current directory and writes
+// the OpenAPI document in the requested formats
+func generateOpenAPI() ([]string, error) {
+	formats := map[string]bool{}
+	switch swaggerFormat {
+	case "json", "yaml":
+		formats[swaggerFormat] = true
+	case "both":
+		formats["json"], formats["yaml"] = true, true
+	default:
+		return nil, fmt.Errorf("unknown format %q (use json, yaml or both)", swaggerFormat)
+	}
+
+	doc, err := buildOpenAPIDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(swaggerOutput, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var written []string
+	for _, format := range []string{"json", "yaml"} {
+		if !formats[format] {
+			continue
+		}
+		var data []byte
+		if format == "json" {
+			data, err = doc.JSON()
+		} else {
+			data, err = doc.YAML()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", format, err)
+		}
+		path := filepath.Join(swaggerOutput, "openapi."+format)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// buildOpenAPIDocument runs the static analyzer on the current project
+func buildOpenAPIDocument() (*openapi.Document, error) {
+	cfg := readProjectConfig()
+
+	basePath := swaggerBasePath
+	if basePath == "" {
+		basePath = cfg.GetString("swagger.base_path")
+	}
+	if basePath == "" {
+		basePath = "/api/v1"
+	}
+
+	color.Blue("🔍 Analyzing routes...")
+	project, err := analyzer.Load(analyzer.Options{Dir: ".", BasePath: basePath})
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range project.Warnings {
+		color.Yellow("⚠️  %s", w)
+	}
+
+	info := openapi.Info{
+		Title:       cfg.GetString("swagger.title"),
+		Description: cfg.GetString("swagger.description"),
+		Version:     cfg.GetString("swagger.version"),
+	}
+	if info.Title == "" {
+		info.Title = project.Module + " API"
+	}
+	if info.Version == "" {
+		info.Version = "1.0.0"
+	}
+
+	var servers []string
+	if host := cfg.GetString("swagger.host"); host != "" {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		servers = append(servers, host)
+	}
+
+	return openapi.Build(project, info, servers), nil
+}
+
+// generateSwagger runs swag init to produce Swagger 2.0 docs
 func generateSwagger() error {
 	// Check if swag is installed
 	if _, err := exec.LookPath("swag"); err != nil {
@@ -39,7 +157,7 @@ func generateSwagger() error {
 	swagCmd := exec.Command("swag", "init", "-g", "cmd/server/main.go", "-o", "docs/", "--parseDependency", "--parseInternal")
 	swagCmd.Stdout = os.Stdout
 	swagCmd.Stderr = os.Stderr
-	
+
 	if err := swagCmd.Run(); err != nil {
 		return fmt.Errorf("failed to generate swagger docs: %w", err)
 	}
@@ -49,4 +167,8 @@ func generateSwagger() error {
 
 func init() {
 	rootCmd.AddCommand(swaggerCmd)
-}
\ No newline at end of file
+	swaggerCmd.Flags().StringVarP(&swaggerFormat, "format", "f", "json", "Output format: json, yaml or both")
+	swaggerCmd.Flags().StringVarP(&swaggerOutput, "output", "o", "docs", "Output directory")
+	swaggerCmd.Flags().StringVar(&swaggerBasePath, "base-path", "", "Prefix for modules not mounted from internal.Handlers (default: swagger.base_path or /api/v1)")
+	swaggerCmd.Flags().BoolVar(&swaggerLegacy, "legacy", false, "Use swag init to generate Swagger 2.0 docs")
+}
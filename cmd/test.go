@@ -4,19 +4,58 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/testrunner"
 	"github.com/spf13/cobra"
 )
 
 var (
 	testWatchFlag    bool
 	testCoverageFlag bool
+	testRun          string
+	testPkgs         []string
+	testRace         bool
+	testShort        bool
+	testCount        int
+	testVerbose      bool
+	testJUnit        string
+	testMinCoverage  float64
+	testSlowest      int
+	testUpdateSnaps  bool
 )
 
 var testCmd = &cobra.Command{
 	Use:   "test",
 	Short: "Run unit tests",
-	Long:  "Run unit tests with optional watch mode and coverage",
+	Long: `Run unit tests and report each package with its duration, the
+output of failed tests and the slowest tests.
+
+With --coverage, coverage/ receives the profile, an HTML report,
+cobertura.xml and lcov.info, and packages with tests must reach the
+minimum coverage from --min-coverage or configs/config.yaml:
+
+  test:
+    coverage:
+      min: 70
+      packages:
+        - path: internal/users
+          min: 85
+
+With --watch, only the packages affected by a change are retested, using
+the import graph from go list. Type a key and press Enter to:
+
+  a  run all tests
+  f  rerun only the failed tests
+  p  filter by package (import path substring)
+  t  filter by test name regex
+  q  quit
+
+Tests that use pkg/snapshot compare JSON responses with the files in
+__snapshots__; --update-snapshots rewrites them with the current
+responses.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if testWatchFlag {
 			runTestsWithWatch()
@@ -30,77 +69,184 @@ var testCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(testCmd)
-	testCmd.Flags().BoolVarP(&testWatchFlag, "watch", "w", false, "Run tests in watch mode")
-	testCmd.Flags().BoolVar(&testCoverageFlag, "coverage", false, "Generate code coverage report")
+	testCmd.Flags().BoolVarP(&testWatchFlag, "watch", "w", false, "Rerun affected tests when files change")
+	testCmd.Flags().BoolVar(&testCoverageFlag, "coverage", false, "Generate code coverage reports and enforce the minimum")
+	testCmd.Flags().StringVar(&testRun, "run", "", "Only run tests matching this regex")
+	testCmd.Flags().StringSliceVar(&testPkgs, "pkg", nil, "Packages to test (default ./...)")
+	testCmd.Flags().BoolVar(&testRace, "race", false, "Enable the race detector")
+	testCmd.Flags().BoolVar(&testShort, "short", false, "Tell long-running tests to shorten their run time")
+	testCmd.Flags().IntVar(&testCount, "count", 0, "Run each test n times (1 disables the test cache)")
+	testCmd.Flags().BoolVarP(&testVerbose, "verbose", "v", false, "Stream the full test output")
+	testCmd.Flags().StringVar(&testJUnit, "junit", "", "Write a JUnit XML report to this file")
+	testCmd.Flags().Float64Var(&testMinCoverage, "min-coverage", 0, "Minimum coverage in percent per package (overrides config)")
+	testCmd.Flags().IntVar(&testSlowest, "slowest", 5, "Number of slowest tests to list")
+	testCmd.Flags().BoolVar(&testUpdateSnaps, "update-snapshots", false, "Rewrite __snapshots__ files with the current responses")
+}
+
+// testOptions returns the go test options from the flags
+func testOptions() testrunner.Options {
+	opts := testrunner.Options{
+		Packages: testPkgs,
+		Run:      testRun,
+		Race:     testRace,
+		Short:    testShort,
+		Count:    testCount,
+		Verbose:  testVerbose,
+	}
+	if testUpdateSnaps {
+		opts.Env = append(opts.Env, "UPDATE_SNAPSHOTS=1")
+	}
+	return opts
 }
 
 func runTests() {
 	fmt.Println("🧪 Running unit tests...")
-	
-	cmd := exec.Command("go", "test", "./...")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("❌ Tests failed: %v\n", err)
+
+	result := runTestSuite(testOptions())
+	if !result.OK() {
 		os.Exit(1)
 	}
-	
-	fmt.Println("✅ All tests passed!")
 }
 
-func runTestsWithWatch() {
-	fmt.Println("👀 Running tests in watch mode...")
-	
-	// Use gotestsum if available, otherwise fall back to basic watch
-	if _, err := exec.LookPath("gotestsum"); err == nil {
-		cmd := exec.Command("gotestsum", "--watch", "./...")
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("❌ Test watch failed: %v\n", err)
+// runTestSuite runs the tests, prints the summary and writes the JUnit
+// report. It exits when the tests cannot run at all.
+func runTestSuite(opts testrunner.Options) *testrunner.Result {
+	result, err := testrunner.Run(opts)
+	if err != nil {
+		fmt.Printf("❌ Tests failed: %v\n", err)
+		os.Exit(1)
+	}
+	testrunner.PrintSummary(os.Stdout, result, testSlowest)
+	printSnapshotDrift(result)
+
+	if testJUnit != "" {
+		if err := testrunner.WriteJUnit(testJUnit, result); err != nil {
+			fmt.Printf("❌ Failed to write JUnit report: %v\n", err)
 			os.Exit(1)
 		}
-	} else {
-		fmt.Println("💡 Install gotestsum for better watch experience: go install gotest.tools/gotestsum@latest")
-		// Simple watch implementation
-		runTests()
+		fmt.Printf("📄 JUnit report written to %s\n", testJUnit)
 	}
+	return result
 }
 
 func runTestsWithCoverage() {
 	fmt.Println("📊 Running tests with coverage...")
-	
+
 	// Create coverage directory
 	if err := os.MkdirAll("coverage", 0755); err != nil {
 		fmt.Printf("❌ Failed to create coverage directory: %v\n", err)
 		os.Exit(1)
 	}
-	
+
 	// Run tests with coverage
-	cmd := exec.Command("go", "test", "-coverprofile=coverage/coverage.out", "./...")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	if err := cmd.Run(); err != nil {
-		fmt.Printf("❌ Tests with coverage failed: %v\n", err)
-		os.Exit(1)
-	}
-	
+	profile := filepath.Join("coverage", "coverage.out")
+	opts := testOptions()
+	opts.CoverProfile = profile
+	result := runTestSuite(opts)
+
 	// Generate HTML coverage report
-	cmd = exec.Command("go", "tool", "cover", "-html=coverage/coverage.out", "-o", "coverage/coverage.html")
+	cmd := exec.Command("go", "tool", "cover", "-html="+profile, "-o", filepath.Join("coverage", "coverage.html"))
 	if err := cmd.Run(); err != nil {
 		fmt.Printf("Warning: Could not generate HTML coverage report: %v\n", err)
 	} else {
 		fmt.Println("📈 Coverage report generated: coverage/coverage.html")
 	}
-	
-	// Show coverage summary
-	cmd = exec.Command("go", "tool", "cover", "-func=coverage/coverage.out")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Run()
-	
+
+	coverage, err := testrunner.LoadCoverage(".", profile)
+	if err != nil {
+		if result.OK() {
+			fmt.Printf("❌ Failed to read coverage profile: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	if err := coverage.WriteCobertura(filepath.Join("coverage", "cobertura.xml")); err != nil {
+		fmt.Printf("Warning: Could not write Cobertura report: %v\n", err)
+	}
+	if err := coverage.WriteLCOV(filepath.Join("coverage", "lcov.info")); err != nil {
+		fmt.Printf("Warning: Could not write LCOV report: %v\n", err)
+	}
+	fmt.Println("📄 CI reports written: coverage/cobertura.xml, coverage/lcov.info")
+
+	below := checkCoverage(result, coverage)
+	if !result.OK() {
+		os.Exit(1)
+	}
+	if below > 0 {
+		color.Red("❌ %d package(s) below the minimum coverage", below)
+		os.Exit(1)
+	}
 	fmt.Println("✅ Tests completed with coverage!")
-}
\ No newline at end of file
+}
+
+// printSnapshotDrift lists the snapshots that no longer match
+func printSnapshotDrift(result *testrunner.Result) {
+	if testUpdateSnaps {
+		fmt.Println("📸 Snapshots updated")
+		return
+	}
+	drifts := result.SnapshotDrifts()
+	if len(drifts) == 0 {
+		return
+	}
+	color.Yellow("\n📸 Snapshot drift in %d file(s):", len(drifts))
+	for _, d := range drifts {
+		status := "changed"
+		if d.Missing {
+			status = "missing"
+		}
+		fmt.Printf("   %-8s %s/%s (%s)\n", status, d.Package, d.File, d.Test)
+	}
+	fmt.Println("   Review the diffs above and accept them with --update-snapshots")
+}
+
+// coverageRule is a per-package minimum from test.coverage.packages
+type coverageRule struct {
+	Path string  `mapstructure:"path"`
+	Min  float64 `mapstructure:"min"`
+}
+
+// checkCoverage prints the coverage of each package with tests against
+// its minimum and returns how many fall short
+func checkCoverage(result *testrunner.Result, coverage *testrunner.Coverage) int {
+	cfg := readProjectConfig()
+	min := cfg.GetFloat64("test.coverage.min")
+	if testMinCoverage > 0 {
+		min = testMinCoverage
+	}
+	var rules []coverageRule
+	cfg.UnmarshalKey("test.coverage.packages", &rules)
+
+	tested := map[string]bool{}
+	for _, p := range result.Packages {
+		if p.HasTests() {
+			tested[p.Name] = true
+		}
+	}
+
+	fmt.Println("\n📊 Coverage by package:")
+	below := 0
+	for _, pkg := range coverage.Packages() {
+		if !tested[pkg.Package] {
+			continue
+		}
+		want := min
+		for _, rule := range rules {
+			path := strings.TrimPrefix(strings.TrimSuffix(rule.Path, "/"), "./")
+			if pkg.Package == path || strings.HasSuffix(pkg.Package, "/"+path) {
+				want = rule.Min
+			}
+		}
+		line := fmt.Sprintf("   %6.1f%%  %s", pkg.Percent(), pkg.Package)
+		switch {
+		case want > 0 && pkg.Percent() < want:
+			below++
+			color.Red("%s (minimum %.1f%%)", line, want)
+		case want > 0:
+			color.Green("%s", line)
+		default:
+			fmt.Println(line)
+		}
+	}
+	fmt.Printf("   %6.1f%%  total\n", coverage.Percent())
+	return below
+}
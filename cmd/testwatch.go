@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/testrunner"
+)
+
+// testWatch is the state of meba test --watch. Runs are triggered by file
+// changes and by keys typed on stdin, one at a time.
+type testWatch struct {
+	mu sync.Mutex
+	// pkgFilter keeps packages whose import path contains it
+	pkgFilter string
+	// runFilter is passed to go test -run
+	runFilter string
+	// failedOnly reruns only the tests that failed last time
+	failedOnly bool
+	last       *testrunner.Result
+}
+
+func runTestsWithWatch() {
+	fmt.Println("👀 Running tests in watch mode...")
+
+	w := &testWatch{}
+	go w.readKeys(os.Stdin)
+	runWatch(watchConfig(), w.onChange)
+}
+
+// onChange runs the tests affected by the changed files, or every test on
+// the first run
+func (w *testWatch) onChange(changed []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if changed == nil || w.failedOnly {
+		w.run(nil)
+		return
+	}
+
+	pkgs, err := testrunner.LoadPackages(".")
+	if err != nil {
+		color.Yellow("⚠️  %v; running all tests", err)
+		w.run(nil)
+		return
+	}
+	affected, all := pkgs.Affected(changed)
+	if all {
+		w.run(nil)
+		return
+	}
+	if len(affected) == 0 {
+		fmt.Println("💤 No packages affected")
+		w.usage()
+		return
+	}
+	w.run(affected)
+}
+
+// run tests the given packages, or all packages when pkgs is nil, after
+// applying the filters
+func (w *testWatch) run(pkgs []string) {
+	runFilter := w.runFilter
+	if w.failedOnly && w.last != nil && !w.last.OK() {
+		pkgs = w.last.FailedPackages()
+		var tests []string
+		whole := false
+		for _, pkg := range pkgs {
+			// A package that failed to build has no test names; run it whole
+			if len(w.last.Failures[pkg]) == 0 {
+				whole = true
+			}
+			tests = append(tests, w.last.Failures[pkg]...)
+		}
+		if !whole {
+			runFilter = testrunner.RunPattern(tests)
+		}
+	}
+
+	if w.pkgFilter != "" {
+		if pkgs == nil {
+			all, err := testrunner.LoadPackages(".")
+			if err != nil {
+				color.Red("❌ %v", err)
+				return
+			}
+			pkgs = all.All()
+		}
+		var kept []string
+		for _, pkg := range pkgs {
+			if strings.Contains(pkg, w.pkgFilter) {
+				kept = append(kept, pkg)
+			}
+		}
+		if len(kept) == 0 {
+			fmt.Printf("💤 No packages match %q\n", w.pkgFilter)
+			w.usage()
+			return
+		}
+		pkgs = kept
+	}
+
+	fmt.Println("🧪 Running unit tests...")
+	opts := testOptions()
+	if pkgs != nil {
+		opts.Packages = pkgs
+	}
+	if runFilter != "" {
+		opts.Run = runFilter
+	}
+	result, err := testrunner.Run(opts)
+	if err != nil {
+		color.Red("❌ %v", err)
+		w.usage()
+		return
+	}
+	testrunner.PrintSummary(os.Stdout, result, 0)
+
+	if result.OK() && w.failedOnly {
+		fmt.Println("🎉 All previously failing tests pass, leaving failed-only mode")
+		w.failedOnly = false
+	}
+	w.last = result
+	w.usage()
+}
+
+// usage prints the active filters and the watch keys
+func (w *testWatch) usage() {
+	var active []string
+	if w.failedOnly {
+		active = append(active, "failed only")
+	}
+	if w.pkgFilter != "" {
+		active = append(active, fmt.Sprintf("package ~ %q", w.pkgFilter))
+	}
+	if w.runFilter != "" {
+		active = append(active, fmt.Sprintf("test ~ /%s/", w.runFilter))
+	}
+	if len(active) > 0 {
+		fmt.Printf("\n🔎 Filters: %s\n", strings.Join(active, ", "))
+	}
+	color.HiBlack("› a all · f failed · p package filter · t test filter · Enter rerun · q quit (then Enter)")
+}
+
+// readKeys handles the watch keys. Input is read a line at a time so it
+// works in any terminal; filters are typed on the line after p or t.
+func (w *testWatch) readKeys(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	prompt := func(label string) (string, bool) {
+		fmt.Print(label)
+		if !scanner.Scan() {
+			return "", false
+		}
+		return strings.TrimSpace(scanner.Text()), true
+	}
+
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		switch key {
+		case "q":
+			fmt.Println("👋 Stopped watching")
+			os.Exit(0)
+		case "a":
+			w.mu.Lock()
+			w.failedOnly, w.pkgFilter, w.runFilter = false, "", ""
+			w.run(nil)
+			w.mu.Unlock()
+		case "f":
+			w.mu.Lock()
+			if w.last == nil || w.last.OK() {
+				fmt.Println("✅ No failed tests to rerun")
+				w.usage()
+			} else {
+				w.failedOnly = true
+				w.run(nil)
+			}
+			w.mu.Unlock()
+		case "p":
+			pattern, ok := prompt("📦 Package pattern (empty to clear): ")
+			if !ok {
+				return
+			}
+			w.mu.Lock()
+			w.pkgFilter = pattern
+			w.run(nil)
+			w.mu.Unlock()
+		case "t":
+			pattern, ok := prompt("🔎 Test name regex (empty to clear): ")
+			if !ok {
+				return
+			}
+			if _, err := regexp.Compile(pattern); err != nil {
+				color.Red("❌ Invalid regex: %v", err)
+				continue
+			}
+			w.mu.Lock()
+			w.runFilter = pattern
+			w.run(nil)
+			w.mu.Unlock()
+		case "":
+			w.mu.Lock()
+			w.run(nil)
+			w.mu.Unlock()
+		default:
+			color.Yellow("⚠️  Unknown key %q", key)
+			w.mu.Lock()
+			w.usage()
+			w.mu.Unlock()
+		}
+	}
+}
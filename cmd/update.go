@@ -70,11 +70,8 @@ func updateDevTools() {
 	fmt.Println("🛠️  Updating development tools...")
 	
 	tools := []string{
-		"github.com/google/wire/cmd/wire@latest",
-		"github.com/cosmtrek/air@latest",
 		"gotest.tools/gotestsum@latest",
 		"github.com/swaggo/swag/cmd/swag@latest",
-		"github.com/golang/mock/mockgen@latest",
 	}
 	
 	for _, tool := range tools {
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/watcher"
+)
+
+// watchConfig reads the watch section of configs/config.yaml on top of
+// the watcher defaults:
+//
+//	watch:
+//	  include: ["**/*.go", "configs/**/*.yaml"]
+//	  exclude: ["tmp/**", "**/wire_gen.go"]
+//	  delay: 300ms
+//	  kill_timeout: 5s
+func watchConfig() watcher.Config {
+	config := watcher.DefaultConfig()
+	cfg := readProjectConfig()
+	if include := cfg.GetStringSlice("watch.include"); len(include) > 0 {
+		config.Include = include
+	}
+	if exclude := cfg.GetStringSlice("watch.exclude"); len(exclude) > 0 {
+		config.Exclude = exclude
+	}
+	if delay := cfg.GetDuration("watch.delay"); delay > 0 {
+		config.Delay = delay
+	}
+	if timeout := cfg.GetDuration("watch.kill_timeout"); timeout > 0 {
+		config.KillTimeout = timeout
+	}
+	return config
+}
+
+// runWatch calls run once with no changes, then after every batch of file
+// changes until the user presses Ctrl+C
+func runWatch(config watcher.Config, run func(changed []string)) {
+	w, err := watcher.New(config)
+	if err != nil {
+		color.Red("❌ Failed to start watcher: %v", err)
+		os.Exit(1)
+	}
+	defer w.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run(nil)
+	fmt.Println("👀 Watching for changes... (Press Ctrl+C to stop)")
+	w.Watch(ctx, func(changed []string) {
+		fmt.Printf("\n📝 %s\n", describeChanges(changed))
+		run(changed)
+		fmt.Println("👀 Watching for changes...")
+	})
+	fmt.Println("\n👋 Stopped watching")
+}
+
+// describeChanges summarises changed files for the watch log
+func describeChanges(changed []string) string {
+	if len(changed) == 1 {
+		return changed[0] + " changed"
+	}
+	if len(changed) <= 3 {
+		return strings.Join(changed, ", ") + " changed"
+	}
+	return fmt.Sprintf("%s and %d more files changed", strings.Join(changed[:2], ", "), len(changed)-2)
+}
+
+// needsWire reports whether the changes touch the wire sets, i.e. a
+// module.go or the injector declaration
+func needsWire(changed []string) bool {
+	for _, file := range changed {
+		if path.Base(file) == "module.go" || file == "internal/wire.go" || file == "internal/app.go" {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyTests reports whether every change is a test file, which does not
+// affect the server binary
+func onlyTests(changed []string) bool {
+	for _, file := range changed {
+		if !strings.HasSuffix(file, "_test.go") {
+			return false
+		}
+	}
+	return len(changed) > 0
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/samuel-k-w/meba-cli/internal/generator"
+	"github.com/samuel-k-w/meba-cli/internal/wiregraph"
+	"github.com/spf13/cobra"
+)
+
+var skipWire bool
+
+var wireCmd = &cobra.Command{
+	Use:   "wire",
+	Short: "Generate dependency injection code",
+	Long: `Generate internal/wire_gen.go from the wire sets of the project.
+
+Wire's generator is built into meba, so no wire binary is needed. Errors
+are grouped by the module that caused them. build and start run this step
+automatically unless --skip-wire is given.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runWire(); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// runWire generates the injectors and prints diagnostics on failure
+func runWire() error {
+	fmt.Println("🔧 Generating wire dependencies...")
+	files, err := generator.GenerateWire(".")
+	if err != nil {
+		printWireError(err)
+		return err
+	}
+	for _, file := range files {
+		color.Green("✅ Wrote %s", file)
+	}
+	return nil
+}
+
+// printWireError prints wire diagnostics grouped by module, followed by
+// the fixes suggested by the dependency graph check
+func printWireError(err error) {
+	var wireErr *generator.WireError
+	if !errors.As(err, &wireErr) {
+		color.Red("❌ Wire failed: %v", err)
+		return
+	}
+
+	color.Red("❌ %v", wireErr)
+	var order []string
+	byModule := map[string][]string{}
+	for _, d := range wireErr.Diagnostics {
+		if _, ok := byModule[d.Module]; !ok {
+			order = append(order, d.Module)
+		}
+		byModule[d.Module] = append(byModule[d.Module], d.Message)
+	}
+	for _, module := range order {
+		fmt.Fprintf(os.Stderr, "\n📦 %s\n", module)
+		for _, msg := range byModule[module] {
+			fmt.Fprintf(os.Stderr, "   %s\n", strings.ReplaceAll(msg, "\n", "\n   "))
+		}
+	}
+
+	// Hints are only reliable when the code type-checks
+	if graph, err := wiregraph.Load("."); err == nil && len(graph.Warnings) == 0 {
+		hinted := map[string]bool{}
+		for _, p := range graph.Check() {
+			if p.IsError() && !hinted[p.Hint] {
+				hinted[p.Hint] = true
+				fmt.Fprintf(os.Stderr, "\n💡 %s\n", p.Hint)
+			}
+		}
+	}
+	fmt.Fprintln(os.Stderr)
+}
+
+func init() {
+	rootCmd.AddCommand(wireCmd)
+}
@@ -0,0 +1,284 @@
+// Package analyzer statically inspects a meba project with go/packages and
+// extracts its HTTP surface: routes, handlers and the DTOs they bind.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const ginPkgPath = "github.com/gin-gonic/gin"
+
+// Options controls how a project is analyzed
+type Options struct {
+	// Dir is the project root (the directory containing go.mod)
+	Dir string
+	// BasePath is the prefix assumed for SetupRoutes methods that are
+	// never mounted from a gin engine, e.g. "/api/v1"
+	BasePath string
+}
+
+// Project is the static view of a meba project
+type Project struct {
+	Module   string
+	Dir      string
+	Routes   []*Route
+	Models   map[string]*Model
+	Warnings []string
+}
+
+// Route is a single registered HTTP route
+type Route struct {
+	Method     string
+	Path       string
+	Handler    *Handler
+	Middleware []string
+	// Mounted is false when the route comes from a SetupRoutes method that
+	// is not reachable from an engine and BasePath was assumed
+	Mounted  bool
+	Position token.Position
+}
+
+// Handler describes what a gin handler reads from and writes to the request.
+// Func is empty for inline func literals.
+type Handler struct {
+	Name        string
+	Package     string
+	Func        string
+	Doc         string
+	Position    token.Position
+	PathParams  []*Param
+	QueryParams []*Param
+	Body        *TypeRef
+	Responses   []*Response
+}
+
+// Param is a path or query parameter read by a handler
+type Param struct {
+	Name        string
+	Type        *TypeRef
+	Required    bool
+	Rules       []Rule
+	Description string
+}
+
+// Response is a status code and body written by a handler
+type Response struct {
+	Status int
+	Body   *TypeRef
+}
+
+// Model is a named Go struct used as a request or response body
+type Model struct {
+	Name     string
+	Package  string
+	TypeName string
+	Doc      string
+	Fields   []*Field
+}
+
+// Field is a struct field as seen on the wire
+type Field struct {
+	Name     string
+	JSONName string
+	FormName string
+	URIName  string
+	Type     *TypeRef
+	Required bool
+	Rules    []Rule
+}
+
+// Rule is a single validator rule taken from binding/validate tags
+type Rule struct {
+	Name  string
+	Param string
+}
+
+// Kind is the wire-level kind of a type
+type Kind string
+
+const (
+	KindString  Kind = "string"
+	KindInteger Kind = "integer"
+	KindNumber  Kind = "number"
+	KindBoolean Kind = "boolean"
+	KindArray   Kind = "array"
+	KindMap     Kind = "map"
+	KindObject  Kind = "object"
+	KindRef     Kind = "ref"
+	KindAny     Kind = "any"
+)
+
+// TypeRef is a wire-level type: a primitive, a container, an inline object
+// or a reference to a named Model
+type TypeRef struct {
+	Kind       Kind
+	Format     string
+	Ref        string
+	Elem       *TypeRef
+	Properties []*Field
+	Nullable   bool
+	// Enum lists the allowed values, e.g. the sortable fields of a list
+	Enum []string
+}
+
+type funcDecl struct {
+	decl *ast.FuncDecl
+	pkg  *packages.Package
+}
+
+// varDecl is the initializer of a package-level variable
+type varDecl struct {
+	value ast.Expr
+	pkg   *packages.Package
+}
+
+type analyzer struct {
+	opts     Options
+	project  *Project
+	pkgs     []*packages.Package
+	decls    map[*types.Func]funcDecl
+	vars     map[types.Object]varDecl
+	handlers map[*types.Func]*Handler
+	typeDocs map[types.Object]string
+	visited  map[*ast.FuncDecl]bool
+	stack    map[*ast.FuncDecl]bool
+	config   configDefaults
+}
+
+// Load type-checks the project at opts.Dir and extracts its routes.
+// Type errors are tolerated (e.g. a missing wire_gen.go) and reported
+// as warnings.
+func Load(opts Options) (*Project, error) {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports |
+			packages.NeedDeps | packages.NeedModule,
+		Dir: opts.Dir,
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go packages found in %s", opts.Dir)
+	}
+
+	a := &analyzer{
+		opts:     opts,
+		project:  &Project{Dir: opts.Dir, Models: map[string]*Model{}},
+		pkgs:     pkgs,
+		decls:    map[*types.Func]funcDecl{},
+		vars:     map[types.Object]varDecl{},
+		handlers: map[*types.Func]*Handler{},
+		typeDocs: map[types.Object]string{},
+		visited:  map[*ast.FuncDecl]bool{},
+		stack:    map[*ast.FuncDecl]bool{},
+	}
+
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && a.project.Module == "" {
+			a.project.Module = pkg.Module.Path
+		}
+		for _, e := range pkg.Errors {
+			a.warnf("%s", e.Error())
+		}
+		a.indexDecls(pkg)
+	}
+
+	a.collectRoutes()
+	return a.project, nil
+}
+
+// warnf records a non-fatal analysis problem
+func (a *analyzer) warnf(format string, args ...interface{}) {
+	a.project.Warnings = append(a.project.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (a *analyzer) indexDecls(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	for _, file := range pkg.Syntax {
+		for _, d := range file.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Body == nil {
+					continue
+				}
+				if fn, ok := pkg.TypesInfo.Defs[d.Name].(*types.Func); ok {
+					a.decls[fn] = funcDecl{decl: d, pkg: pkg}
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					if vs, ok := spec.(*ast.ValueSpec); ok {
+						for i, name := range vs.Names {
+							obj := pkg.TypesInfo.Defs[name]
+							switch {
+							case obj == nil || len(vs.Values) == 0:
+							case len(vs.Values) == len(vs.Names):
+								a.vars[obj] = varDecl{value: vs.Values[i], pkg: pkg}
+							default:
+								a.vars[obj] = varDecl{value: vs.Values[0], pkg: pkg}
+							}
+						}
+						continue
+					}
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					if obj := pkg.TypesInfo.Defs[ts.Name]; obj != nil && doc != nil {
+						a.typeDocs[obj] = doc.Text()
+					}
+				}
+			}
+		}
+	}
+}
+
+// sortedDecls returns the indexed declarations in a stable source order
+func (a *analyzer) sortedDecls() []funcDecl {
+	decls := make([]funcDecl, 0, len(a.decls))
+	for _, fd := range a.decls {
+		decls = append(decls, fd)
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		pi := decls[i].pkg.Fset.Position(decls[i].decl.Pos())
+		pj := decls[j].pkg.Fset.Position(decls[j].decl.Pos())
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	return decls
+}
+
+// isGinType reports whether t (or the type it points to) is gin.<name>
+func isGinType(t types.Type, name string) bool {
+	if t == nil {
+		return false
+	}
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == ginPkgPath && obj.Name() == name
+}
@@ -0,0 +1,181 @@
+package analyzer
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadTestApp analyzes a copy of testdata/app, whose dependencies are
+// resolved with go mod tidy
+func loadTestApp(t *testing.T) *Project {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("type-checks a project and its dependencies")
+	}
+	dir := copyTestdata(t, filepath.Join("testdata", "app"))
+
+	tidy := exec.Command("go", "mod", "tidy")
+	tidy.Dir = dir
+	if out, err := tidy.CombinedOutput(); err != nil {
+		t.Skipf("dependencies unavailable: %v\n%s", err, out)
+	}
+
+	project, err := Load(Options{Dir: dir, BasePath: "/api/v1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Warnings) > 0 {
+		t.Fatalf("unexpected warnings: %v", project.Warnings)
+	}
+	return project
+}
+
+func TestLoadRoutes(t *testing.T) {
+	project := loadTestApp(t)
+
+	tests := []struct {
+		method     string
+		path       string
+		handler    string
+		middleware []string
+	}{
+		{"GET", "/metrics", "main.metrics", []string{"middleware.Logger"}},
+		{"GET", "/api/v1/orders", "orders.Handlers.List", []string{"middleware.Logger"}},
+		{"GET", "/api/v1/orders/:id", "orders.Handlers.Get", []string{"middleware.Logger"}},
+		{"POST", "/api/v1/orders", "orders.Handlers.Create", []string{"middleware.Logger"}},
+		{"GET", "/api/v1/orders/:id/history", "orders.Handlers.History", []string{"middleware.Logger"}},
+		{"DELETE", "/api/v1/orders/:id", "orders.Handlers.Delete", []string{"middleware.Logger", "middleware.AdminGuard"}},
+	}
+	if len(project.Routes) != len(tests) {
+		for _, r := range project.Routes {
+			t.Logf("%s %s", r.Method, r.Path)
+		}
+		t.Fatalf("got %d routes, want %d", len(project.Routes), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			route := findRoute(project, tt.method, tt.path)
+			if route == nil {
+				t.Fatal("route not found")
+			}
+			if !route.Mounted {
+				t.Error("route is not mounted from main")
+			}
+			if route.Handler == nil || route.Handler.Name != tt.handler {
+				t.Errorf("handler = %v, want %s", route.Handler, tt.handler)
+			}
+			if !reflect.DeepEqual(route.Middleware, tt.middleware) {
+				t.Errorf("middleware = %v, want %v", route.Middleware, tt.middleware)
+			}
+		})
+	}
+}
+
+func TestLoadErrorResponses(t *testing.T) {
+	project := loadTestApp(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		statuses []int
+	}{
+		{"unknown error is 500", "GET", "/api/v1/orders", []int{200, 500}},
+		{"service maps missing record", "GET", "/api/v1/orders/:id", []int{200, 400, 404, 500}},
+		{"FromDB of a variable adds no kind", "POST", "/api/v1/orders", []int{201, 400, 500}},
+		{"FromDB of ErrRecordNotFound is 404 only", "DELETE", "/api/v1/orders/:id", []int{204, 400, 404, 500}},
+		{"@Failure replaces inference", "GET", "/api/v1/orders/:id/history", []int{200, 410}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := findRoute(project, tt.method, tt.path)
+			if route == nil || route.Handler == nil {
+				t.Fatal("route not found")
+			}
+			var statuses []int
+			for _, resp := range route.Handler.Responses {
+				statuses = append(statuses, resp.Status)
+			}
+			if !reflect.DeepEqual(statuses, tt.statuses) {
+				t.Errorf("statuses = %v, want %v", statuses, tt.statuses)
+			}
+		})
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/users", nil},
+		{"/users/:id", []string{"id"}},
+		{"/users/:id/posts/:postID", []string{"id", "postID"}},
+		{"/static/*filepath", []string{"filepath"}},
+	}
+	for _, tt := range tests {
+		if got := PathParams(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PathParams(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []Rule
+	}{
+		{"", nil},
+		{"-", nil},
+		{"required,email", []Rule{{Name: "required"}, {Name: "email"}}},
+		{"omitempty,min=2,max=100", []Rule{{Name: "min", Param: "2"}, {Name: "max", Param: "100"}}},
+		{"required,dive,email", []Rule{{Name: "required"}}},
+	}
+	for _, tt := range tests {
+		if got := ParseRules(tt.tag); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRules(%q) = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func findRoute(project *Project, method, path string) *Route {
+	for _, r := range project.Routes {
+		if r.Method == method && r.Path == path {
+			return r
+		}
+	}
+	return nil
+}
+
+// copyTestdata copies a testdata module to a temporary directory, so that
+// tidying it leaves the tree untouched
+func copyTestdata(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
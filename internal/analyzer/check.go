@@ -0,0 +1,90 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem kinds reported by CheckRoutes
+const (
+	ProblemDuplicate = "duplicate"
+	ProblemConflict  = "conflict"
+)
+
+// Problem is a pair of routes gin would refuse to register together
+type Problem struct {
+	Kind   string
+	Route  *Route
+	Other  *Route
+	Reason string
+}
+
+// CheckRoutes reports routes registered twice and routes whose wildcards
+// clash in gin's radix tree, both of which make gin panic at startup
+func CheckRoutes(routes []*Route) []*Problem {
+	var problems []*Problem
+	for i, a := range routes {
+		for _, b := range routes[i+1:] {
+			if !sameMethod(a.Method, b.Method) {
+				continue
+			}
+			if a.Path == b.Path {
+				problems = append(problems, &Problem{
+					Kind:   ProblemDuplicate,
+					Route:  a,
+					Other:  b,
+					Reason: "handlers are already registered for this path",
+				})
+				continue
+			}
+			if reason := wildcardConflict(a.Path, b.Path); reason != "" {
+				problems = append(problems, &Problem{Kind: ProblemConflict, Route: a, Other: b, Reason: reason})
+			}
+		}
+	}
+	return problems
+}
+
+// IsGuard reports whether a middleware name refers to a guard generated
+// by meba g guard, e.g. middleware.AdminGuard
+func IsGuard(name string) bool {
+	return strings.HasSuffix(name[strings.LastIndex(name, ".")+1:], "Guard")
+}
+
+func sameMethod(a, b string) bool {
+	return a == b || a == "ANY" || b == "ANY"
+}
+
+// wildcardConflict compares two paths segment by segment the way gin
+// inserts them into its tree
+func wildcardConflict(a, b string) string {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, y := as[i], bs[i]
+		if x == y {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(x, "*") || strings.HasPrefix(y, "*"):
+			return fmt.Sprintf("catch-all %q conflicts with %q", pick(x, y, "*"), other(x, y, "*"))
+		case strings.HasPrefix(x, ":") && strings.HasPrefix(y, ":"):
+			return fmt.Sprintf("wildcard %q conflicts with %q; use the same parameter name", x, y)
+		}
+		return ""
+	}
+	return ""
+}
+
+func pick(x, y, prefix string) string {
+	if strings.HasPrefix(x, prefix) {
+		return x
+	}
+	return y
+}
+
+func other(x, y, prefix string) string {
+	if strings.HasPrefix(x, prefix) {
+		return y
+	}
+	return x
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const viperPkgPath = "github.com/spf13/viper"
+
+// configDefaults maps the config struct fields of the project, such as
+// HealthCheckConfig.Path, to the value viper.SetDefault gives their key,
+// e.g. monitoring.health_check.path
+type configDefaults map[*types.Var]string
+
+// pathString returns the value of a route path: a constant, or a config
+// field with a default such as cfg.Monitoring.HealthCheck.Path
+func (a *analyzer) pathString(info *types.Info, expr ast.Expr) (string, bool) {
+	if s, ok := constString(info, expr); ok {
+		return s, true
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	field, ok := info.Uses[sel.Sel].(*types.Var)
+	if !ok || !field.IsField() {
+		return "", false
+	}
+	if a.config == nil {
+		a.config = a.loadConfigDefaults()
+	}
+	s, ok := a.config[field]
+	return s, ok
+}
+
+func (a *analyzer) loadConfigDefaults() configDefaults {
+	defaults := map[string]string{}
+	for _, fd := range a.decls {
+		info := fd.pkg.TypesInfo
+		ast.Inspect(fd.decl.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 {
+				return true
+			}
+			fn := typeutil.StaticCallee(info, call)
+			if fn == nil || fn.Name() != "SetDefault" || fn.Pkg() == nil || fn.Pkg().Path() != viperPkgPath {
+				return true
+			}
+			key, ok := constString(info, call.Args[0])
+			value, isString := constString(info, call.Args[1])
+			if ok && isString {
+				defaults[key] = value
+			}
+			return true
+		})
+	}
+
+	// Key every field of the config structs by walking down from the
+	// structs no other config struct embeds as a field
+	keyed := map[*types.Struct]bool{}
+	nested := map[*types.Struct]bool{}
+	for _, pkg := range a.pkgs {
+		if pkg.Types == nil {
+			continue
+		}
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+			st, ok := tn.Type().Underlying().(*types.Struct)
+			if !ok || !hasMapstructureTags(st) {
+				continue
+			}
+			keyed[st] = true
+			for i := 0; i < st.NumFields(); i++ {
+				if inner, ok := st.Field(i).Type().Underlying().(*types.Struct); ok {
+					nested[inner] = true
+				}
+			}
+		}
+	}
+
+	fields := configDefaults{}
+	seen := map[*types.Var]bool{}
+	var walk func(st *types.Struct, prefix string)
+	walk = func(st *types.Struct, prefix string) {
+		for i := 0; i < st.NumFields(); i++ {
+			field := st.Field(i)
+			tag := reflect.StructTag(st.Tag(i)).Get("mapstructure")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			key := prefix + tag
+			if inner, ok := field.Type().Underlying().(*types.Struct); ok {
+				walk(inner, key+".")
+				continue
+			}
+			// A struct used under two keys has no single default
+			if seen[field] {
+				delete(fields, field)
+				continue
+			}
+			seen[field] = true
+			if value, ok := defaults[key]; ok {
+				fields[field] = value
+			}
+		}
+	}
+	for st := range keyed {
+		if !nested[st] {
+			walk(st, "")
+		}
+	}
+	return fields
+}
+
+func hasMapstructureTags(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if reflect.StructTag(st.Tag(i)).Get("mapstructure") != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"net/http"
+	"sort"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// errorResponses adds the responses of the errors a handler passes to
+// c.Error. The Errors middleware renders an apperrors kind with its status
+// and any other error as 500, so the statuses are those of the kinds the
+// code the handler calls can return, plus 500. A branch of a helper such as
+// FromDB guarded by errors.Is(err, gorm.ErrRecordNotFound) only counts
+// where the caller passes it that error.
+func (a *analyzer) errorResponses(fd funcDecl, body *ast.BlockStmt, h *Handler) {
+	s := &errorScan{a: a, funcs: map[scanKey]bool{}, vars: map[types.Object]bool{}, kinds: map[string]bool{}}
+	s.scan(fd.pkg, body, nil)
+
+	statuses := map[int]bool{http.StatusInternalServerError: true}
+	var problem *TypeRef
+	if pkg := a.errorsPackage(); pkg != nil {
+		byKind := a.kindStatuses(pkg)
+		for kind := range s.kinds {
+			if status, ok := byKind[kind]; ok {
+				statuses[status] = true
+			}
+		}
+		if tn, ok := pkg.Types.Scope().Lookup("Problem").(*types.TypeName); ok {
+			problem = a.typeRef(tn.Type())
+		}
+	}
+
+	codes := make([]int, 0, len(statuses))
+	for status := range statuses {
+		codes = append(codes, status)
+	}
+	sort.Ints(codes)
+	for _, status := range codes {
+		h.Responses = append(h.Responses, &Response{Status: status, Body: problem})
+	}
+}
+
+// errorScan walks the functions and package variables reachable from a
+// handler, collecting the apperrors kinds they use
+type errorScan struct {
+	a     *analyzer
+	funcs map[scanKey]bool
+	vars  map[types.Object]bool
+	kinds map[string]bool
+}
+
+// scanKey is a function scanned with the package-level variables passed
+// as its arguments, e.g. FromDB with gorm.ErrRecordNotFound
+type scanKey struct {
+	fn   *types.Func
+	args string
+}
+
+// params binds the parameters of the function being scanned to the
+// package-level variable passed for them, or nil when the argument is
+// anything else
+type params map[*types.Var]types.Object
+
+func (s *errorScan) scan(pkg *packages.Package, node ast.Node, bound params) {
+	info := pkg.TypesInfo
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt:
+			if s.excluded(info, n.Cond, bound) {
+				if n.Init != nil {
+					s.scan(pkg, n.Init, bound)
+				}
+				if n.Else != nil {
+					s.scan(pkg, n.Else, bound)
+				}
+				return false
+			}
+		case *ast.CaseClause:
+			excluded := len(n.List) > 0
+			for _, cond := range n.List {
+				excluded = excluded && s.excluded(info, cond, bound)
+			}
+			if excluded {
+				return false
+			}
+		case *ast.CallExpr:
+			if fn, ok := typeutil.Callee(info, n).(*types.Func); ok {
+				s.function(fn, callArgs(info, n, bound))
+			}
+		case *ast.Ident:
+			switch obj := info.Uses[n].(type) {
+			case *types.Const:
+				if isErrorKind(obj.Type()) && obj.Val().Kind() == constant.String {
+					s.kinds[constant.StringVal(obj.Val())] = true
+				}
+			case *types.Var:
+				s.variable(obj)
+			case *types.Func:
+				s.function(obj, nil)
+			}
+		}
+		return true
+	})
+}
+
+// excluded reports whether cond is errors.Is(p, target) for a parameter p
+// whose argument is not target
+func (s *errorScan) excluded(info *types.Info, cond ast.Expr, bound params) bool {
+	call, ok := cond.(*ast.CallExpr)
+	if !ok || len(call.Args) != 2 {
+		return false
+	}
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil || fn.Name() != "Is" || fn.Pkg() == nil || fn.Pkg().Path() != "errors" {
+		return false
+	}
+	ident, ok := call.Args[0].(*ast.Ident)
+	if !ok {
+		return false
+	}
+	p, ok := info.Uses[ident].(*types.Var)
+	if !ok {
+		return false
+	}
+	target := packageVar(info, call.Args[1])
+	arg, isParam := bound[p]
+	return isParam && target != nil && arg != target
+}
+
+// callArgs returns the package-level variables passed by call, in order,
+// following parameters of the calling function bound to one
+func callArgs(info *types.Info, call *ast.CallExpr, bound params) []types.Object {
+	args := make([]types.Object, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = packageVar(info, arg)
+		if ident, ok := arg.(*ast.Ident); ok && args[i] == nil {
+			if p, ok := info.Uses[ident].(*types.Var); ok {
+				args[i] = bound[p]
+			}
+		}
+	}
+	return args
+}
+
+// packageVar returns the package-level variable expr names, such as
+// gorm.ErrRecordNotFound
+func packageVar(info *types.Info, expr ast.Expr) types.Object {
+	var ident *ast.Ident
+	switch e := expr.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return nil
+	}
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+		return nil
+	}
+	return v
+}
+
+// variable scans the initializer of a package-level variable, such as
+// var ErrNotFound = apperrors.NotFound("..."). Maps keyed by kind, like
+// the status table of apperrors, name every kind and are skipped.
+func (s *errorScan) variable(obj *types.Var) {
+	if s.vars[obj] || obj.Pkg() == nil || obj.Parent() != obj.Pkg().Scope() {
+		return
+	}
+	s.vars[obj] = true
+	if m, ok := obj.Type().Underlying().(*types.Map); ok && isErrorKind(m.Key()) {
+		return
+	}
+	if v, ok := s.a.vars[obj]; ok {
+		s.scan(v.pkg, v.value, nil)
+	}
+}
+
+// function scans the body of fn called with args, or of every project
+// method that may implement fn when it is an interface method
+func (s *errorScan) function(fn *types.Func, args []types.Object) {
+	fn = fn.Origin()
+	key := scanKey{fn: fn}
+	for _, arg := range args {
+		if arg != nil {
+			key.args += arg.Pkg().Path() + "." + arg.Name()
+		}
+		key.args += ","
+	}
+	if s.funcs[key] {
+		return
+	}
+	s.funcs[key] = true
+
+	if decl, ok := s.a.decls[fn]; ok {
+		bound := params{}
+		if sig, ok := fn.Type().(*types.Signature); ok {
+			for i := 0; i < sig.Params().Len(); i++ {
+				bound[sig.Params().At(i)] = nil
+				if i < len(args) {
+					bound[sig.Params().At(i)] = args[i]
+				}
+			}
+		}
+		s.scan(decl.pkg, decl.decl.Body, bound)
+		return
+	}
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return
+	}
+	iface, ok := sig.Recv().Type().Underlying().(*types.Interface)
+	if !ok {
+		return
+	}
+	for impl := range s.a.decls {
+		if impl.Name() != fn.Name() {
+			continue
+		}
+		implSig, ok := impl.Type().(*types.Signature)
+		if !ok || implSig.Recv() == nil {
+			continue
+		}
+		recv := implSig.Recv().Type()
+		if types.Implements(recv, iface) || types.Implements(types.NewPointer(recv), iface) {
+			s.function(impl, args)
+		}
+	}
+}
+
+// errorsPackage returns the apperrors package of the project, if any
+func (a *analyzer) errorsPackage() *packages.Package {
+	for _, p := range a.pkgs {
+		if p.Types == nil {
+			continue
+		}
+		if tn, ok := p.Types.Scope().Lookup("Kind").(*types.TypeName); ok && isErrorKind(tn.Type()) {
+			return p
+		}
+	}
+	return nil
+}
+
+// kindStatuses reads the status of each kind from the map literals keyed
+// by kind in the apperrors package, e.g. KindNotFound: http.StatusNotFound
+func (a *analyzer) kindStatuses(p *packages.Package) map[string]int {
+	statuses := map[string]int{}
+	if p.TypesInfo == nil {
+		return statuses
+	}
+	for _, file := range p.Syntax {
+		ast.Inspect(file, func(n ast.Node) bool {
+			lit, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			m, ok := p.TypesInfo.TypeOf(lit).Underlying().(*types.Map)
+			if !ok || !isErrorKind(m.Key()) {
+				return true
+			}
+			for _, elt := range lit.Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				key, value := p.TypesInfo.Types[kv.Key].Value, p.TypesInfo.Types[kv.Value].Value
+				if key == nil || value == nil || key.Kind() != constant.String || value.Kind() != constant.Int {
+					continue
+				}
+				if status, ok := constant.Int64Val(value); ok {
+					statuses[constant.StringVal(key)] = int(status)
+				}
+			}
+			return false
+		})
+	}
+	return statuses
+}
+
+// isErrorKind reports whether t is the Kind of an apperrors package: a
+// named Kind type declared next to a Problem type
+func isErrorKind(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Name() != "Kind" || named.Obj().Pkg() == nil {
+		return false
+	}
+	_, ok = named.Obj().Pkg().Scope().Lookup("Problem").(*types.TypeName)
+	return ok
+}
@@ -0,0 +1,517 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Binding methods on *gin.Context grouped by where they read from
+var (
+	bodyBinders  = map[string]bool{"ShouldBindJSON": true, "BindJSON": true, "ShouldBind": true, "Bind": true, "ShouldBindWith": true, "ShouldBindBodyWith": true}
+	queryBinders = map[string]bool{"ShouldBindQuery": true, "BindQuery": true}
+	uriBinders   = map[string]bool{"ShouldBindUri": true, "BindUri": true}
+	jsonWriters  = map[string]bool{"JSON": true, "IndentedJSON": true, "PureJSON": true, "SecureJSON": true, "AbortWithStatusJSON": true}
+	statusWriter = map[string]bool{"Status": true, "AbortWithStatus": true, "String": true, "Data": true}
+)
+
+// strconv conversions that reveal the type of a path or query value
+var paramConversions = map[string]*TypeRef{
+	"Atoi":       {Kind: KindInteger},
+	"ParseInt":   {Kind: KindInteger, Format: "int64"},
+	"ParseUint":  {Kind: KindInteger, Format: "int64"},
+	"ParseFloat": {Kind: KindNumber, Format: "double"},
+	"ParseBool":  {Kind: KindBoolean},
+}
+
+// handlerFor resolves the final handler of a route registration
+func (a *analyzer) handlerFor(fd funcDecl, expr ast.Expr) *Handler {
+	info := fd.pkg.TypesInfo
+
+	if lit, ok := expr.(*ast.FuncLit); ok {
+		h := &Handler{
+			Name:     "func literal",
+			Package:  fd.pkg.Name,
+			Position: fd.pkg.Fset.Position(lit.Pos()),
+		}
+		a.inspectHandler(fd, lit.Type, lit.Body, h)
+		return h
+	}
+
+	var fn *types.Func
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			fn, _ = sel.Obj().(*types.Func)
+		} else {
+			fn, _ = info.Uses[e.Sel].(*types.Func)
+		}
+	case *ast.Ident:
+		fn, _ = info.Uses[e].(*types.Func)
+	}
+	if fn == nil {
+		return &Handler{Name: exprName(expr), Package: fd.pkg.Name, Func: exprName(expr)}
+	}
+
+	fn = fn.Origin()
+	if h, ok := a.handlers[fn]; ok {
+		return h
+	}
+
+	h := &Handler{Name: funcName(fn), Func: fn.Name()}
+	if fn.Pkg() != nil {
+		h.Package = fn.Pkg().Name()
+	}
+	a.handlers[fn] = h
+
+	if decl, ok := a.decls[fn]; ok {
+		h.Position = decl.pkg.Fset.Position(decl.decl.Pos())
+		if decl.decl.Doc != nil {
+			h.Doc = decl.decl.Doc.Text()
+		}
+		a.inspectHandler(decl, decl.decl.Type, decl.decl.Body, h)
+		a.failureResponses(decl, h)
+		annotatedParams(h)
+	}
+	return h
+}
+
+// funcName renders fn as pkg.Func or pkg.Type.Method
+func funcName(fn *types.Func) string {
+	name := fn.Name()
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		recv := sig.Recv().Type()
+		if p, ok := recv.(*types.Pointer); ok {
+			recv = p.Elem()
+		}
+		if named, ok := recv.(*types.Named); ok {
+			name = named.Obj().Name() + "." + name
+		}
+	}
+	if fn.Pkg() != nil {
+		name = fn.Pkg().Name() + "." + name
+	}
+	return name
+}
+
+func (a *analyzer) inspectHandler(fd funcDecl, ftype *ast.FuncType, body *ast.BlockStmt, h *Handler) {
+	info := fd.pkg.TypesInfo
+
+	var ctx types.Object
+	for _, field := range ftype.Params.List {
+		if isGinType(info.TypeOf(field.Type), "Context") && len(field.Names) > 0 {
+			ctx = info.Defs[field.Names[0]]
+			break
+		}
+	}
+	if ctx == nil {
+		return
+	}
+
+	// ctxCall returns the method name when call is ctx.<Method>(...)
+	ctxCall := func(expr ast.Expr) (string, *ast.CallExpr) {
+		call, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return "", nil
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return "", nil
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && info.ObjectOf(ident) == ctx {
+			return sel.Sel.Name, call
+		}
+		return "", nil
+	}
+
+	typed := map[ast.Expr]*TypeRef{}
+	addsErrors := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		// strconv.ParseUint(c.Param("id"), 10, 32) types the "id" parameter
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && len(call.Args) > 0 {
+			if pkgIdent, ok := sel.X.(*ast.Ident); ok {
+				if pn, ok := info.Uses[pkgIdent].(*types.PkgName); ok && pn.Imported().Path() == "strconv" {
+					if t, ok := paramConversions[sel.Sel.Name]; ok {
+						typed[call.Args[0]] = t
+					}
+				}
+			}
+		}
+
+		method, ctxc := ctxCall(call)
+		if ctxc == nil {
+			a.queryParams(info, call, h)
+			return true
+		}
+		switch {
+		case method == "Param":
+			if name, ok := argString(info, call, 0); ok {
+				h.PathParams = addParam(h.PathParams, &Param{Name: name, Type: typeOrString(typed[call]), Required: true})
+			}
+		case method == "Query" || method == "DefaultQuery" || method == "GetQuery":
+			if name, ok := argString(info, call, 0); ok {
+				h.QueryParams = addParam(h.QueryParams, &Param{Name: name, Type: typeOrString(typed[call])})
+			}
+		case bodyBinders[method]:
+			if t := a.pointeeRef(info, call, 0); t != nil {
+				h.Body = t
+			}
+		case queryBinders[method]:
+			for _, f := range a.boundFields(info, call) {
+				name := f.FormName
+				if name == "" {
+					name = f.Name
+				}
+				h.QueryParams = addParam(h.QueryParams, &Param{Name: name, Type: f.Type, Required: f.Required, Rules: f.Rules})
+			}
+		case uriBinders[method]:
+			for _, f := range a.boundFields(info, call) {
+				if f.URIName == "" {
+					continue
+				}
+				h.PathParams = addParam(h.PathParams, &Param{Name: f.URIName, Type: f.Type, Required: true, Rules: f.Rules})
+			}
+		case jsonWriters[method]:
+			for _, status := range argStatuses(info, body, call) {
+				resp := &Response{Status: status}
+				if len(call.Args) > 1 {
+					resp.Body = a.bodyRef(info, call.Args[1])
+				}
+				h.Responses = append(h.Responses, resp)
+			}
+		case statusWriter[method]:
+			for _, status := range argStatuses(info, body, call) {
+				h.Responses = append(h.Responses, &Response{Status: status})
+			}
+		case method == "Error" || method == "AbortWithError":
+			addsErrors = true
+		}
+		return true
+	})
+	// Errors documented with @Failure are not inferred, see failureResponses
+	if addsErrors && !strings.Contains(h.Doc, "@Failure") {
+		a.errorResponses(fd, body, h)
+	}
+}
+
+// failureResponses adds the responses of swag @Failure annotations, e.g.
+// "@Failure 400,404 {object} apperrors.Problem", which document the errors
+// a handler returns through c.Error rather than writes itself
+func (a *analyzer) failureResponses(fd funcDecl, h *Handler) {
+	for _, line := range strings.Split(h.Doc, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "@Failure" {
+			continue
+		}
+		var body *TypeRef
+		if len(fields) > 3 && fields[2] == "{object}" {
+			body = a.namedRef(fd, fields[3])
+		}
+		for _, code := range strings.Split(fields[1], ",") {
+			if status, err := strconv.Atoi(code); err == nil {
+				h.Responses = append(h.Responses, &Response{Status: status, Body: body})
+			}
+		}
+	}
+}
+
+// namedRef resolves a type named in an annotation, such as Problem or
+// apperrors.Problem, from the file declaring fd
+func (a *analyzer) namedRef(fd funcDecl, name string) *TypeRef {
+	if fd.pkg.Types == nil {
+		return nil
+	}
+	scope := fd.pkg.Types.Scope()
+	if qualifier, typeName, ok := strings.Cut(name, "."); ok {
+		pkg := a.importedAs(fd, qualifier)
+		if pkg == nil {
+			return nil
+		}
+		scope, name = pkg.Scope(), typeName
+	}
+	if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+		return a.typeRef(tn.Type())
+	}
+	return nil
+}
+
+// importedAs returns the package the file declaring fd imports as name
+func (a *analyzer) importedAs(fd funcDecl, name string) *types.Package {
+	info := fd.pkg.TypesInfo
+	for _, file := range fd.pkg.Syntax {
+		if fd.decl.Pos() < file.Pos() || fd.decl.Pos() >= file.End() {
+			continue
+		}
+		for _, imp := range file.Imports {
+			obj := info.Implicits[imp]
+			if imp.Name != nil {
+				obj = info.Defs[imp.Name]
+			}
+			if pn, ok := obj.(*types.PkgName); ok && pn.Name() == name {
+				return pn.Imported()
+			}
+		}
+	}
+	return nil
+}
+
+// swagTypes maps the types of swag @Param annotations to wire types
+var swagTypes = map[string]*TypeRef{
+	"int":     {Kind: KindInteger},
+	"integer": {Kind: KindInteger},
+	"number":  {Kind: KindNumber},
+	"float":   {Kind: KindNumber},
+	"bool":    {Kind: KindBoolean},
+	"boolean": {Kind: KindBoolean},
+}
+
+// paramAnnotation matches swag "@Param name in type required "description""
+var paramAnnotation = regexp.MustCompile(`^@Param\s+(\S+)\s+(query|path)\s+(\S+)\s+(true|false)(?:\s+"(.*)")?`)
+
+// annotatedParams adds the descriptions of swag @Param annotations to the
+// parameters found in the code, and the query parameters only read
+// through c.Request.URL.Query(), such as the pkg/query ones
+func annotatedParams(h *Handler) {
+	for _, line := range strings.Split(h.Doc, "\n") {
+		m := paramAnnotation.FindStringSubmatch(strings.TrimSpace(line))
+		// Names such as filter[field] are placeholders for swag, which
+		// cannot list the fields; the analyzer derives those itself
+		if m == nil || strings.Contains(m[1], "[") {
+			continue
+		}
+		params := &h.QueryParams
+		if m[2] == "path" {
+			params = &h.PathParams
+		}
+		known := false
+		for _, p := range *params {
+			if p.Name == m[1] {
+				p.Description, known = m[5], true
+			}
+		}
+		if known || m[2] == "path" {
+			continue
+		}
+		t := &TypeRef{Kind: KindString}
+		if st, ok := swagTypes[m[3]]; ok {
+			copied := *st
+			t = &copied
+		}
+		*params = append(*params, &Param{Name: m[1], Type: t, Required: m[4] == "true", Description: m[5]})
+	}
+}
+
+// addParam adds p unless a parameter with the same name is already known;
+// a typed occurrence replaces an untyped (string) one
+func addParam(params []*Param, p *Param) []*Param {
+	for i, existing := range params {
+		if existing.Name == p.Name {
+			if existing.Type.Kind == KindString && p.Type.Kind != KindString {
+				params[i] = p
+			}
+			return params
+		}
+	}
+	return append(params, p)
+}
+
+func typeOrString(t *TypeRef) *TypeRef {
+	if t == nil {
+		return &TypeRef{Kind: KindString}
+	}
+	return t
+}
+
+// pointeeRef returns the type bound through &req in a binding call
+func (a *analyzer) pointeeRef(info *types.Info, call *ast.CallExpr, i int) *TypeRef {
+	if len(call.Args) <= i {
+		return nil
+	}
+	t := info.TypeOf(call.Args[i])
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+	return a.typeRef(t)
+}
+
+// boundFields returns the fields of the struct bound through &req
+func (a *analyzer) boundFields(info *types.Info, call *ast.CallExpr) []*Field {
+	ref := a.pointeeRef(info, call, 0)
+	if ref == nil {
+		return nil
+	}
+	switch ref.Kind {
+	case KindRef:
+		if m := a.project.Models[ref.Ref]; m != nil {
+			return m.Fields
+		}
+	case KindObject:
+		return ref.Properties
+	}
+	return nil
+}
+
+// bodyRef describes a response body; gin.H literals become inline objects
+// so the envelope keys (success, data, message) are documented
+func (a *analyzer) bodyRef(info *types.Info, expr ast.Expr) *TypeRef {
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return a.typeRef(info.TypeOf(expr))
+	}
+	t := info.TypeOf(lit)
+	if t == nil {
+		return &TypeRef{Kind: KindAny}
+	}
+	if _, isMap := t.Underlying().(*types.Map); !isMap {
+		return a.typeRef(t)
+	}
+	obj := &TypeRef{Kind: KindObject}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := constString(info, kv.Key)
+		if !ok {
+			continue
+		}
+		obj.Properties = append(obj.Properties, &Field{
+			Name:     key,
+			JSONName: key,
+			Type:     a.bodyRef(info, kv.Value),
+			Required: true,
+		})
+	}
+	return obj
+}
+
+func argString(info *types.Info, call *ast.CallExpr, i int) (string, bool) {
+	if len(call.Args) <= i {
+		return "", false
+	}
+	return constString(info, call.Args[i])
+}
+
+func argInt(info *types.Info, call *ast.CallExpr, i int) (int, bool) {
+	if len(call.Args) <= i {
+		return 0, false
+	}
+	tv, ok := info.Types[call.Args[i]]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+		return 0, false
+	}
+	v, ok := constant.Int64Val(tv.Value)
+	return int(v), ok
+}
+
+// argStatuses returns the statuses the first argument of call may be: a
+// constant, or the constants a local variable is assigned in body, as in
+// status := http.StatusOK followed by status = http.StatusServiceUnavailable
+func argStatuses(info *types.Info, body *ast.BlockStmt, call *ast.CallExpr) []int {
+	if status, ok := argInt(info, call, 0); ok {
+		return []int{status}
+	}
+	if len(call.Args) == 0 {
+		return nil
+	}
+	ident, ok := call.Args[0].(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := info.Uses[ident].(*types.Var)
+	if !ok {
+		return nil
+	}
+	var statuses []int
+	add := func(lhs *ast.Ident, rhs ast.Expr) {
+		if info.ObjectOf(lhs) != v {
+			return
+		}
+		tv, ok := info.Types[rhs]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.Int {
+			return
+		}
+		if status, ok := constant.Int64Val(tv.Value); ok {
+			statuses = append(statuses, int(status))
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, lhs := range n.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						add(ident, n.Rhs[i])
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == len(n.Values) {
+				for i, name := range n.Names {
+					add(name, n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	return statuses
+}
+
+// Summary returns the handler's one-line summary: a swag @Summary line when
+// present, otherwise the first sentence of its doc comment
+func (h *Handler) Summary() string {
+	if v := h.annotation("@Summary"); v != "" {
+		return v
+	}
+	// The summary is the first sentence of the first paragraph, which may
+	// wrap over several lines
+	var words []string
+	for _, line := range strings.Split(h.Doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "@") || strings.HasSuffix(line, " godoc") {
+			continue
+		}
+		if line == "" {
+			if len(words) > 0 {
+				break
+			}
+			continue
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	for i, word := range words {
+		if strings.HasSuffix(word, ".") && !abbreviations[strings.ToLower(word)] {
+			return strings.Join(words[:i+1], " ")
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// abbreviations end in a period without ending the sentence
+var abbreviations = map[string]bool{"e.g.": true, "i.e.": true, "etc.": true, "vs.": true}
+
+// Description returns the swag @Description of the handler, if any
+func (h *Handler) Description() string {
+	return h.annotation("@Description")
+}
+
+func (h *Handler) annotation(name string) string {
+	for _, line := range strings.Split(h.Doc, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, name+" ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, name))
+		}
+	}
+	return ""
+}
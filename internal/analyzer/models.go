@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// wellKnown maps named types with custom JSON encodings to their wire type
+var wellKnown = map[string]*TypeRef{
+	"time.Time":                             {Kind: KindString, Format: "date-time"},
+	"time.Duration":                         {Kind: KindInteger, Format: "int64"},
+	"gorm.io/gorm.DeletedAt":                {Kind: KindString, Format: "date-time", Nullable: true},
+	"database/sql.NullTime":                 {Kind: KindString, Format: "date-time", Nullable: true},
+	"database/sql.NullString":               {Kind: KindString, Nullable: true},
+	"database/sql.NullInt64":                {Kind: KindInteger, Format: "int64", Nullable: true},
+	"database/sql.NullInt32":                {Kind: KindInteger, Format: "int32", Nullable: true},
+	"database/sql.NullFloat64":              {Kind: KindNumber, Format: "double", Nullable: true},
+	"database/sql.NullBool":                 {Kind: KindBoolean, Nullable: true},
+	"encoding/json.RawMessage":              {Kind: KindAny},
+	"github.com/google/uuid.UUID":           {Kind: KindString, Format: "uuid"},
+	"gorm.io/datatypes.JSON":                {Kind: KindAny},
+	"github.com/shopspring/decimal.Decimal": {Kind: KindString, Format: "decimal"},
+}
+
+// typeRef converts a Go type into its wire-level description, registering
+// named structs as models on the way
+func (a *analyzer) typeRef(t types.Type) *TypeRef {
+	if t == nil {
+		return &TypeRef{Kind: KindAny}
+	}
+	t = types.Unalias(t)
+
+	switch tt := t.(type) {
+	case *types.Pointer:
+		ref := a.typeRef(tt.Elem())
+		if ref.Kind != KindRef && ref.Kind != KindObject {
+			copied := *ref
+			copied.Nullable = true
+			return &copied
+		}
+		return ref
+	case *types.Named:
+		obj := tt.Obj()
+		if obj.Pkg() != nil {
+			key := obj.Pkg().Path() + "." + obj.Name()
+			if ref, ok := wellKnown[key]; ok {
+				copied := *ref
+				return &copied
+			}
+		}
+		if _, ok := tt.Underlying().(*types.Struct); ok && obj.Pkg() != nil {
+			name := obj.Pkg().Name() + "." + obj.Name()
+			a.registerModel(name, tt)
+			return &TypeRef{Kind: KindRef, Ref: name}
+		}
+		return a.typeRef(tt.Underlying())
+	case *types.Basic:
+		return basicRef(tt)
+	case *types.Slice:
+		if b, ok := tt.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Byte {
+			return &TypeRef{Kind: KindString, Format: "byte"}
+		}
+		return &TypeRef{Kind: KindArray, Elem: a.typeRef(tt.Elem())}
+	case *types.Array:
+		return &TypeRef{Kind: KindArray, Elem: a.typeRef(tt.Elem())}
+	case *types.Map:
+		return &TypeRef{Kind: KindMap, Elem: a.typeRef(tt.Elem())}
+	case *types.Struct:
+		return &TypeRef{Kind: KindObject, Properties: a.structFields(tt)}
+	}
+	return &TypeRef{Kind: KindAny}
+}
+
+func basicRef(b *types.Basic) *TypeRef {
+	switch b.Kind() {
+	case types.Bool, types.UntypedBool:
+		return &TypeRef{Kind: KindBoolean}
+	case types.Int8, types.Int16, types.Int32, types.Uint8, types.Uint16:
+		return &TypeRef{Kind: KindInteger, Format: "int32"}
+	case types.Int, types.Int64, types.Uint, types.Uint32, types.Uint64, types.Uintptr, types.UntypedInt:
+		return &TypeRef{Kind: KindInteger, Format: "int64"}
+	case types.Float32:
+		return &TypeRef{Kind: KindNumber, Format: "float"}
+	case types.Float64, types.UntypedFloat:
+		return &TypeRef{Kind: KindNumber, Format: "double"}
+	case types.String, types.UntypedString:
+		return &TypeRef{Kind: KindString}
+	}
+	return &TypeRef{Kind: KindAny}
+}
+
+func (a *analyzer) registerModel(name string, named *types.Named) {
+	if _, ok := a.project.Models[name]; ok {
+		return
+	}
+
+	obj := named.Obj()
+	m := &Model{Name: name, Package: obj.Pkg().Name(), TypeName: obj.Name(), Doc: a.typeDocs[obj]}
+	// Register before walking fields so self-referencing types terminate
+	a.project.Models[name] = m
+	m.Fields = a.structFields(named.Underlying().(*types.Struct))
+}
+
+// structFields flattens a struct into its JSON-visible fields, following
+// encoding/json rules for embedded structs and json:"-"
+func (a *analyzer) structFields(st *types.Struct) []*Field {
+	var fields []*Field
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		jsonName, jsonOpts := splitTag(tag.Get("json"))
+		if jsonName == "-" && jsonOpts == "" {
+			continue
+		}
+
+		if v.Embedded() && jsonName == "" {
+			ft := types.Unalias(v.Type())
+			if p, ok := ft.(*types.Pointer); ok {
+				ft = p.Elem()
+			}
+			if st, ok := ft.Underlying().(*types.Struct); ok {
+				fields = append(fields, a.structFields(st)...)
+				continue
+			}
+		}
+		if !v.Exported() {
+			continue
+		}
+
+		f := &Field{
+			Name:     v.Name(),
+			JSONName: jsonName,
+			Type:     a.typeRef(v.Type()),
+		}
+		if f.JSONName == "" {
+			f.JSONName = v.Name()
+		}
+		if strings.Contains(jsonOpts, "string") {
+			f.Type = &TypeRef{Kind: KindString}
+		}
+		f.FormName, _ = splitTag(tag.Get("form"))
+		f.URIName, _ = splitTag(tag.Get("uri"))
+
+		for _, key := range []string{"binding", "validate"} {
+			for _, r := range ParseRules(tag.Get(key)) {
+				if r.Name == "required" {
+					f.Required = true
+					continue
+				}
+				f.Rules = append(f.Rules, r)
+			}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func splitTag(tag string) (name, opts string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// ParseRules splits a go-playground/validator tag such as
+// "required,min=2,max=100" into rules. Rules after "dive" apply to
+// elements and are skipped, as is omitempty.
+func ParseRules(tag string) []Rule {
+	if tag == "" || tag == "-" {
+		return nil
+	}
+	var rules []Rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "dive" {
+			break
+		}
+		if part == "" || part == "omitempty" {
+			continue
+		}
+		r := Rule{Name: part}
+		if i := strings.Index(part, "="); i >= 0 {
+			r.Name, r.Param = part[:i], part[i+1:]
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
@@ -0,0 +1,205 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// queryField is a field of the whitelist pkg/query builds with FieldsOf
+type queryField struct {
+	name       string
+	typ        *TypeRef
+	searchable bool
+}
+
+// queryParams adds the parameters of a query.Parse(values, fields) call:
+// filter[<field>] for each whitelisted field, sort and fields limited to
+// the whitelist, and search when a field is searchable
+func (a *analyzer) queryParams(info *types.Info, call *ast.CallExpr, h *Handler) {
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil || fn.Name() != "Parse" || len(call.Args) != 2 || fn.Pkg() == nil {
+		return
+	}
+	fieldsOf, ok := fn.Pkg().Scope().Lookup("FieldsOf").(*types.Func)
+	if !ok {
+		return
+	}
+	model := a.fieldsModel(info, call.Args[1], fieldsOf)
+	if model == nil {
+		return
+	}
+	fields := queryFields(model)
+	if len(fields) == 0 {
+		return
+	}
+
+	ops := a.queryOperators(fn.Pkg())
+	var names, sorts, searchable []string
+	for _, f := range fields {
+		desc := "Filter on " + f.name
+		if len(ops) > 0 {
+			desc += "; filter[" + f.name + "][op] applies an operator: " + strings.Join(ops, ", ")
+		}
+		h.QueryParams = addParam(h.QueryParams, &Param{Name: "filter[" + f.name + "]", Type: f.typ, Description: desc})
+		names = append(names, f.name)
+		sorts = append(sorts, f.name, "-"+f.name)
+		if f.searchable {
+			searchable = append(searchable, f.name)
+		}
+	}
+	h.QueryParams = addParam(h.QueryParams, &Param{
+		Name:        "sort",
+		Type:        &TypeRef{Kind: KindArray, Elem: &TypeRef{Kind: KindString, Enum: sorts}},
+		Description: "Fields to sort by, prefixed with - for descending; repeated or comma-separated",
+	})
+	h.QueryParams = addParam(h.QueryParams, &Param{
+		Name:        "fields",
+		Type:        &TypeRef{Kind: KindArray, Elem: &TypeRef{Kind: KindString, Enum: names}},
+		Description: "Fields to return; repeated or comma-separated",
+	})
+	if len(searchable) > 0 {
+		h.QueryParams = addParam(h.QueryParams, &Param{
+			Name:        "search",
+			Type:        &TypeRef{Kind: KindString},
+			Description: "Case-insensitive text matched against " + strings.Join(searchable, ", "),
+		})
+	}
+}
+
+// fieldsModel resolves the whitelist argument of query.Parse to the model
+// passed to FieldsOf, following package-level variables such as
+// var queryFields = query.FieldsOf(&Orders{})
+func (a *analyzer) fieldsModel(info *types.Info, expr ast.Expr, fieldsOf *types.Func) types.Type {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return a.fieldsModel(info, e.X, fieldsOf)
+	case *ast.Ident:
+		if v, ok := a.vars[info.Uses[e]]; ok {
+			return a.fieldsModel(v.pkg.TypesInfo, v.value, fieldsOf)
+		}
+	case *ast.CallExpr:
+		if typeutil.StaticCallee(info, e) == fieldsOf && len(e.Args) == 1 {
+			return info.TypeOf(e.Args[0])
+		}
+	}
+	return nil
+}
+
+// queryOperators returns the keys of the operators map of pkg/query
+func (a *analyzer) queryOperators(pkg *types.Package) []string {
+	v, ok := a.vars[pkg.Scope().Lookup("operators")]
+	if !ok {
+		return nil
+	}
+	lit, ok := v.value.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var ops []string
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if tv := v.pkg.TypesInfo.Types[kv.Key]; tv.Value != nil && tv.Value.Kind() == constant.String {
+				ops = append(ops, constant.StringVal(tv.Value))
+			}
+		}
+	}
+	return ops
+}
+
+// queryFields mirrors FieldsOf: the columns of a GORM model named by their
+// json tag, leaving out json:"-", query:"-", gorm:"-" and the columns
+// filters cannot apply to
+func queryFields(t types.Type) []queryField {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var fields []queryField
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		gormTag := tag.Get("gorm")
+		if !f.Exported() || gormTag == "-" || strings.HasPrefix(gormTag, "-:") || tag.Get("query") == "-" {
+			continue
+		}
+		if f.Anonymous() || strings.Contains(gormTag, "embedded") {
+			fields = append(fields, queryFields(f.Type())...)
+			continue
+		}
+		typ, ok := columnType(f.Type())
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = columnName(f.Name(), gormTag)
+		}
+		fields = append(fields, queryField{name: name, typ: typ, searchable: typ.Kind == KindString && typ.Format == ""})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+	return fields
+}
+
+// columnType returns the filter value type of a column, or false for a
+// column FieldsOf leaves out because a filter value cannot be converted
+// to it, such as an association or gorm.DeletedAt
+func columnType(t types.Type) (*TypeRef, bool) {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time" {
+		return &TypeRef{Kind: KindString, Format: "date-time"}, true
+	}
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case b.Info()&types.IsInteger != 0:
+		return &TypeRef{Kind: KindInteger}, true
+	case b.Info()&types.IsFloat != 0:
+		return &TypeRef{Kind: KindNumber}, true
+	case b.Info()&types.IsBoolean != 0:
+		return &TypeRef{Kind: KindBoolean}, true
+	case b.Info()&types.IsString != 0:
+		return &TypeRef{Kind: KindString}, true
+	}
+	return nil, false
+}
+
+// columnName is the column GORM gives a field: its column tag, or the
+// snake_case field name, e.g. UserID becomes user_id
+func columnName(field, gormTag string) string {
+	for _, opt := range strings.Split(gormTag, ";") {
+		if k, v, ok := strings.Cut(opt, ":"); ok && strings.EqualFold(strings.TrimSpace(k), "column") {
+			return strings.TrimSpace(v)
+		}
+	}
+	runes := []rune(field)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (nextLower && unicode.IsUpper(runes[i-1])) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
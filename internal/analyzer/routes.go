@@ -0,0 +1,322 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"HEAD":    true,
+	"OPTIONS": true,
+}
+
+// group is a router (engine or RouterGroup) tracked through assignments
+type group struct {
+	prefix     string
+	middleware []string
+}
+
+func (g *group) child(relative string, middleware []string) *group {
+	mw := make([]string, 0, len(g.middleware)+len(middleware))
+	mw = append(mw, g.middleware...)
+	mw = append(mw, middleware...)
+	return &group{prefix: joinPaths(g.prefix, relative), middleware: mw}
+}
+
+// env binds local variables and parameters to the routers they hold
+type env map[types.Object]*group
+
+// collectRoutes walks route registrations starting from main packages and
+// then from any SetupRoutes method that was not reached that way
+func (a *analyzer) collectRoutes() {
+	decls := a.sortedDecls()
+
+	for _, fd := range decls {
+		if fd.pkg.Name == "main" && fd.decl.Recv == nil && fd.decl.Name.Name == "main" {
+			a.walkFunc(fd, env{}, true)
+		}
+	}
+
+	// Unreached SetupRoutes taking an engine are roots of their own; the
+	// remaining ones are assumed to be mounted under the base path.
+	for _, engineRoots := range []bool{true, false} {
+		for _, fd := range decls {
+			if fd.decl.Name.Name != "SetupRoutes" || a.visited[fd.decl] {
+				continue
+			}
+			bound := env{}
+			takesEngine := false
+			for _, field := range fd.decl.Type.Params.List {
+				t := fd.pkg.TypesInfo.TypeOf(field.Type)
+				var g *group
+				switch {
+				case isGinType(t, "Engine"):
+					g = &group{}
+					takesEngine = true
+				case isGinType(t, "RouterGroup"), isGinType(t, "IRouter"), isGinType(t, "IRoutes"):
+					g = &group{prefix: a.opts.BasePath}
+				}
+				if g == nil {
+					continue
+				}
+				for _, name := range field.Names {
+					if obj := fd.pkg.TypesInfo.Defs[name]; obj != nil {
+						bound[obj] = g
+					}
+				}
+			}
+			if len(bound) == 0 || takesEngine != engineRoots {
+				continue
+			}
+			a.walkFunc(fd, bound, engineRoots)
+		}
+	}
+}
+
+func (a *analyzer) walkFunc(fd funcDecl, bound env, mounted bool) {
+	if a.stack[fd.decl] {
+		return
+	}
+	a.stack[fd.decl] = true
+	a.visited[fd.decl] = true
+	defer delete(a.stack, fd.decl)
+
+	info := fd.pkg.TypesInfo
+	ast.Inspect(fd.decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i, rhs := range n.Rhs {
+					if g := a.groupOf(fd.pkg, rhs, bound); g != nil {
+						if obj := objectOf(info, n.Lhs[i]); obj != nil {
+							bound[obj] = g
+						}
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == len(n.Values) {
+				for i, v := range n.Values {
+					if g := a.groupOf(fd.pkg, v, bound); g != nil {
+						if obj := info.Defs[n.Names[i]]; obj != nil {
+							bound[obj] = g
+						}
+					}
+				}
+			}
+		case *ast.CallExpr:
+			a.visitCall(fd, n, bound, mounted)
+		}
+		return true
+	})
+}
+
+func (a *analyzer) visitCall(fd funcDecl, call *ast.CallExpr, bound env, mounted bool) {
+	info := fd.pkg.TypesInfo
+
+	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+		if g := a.groupOf(fd.pkg, sel.X, bound); g != nil {
+			method := sel.Sel.Name
+			switch {
+			case httpMethods[method] || method == "Any":
+				if len(call.Args) >= 1 {
+					a.addRoute(fd, call, method, g, call.Args[0], call.Args[1:], mounted)
+				}
+				return
+			case method == "Handle":
+				if len(call.Args) >= 2 {
+					m, ok := constString(info, call.Args[0])
+					if !ok {
+						m = "ANY"
+					}
+					a.addRoute(fd, call, strings.ToUpper(m), g, call.Args[1], call.Args[2:], mounted)
+				}
+				return
+			case method == "Use":
+				for _, arg := range call.Args {
+					g.middleware = append(g.middleware, exprName(arg))
+				}
+				return
+			}
+		}
+	}
+
+	// Follow calls that hand a router to another function, such as
+	// h.users.SetupRoutes(api), and calls of functions that may build a
+	// router of their own, such as run(cfg, logger) in main
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil {
+		return
+	}
+	callee, ok := a.decls[fn.Origin()]
+	if !ok {
+		return
+	}
+	var args map[int]*group
+	for i, arg := range call.Args {
+		if g := a.groupOf(fd.pkg, arg, bound); g != nil {
+			if args == nil {
+				args = map[int]*group{}
+			}
+			args[i] = g
+		}
+	}
+	if args == nil {
+		if !a.visited[callee.decl] {
+			a.walkFunc(callee, env{}, mounted)
+		}
+		return
+	}
+	calleeEnv := env{}
+	i := 0
+	for _, field := range callee.decl.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			i++
+			continue
+		}
+		for _, name := range names {
+			if g, ok := args[i]; ok {
+				if obj := callee.pkg.TypesInfo.Defs[name]; obj != nil {
+					calleeEnv[obj] = g
+				}
+			}
+			i++
+		}
+	}
+	a.walkFunc(callee, calleeEnv, mounted)
+}
+
+func (a *analyzer) addRoute(fd funcDecl, call *ast.CallExpr, method string, g *group, pathArg ast.Expr, handlers []ast.Expr, mounted bool) {
+	relative, ok := a.pathString(fd.pkg.TypesInfo, pathArg)
+	if !ok {
+		relative = "/{" + types.ExprString(pathArg) + "}"
+		a.warnf("%s: route path %s is not a constant", fd.pkg.Fset.Position(pathArg.Pos()), types.ExprString(pathArg))
+	}
+
+	route := &Route{
+		Method:     method,
+		Path:       joinPaths(g.prefix, relative),
+		Middleware: append([]string{}, g.middleware...),
+		Mounted:    mounted,
+		Position:   fd.pkg.Fset.Position(call.Pos()),
+	}
+	if route.Method == "Any" {
+		route.Method = "ANY"
+	}
+	if n := len(handlers); n > 0 {
+		for _, mw := range handlers[:n-1] {
+			route.Middleware = append(route.Middleware, exprName(mw))
+		}
+		route.Handler = a.handlerFor(fd, handlers[n-1])
+	}
+	a.project.Routes = append(a.project.Routes, route)
+}
+
+// groupOf resolves expr to the router it evaluates to, if known
+func (a *analyzer) groupOf(pkg *packages.Package, expr ast.Expr, bound env) *group {
+	info := pkg.TypesInfo
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return a.groupOf(pkg, e.X, bound)
+	case *ast.Ident:
+		if obj := info.ObjectOf(e); obj != nil {
+			return bound[obj]
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		if pkgIdent, ok := sel.X.(*ast.Ident); ok {
+			if pn, ok := info.Uses[pkgIdent].(*types.PkgName); ok && pn.Imported().Path() == ginPkgPath {
+				switch sel.Sel.Name {
+				case "New":
+					return &group{}
+				case "Default":
+					return &group{middleware: []string{"gin.Logger", "gin.Recovery"}}
+				}
+				return nil
+			}
+		}
+		if sel.Sel.Name != "Group" || len(e.Args) == 0 {
+			return nil
+		}
+		parent := a.groupOf(pkg, sel.X, bound)
+		if parent == nil {
+			return nil
+		}
+		relative, ok := a.pathString(info, e.Args[0])
+		if !ok {
+			relative = "/{" + types.ExprString(e.Args[0]) + "}"
+		}
+		var middleware []string
+		for _, arg := range e.Args[1:] {
+			middleware = append(middleware, exprName(arg))
+		}
+		return parent.child(relative, middleware)
+	}
+	return nil
+}
+
+func objectOf(info *types.Info, expr ast.Expr) types.Object {
+	if ident, ok := expr.(*ast.Ident); ok {
+		return info.ObjectOf(ident)
+	}
+	return nil
+}
+
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+// exprName renders a middleware or handler expression for display,
+// dropping call arguments: middleware.Auth(cfg.Secret) becomes middleware.Auth
+func exprName(expr ast.Expr) string {
+	if call, ok := expr.(*ast.CallExpr); ok {
+		return exprName(call.Fun)
+	}
+	return types.ExprString(expr)
+}
+
+// joinPaths mirrors gin's own joining of group prefixes and relative paths
+func joinPaths(absolute, relative string) string {
+	if relative == "" {
+		if absolute == "" {
+			return "/"
+		}
+		return absolute
+	}
+	joined := path.Join("/", absolute, relative)
+	if strings.HasSuffix(relative, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+// PathParams returns the parameter names in a gin path, e.g. ["id"] for
+// /users/:id
+func PathParams(p string) []string {
+	var names []string
+	for _, segment := range strings.Split(p, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			names = append(names, segment[1:])
+		}
+	}
+	return names
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/app/internal/orders"
+	"example.com/app/pkg/middleware"
+)
+
+func main() {
+	if err := run(); err != nil {
+		os.Exit(1)
+	}
+}
+
+// run builds its own router, so routes are only found by following the
+// call from main
+func run() error {
+	r := gin.New()
+	r.Use(middleware.Logger())
+	r.GET("/metrics", metrics)
+
+	api := r.Group("/api/v1")
+	orders.NewHandlers(orders.NewService(orders.NewRepository())).SetupRoutes(api)
+	return r.Run()
+}
+
+func metrics(c *gin.Context) {
+	c.String(http.StatusOK, "")
+}
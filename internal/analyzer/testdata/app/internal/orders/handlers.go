@@ -0,0 +1,100 @@
+package orders
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/app/pkg/apperrors"
+	"example.com/app/pkg/middleware"
+)
+
+type CreateOrderRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type Handlers struct {
+	service ServiceInterface
+}
+
+func NewHandlers(service ServiceInterface) *Handlers {
+	return &Handlers{service: service}
+}
+
+func (h *Handlers) SetupRoutes(api *gin.RouterGroup) {
+	ordersGroup := api.Group("/orders")
+	ordersGroup.GET("", h.List)
+	ordersGroup.GET("/:id", h.Get)
+	ordersGroup.POST("", h.Create)
+	ordersGroup.GET("/:id/history", h.History)
+
+	admin := ordersGroup.Group("", middleware.AdminGuard())
+	admin.DELETE("/:id", h.Delete)
+}
+
+// List returns whatever the repository fails with, which FromDB maps to
+// no kind as the error is not one of its sentinels
+func (h *Handlers) List(c *gin.Context) {
+	orders, err := h.service.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, orders)
+}
+
+// Get maps a missing record to ErrNotFound in the service
+func (h *Handlers) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperrors.Invalid(err))
+		return
+	}
+	order, err := h.service.Get(c.Request.Context(), id)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *Handlers) Create(c *gin.Context) {
+	var req CreateOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(apperrors.Invalid(err))
+		return
+	}
+	order, err := h.service.Create(c.Request.Context(), req.Name)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, order)
+}
+
+// Delete passes store.ErrRecordNotFound to FromDB itself
+func (h *Handlers) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(apperrors.Invalid(err))
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// History documents its errors, which are then not inferred
+//
+// @Failure 410 {object} apperrors.Problem
+func (h *Handlers) History(c *gin.Context) {
+	order, err := h.service.Get(c.Request.Context(), 0)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
+}
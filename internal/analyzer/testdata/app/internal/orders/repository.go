@@ -0,0 +1,49 @@
+package orders
+
+import (
+	"context"
+
+	"example.com/app/pkg/apperrors"
+	"example.com/app/pkg/store"
+)
+
+type Repository struct {
+	orders map[int]*Order
+}
+
+func NewRepository() *Repository {
+	return &Repository{orders: map[int]*Order{}}
+}
+
+func (r *Repository) List(ctx context.Context) ([]Order, error) {
+	var orders []Order
+	for _, order := range r.orders {
+		orders = append(orders, *order)
+	}
+	return orders, apperrors.FromDB(ctx.Err())
+}
+
+func (r *Repository) Get(ctx context.Context, id int) (*Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+	return order, nil
+}
+
+func (r *Repository) Create(ctx context.Context, name string) (*Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, apperrors.FromDB(err)
+	}
+	order := &Order{ID: len(r.orders) + 1, Name: name}
+	r.orders[order.ID] = order
+	return order, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	if _, ok := r.orders[id]; !ok {
+		return apperrors.FromDB(store.ErrRecordNotFound)
+	}
+	delete(r.orders, id)
+	return nil
+}
@@ -0,0 +1,51 @@
+package orders
+
+import (
+	"context"
+	"errors"
+
+	"example.com/app/pkg/apperrors"
+	"example.com/app/pkg/store"
+)
+
+var ErrNotFound = apperrors.NotFound("Order not found")
+
+type Order struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ServiceInterface interface {
+	List(ctx context.Context) ([]Order, error)
+	Get(ctx context.Context, id int) (*Order, error)
+	Create(ctx context.Context, name string) (*Order, error)
+	Delete(ctx context.Context, id int) error
+}
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) List(ctx context.Context) ([]Order, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Order, error) {
+	order, err := s.repo.Get(ctx, id)
+	if errors.Is(err, store.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return order, err
+}
+
+func (s *Service) Create(ctx context.Context, name string) (*Order, error) {
+	return s.repo.Create(ctx, name)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,62 @@
+package apperrors
+
+import (
+	"errors"
+	"net/http"
+
+	"example.com/app/pkg/store"
+)
+
+type Kind string
+
+const (
+	KindValidation Kind = "validation"
+	KindNotFound   Kind = "not_found"
+	KindConflict   Kind = "conflict"
+)
+
+var statuses = map[Kind]int{
+	KindValidation: http.StatusBadRequest,
+	KindNotFound:   http.StatusNotFound,
+	KindConflict:   http.StatusConflict,
+}
+
+type Error struct {
+	Kind    Kind
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Status() int {
+	return statuses[e.Kind]
+}
+
+type Problem struct {
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+}
+
+func Invalid(err error) *Error {
+	return &Error{Kind: KindValidation, Message: err.Error()}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func FromDB(err error) error {
+	switch {
+	case errors.Is(err, store.ErrRecordNotFound):
+		return NotFound("Resource not found")
+	case errors.Is(err, store.ErrDuplicatedKey):
+		return Conflict("Resource already exists")
+	}
+	return err
+}
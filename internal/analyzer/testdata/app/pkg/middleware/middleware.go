@@ -0,0 +1,11 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
+
+func AdminGuard() gin.HandlerFunc {
+	return func(c *gin.Context) { c.Next() }
+}
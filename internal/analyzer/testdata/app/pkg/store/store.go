@@ -0,0 +1,9 @@
+// Package store stands in for the errors of gorm
+package store
+
+import "errors"
+
+var (
+	ErrRecordNotFound = errors.New("record not found")
+	ErrDuplicatedKey  = errors.New("duplicated key")
+)
@@ -0,0 +1,186 @@
+// Package builder compiles the server of a generated project for one or
+// more platforms, with its version information injected, and records the
+// artifacts in a manifest.
+package builder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Target is a GOOS/GOARCH pair
+type Target struct {
+	OS   string
+	Arch string
+}
+
+func (t Target) String() string {
+	return t.OS + "/" + t.Arch
+}
+
+// ParseTargets parses os/arch pairs. Each spec may hold several pairs
+// separated by commas, e.g. "linux/amd64,darwin/arm64".
+func ParseTargets(specs []string) ([]Target, error) {
+	var targets []Target
+	seen := map[Target]bool{}
+	for _, spec := range specs {
+		for _, s := range strings.Split(spec, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			goos, goarch, ok := strings.Cut(s, "/")
+			if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+				return nil, fmt.Errorf("invalid target %q, expected os/arch such as linux/amd64", s)
+			}
+			t := Target{OS: goos, Arch: goarch}
+			if !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+	return targets, nil
+}
+
+// Options configures a build
+type Options struct {
+	Dir string
+	// Package to build; defaults to ./cmd/server
+	Package string
+	// Output is the binary path; defaults to dist/server. With Targets
+	// each binary is named <Output>_<os>_<arch>.
+	Output string
+	// Targets to cross-compile for; empty builds for the host into Output
+	Targets []Target
+	// VersionPkg is the import path whose Version, Commit and Date
+	// variables are set with -ldflags -X
+	VersionPkg string
+	// Stamp overrides the version information read from git
+	Stamp    Stamp
+	Trimpath bool
+	// CGO sets CGO_ENABLED; nil leaves it to go
+	CGO     *bool
+	Tags    []string
+	LDFlags string
+	// Stdout and Stderr receive the compiler output; default to os.Stdout
+	// and os.Stderr
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// ArtifactPath returns where the binary for t is written. The zero Target
+// is the host build.
+func (o Options) ArtifactPath(t Target) string {
+	output := o.output()
+	if t == (Target{}) {
+		return output
+	}
+	path := fmt.Sprintf("%s_%s_%s", output, t.OS, t.Arch)
+	if t.OS == "windows" {
+		path += ".exe"
+	}
+	return path
+}
+
+func (o Options) output() string {
+	if o.Output == "" {
+		return filepath.Join("dist", "server")
+	}
+	return o.Output
+}
+
+// ldflags returns the linker flags, user flags first so the version
+// variables cannot be overridden by accident
+func (o Options) ldflags(stamp Stamp) string {
+	var flags []string
+	if o.LDFlags != "" {
+		flags = append(flags, o.LDFlags)
+	}
+	if o.VersionPkg != "" {
+		flags = append(flags,
+			fmt.Sprintf("-X '%s.Version=%s'", o.VersionPkg, stamp.Version),
+			fmt.Sprintf("-X '%s.Commit=%s'", o.VersionPkg, stamp.Commit),
+			fmt.Sprintf("-X '%s.Date=%s'", o.VersionPkg, stamp.Date),
+		)
+	}
+	return strings.Join(flags, " ")
+}
+
+// Build compiles the package for every target and returns the manifest of
+// the artifacts. It stops at the first target that fails to compile.
+func Build(opts Options) (*Manifest, error) {
+	if opts.Package == "" {
+		opts.Package = "./cmd/server"
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	stamp := ReadStamp(opts.Dir).Merge(opts.Stamp)
+	ldflags := opts.ldflags(stamp)
+
+	targets := opts.Targets
+	if len(targets) == 0 {
+		targets = []Target{{}}
+	}
+
+	manifest := newManifest(opts, stamp, ldflags)
+	for _, t := range targets {
+		output := opts.ArtifactPath(t)
+		if err := os.MkdirAll(filepath.Join(opts.Dir, filepath.Dir(output)), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := compile(opts, t, output, ldflags); err != nil {
+			if t == (Target{}) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", t, err)
+		}
+		if err := manifest.add(opts.Dir, output, t); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func compile(opts Options, t Target, output, ldflags string) error {
+	args := []string{"build"}
+	if opts.Trimpath {
+		args = append(args, "-trimpath")
+	}
+	if len(opts.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.Tags, ","))
+	}
+	if ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+	args = append(args, "-o", output, opts.Package)
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = opts.Dir
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	cmd.Env = os.Environ()
+	if t != (Target{}) {
+		cmd.Env = append(cmd.Env, "GOOS="+t.OS, "GOARCH="+t.Arch)
+	}
+	if opts.CGO != nil {
+		cmd.Env = append(cmd.Env, "CGO_ENABLED="+cgoValue(*opts.CGO))
+	}
+	return cmd.Run()
+}
+
+func cgoValue(enabled bool) string {
+	if enabled {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,39 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		want    []Target
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "one", specs: []string{"linux/amd64"}, want: []Target{{"linux", "amd64"}}},
+		{
+			name:  "comma-separated and repeated",
+			specs: []string{"linux/amd64, darwin/arm64", "windows/amd64"},
+			want:  []Target{{"linux", "amd64"}, {"darwin", "arm64"}, {"windows", "amd64"}},
+		},
+		{name: "duplicates dropped", specs: []string{"linux/amd64", "linux/amd64"}, want: []Target{{"linux", "amd64"}}},
+		{name: "empty items skipped", specs: []string{"linux/arm64,,"}, want: []Target{{"linux", "arm64"}}},
+		{name: "missing arch", specs: []string{"linux"}, wantErr: true},
+		{name: "empty os", specs: []string{"/amd64"}, wantErr: true},
+		{name: "too many parts", specs: []string{"linux/arm/v7"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargets(tt.specs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
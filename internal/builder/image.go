@@ -0,0 +1,252 @@
+package builder
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samuel-k-w/meba-cli/internal/oci"
+)
+
+// Scratch is the base image name for an image holding only the server,
+// CA certificates and a nonroot user
+const Scratch = "scratch"
+
+// ImageOptions configures a container image build
+type ImageOptions struct {
+	Dir string
+	// Ref is the name:tag the image is loaded as
+	Ref string
+	// Output is the path of the image tarball
+	Output string
+	// Binary is a statically linked server for Platform
+	Binary   string
+	Platform Target
+	// Base is scratch, an OCI layout or docker save archive, or the name
+	// of one cached in BaseCacheDir, e.g. gcr.io/distroless/static
+	Base string
+	// User runs the server; defaults to 65532:65532, nonroot in distroless
+	User string
+	Port int
+	// Env holds KEY=value pairs
+	Env    []string
+	Labels map[string]string
+	// Include lists project directories copied next to the binary in /app
+	Include []string
+	// HealthcheckURL is probed with the healthcheck command of the server;
+	// empty leaves the image without a healthcheck
+	HealthcheckURL      string
+	HealthcheckInterval time.Duration
+	HealthcheckTimeout  time.Duration
+	HealthcheckRetries  int
+	Stamp               Stamp
+}
+
+// Image is a container image in the manifest
+type Image struct {
+	Ref      string `json:"ref"`
+	Path     string `json:"path"`
+	Platform string `json:"platform"`
+	Base     string `json:"base"`
+	// Digest is the digest of the image manifest, as shown by docker
+	// images --digests once pushed
+	Digest string `json:"digest"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BaseCacheDir is where base images are looked up by name
+func BaseCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "meba", "images")
+}
+
+// IsStatic reports whether the binary was built without cgo, so it runs
+// on a base image without a C library
+func IsStatic(binary string) (bool, error) {
+	info, err := buildinfo.ReadFile(binary)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range info.Settings {
+		if s.Key == "CGO_ENABLED" {
+			return s.Value == "0", nil
+		}
+	}
+	return false, nil
+}
+
+// BuildImage writes an image tarball with the server on top of the base
+// image. Files are stamped with the build date so the same build gives
+// the same image digest.
+func BuildImage(opts ImageOptions) (*Image, error) {
+	if opts.User == "" {
+		opts.User = "65532:65532"
+	}
+	created, err := time.Parse(time.RFC3339, opts.Stamp.Date)
+	if err != nil {
+		created = time.Now()
+	}
+	created = created.UTC()
+	platform := oci.Platform{OS: opts.Platform.OS, Architecture: opts.Platform.Arch}
+
+	img := &oci.Image{Ref: opts.Ref, Created: created}
+	var env []string
+	if opts.Base == "" || opts.Base == Scratch {
+		img.Base = oci.Scratch(platform)
+		layer, err := scratchLayer(created)
+		if err != nil {
+			return nil, err
+		}
+		img.Layers = append(img.Layers, layer)
+		env = append(env, "PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin")
+	} else {
+		file, err := resolveBase(opts.Dir, opts.Base)
+		if err != nil {
+			return nil, err
+		}
+		if img.Base, err = oci.LoadBase(file, platform); err != nil {
+			return nil, fmt.Errorf("failed to load base image: %w", err)
+		}
+	}
+
+	layer, err := appLayer(opts, created)
+	if err != nil {
+		return nil, err
+	}
+	img.Layers = append(img.Layers, layer)
+
+	labels := map[string]string{
+		"org.opencontainers.image.version":  opts.Stamp.Version,
+		"org.opencontainers.image.revision": opts.Stamp.Commit,
+		"org.opencontainers.image.created":  created.Format(time.RFC3339),
+	}
+	for k, v := range opts.Labels {
+		labels[k] = v
+	}
+	img.Config = oci.Config{
+		User:       opts.User,
+		Env:        append(env, opts.Env...),
+		Entrypoint: []string{"/app/server"},
+		WorkingDir: "/app",
+		Labels:     labels,
+	}
+	if opts.Port > 0 {
+		img.Config.ExposedPorts = map[string]struct{}{fmt.Sprintf("%d/tcp", opts.Port): {}}
+	}
+	if opts.HealthcheckURL != "" {
+		img.Config.Healthcheck = &oci.Healthcheck{
+			Test:     []string{"CMD", "/app/server", "healthcheck", opts.HealthcheckURL},
+			Interval: opts.HealthcheckInterval,
+			Timeout:  opts.HealthcheckTimeout,
+			Retries:  opts.HealthcheckRetries,
+		}
+	}
+
+	output := filepath.Join(opts.Dir, opts.Output)
+	manifestDigest, err := img.WriteTar(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write image: %w", err)
+	}
+	sum, size, err := checksum(output)
+	if err != nil {
+		return nil, err
+	}
+
+	base := opts.Base
+	if base == "" {
+		base = Scratch
+	}
+	return &Image{
+		Ref:      opts.Ref,
+		Path:     filepath.ToSlash(opts.Output),
+		Platform: opts.Platform.String(),
+		Base:     base,
+		Digest:   manifestDigest,
+		Size:     size,
+		SHA256:   sum,
+	}, nil
+}
+
+// resolveBase finds a base image given as a path or as a name cached in
+// BaseCacheDir, as a layout directory or a .tar
+func resolveBase(dir, base string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, base)); err == nil {
+		return filepath.Join(dir, base), nil
+	}
+
+	cached := filepath.Join(BaseCacheDir(), strings.NewReplacer("/", "_", ":", "_", "@", "_").Replace(base))
+	for _, file := range []string{cached, cached + ".tar"} {
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("base image %s is not cached; save it once with\n  skopeo copy docker://%s oci:%s\nor\n  docker save %s -o %s.tar", base, base, cached, base, cached)
+}
+
+// scratchLayer holds what the server needs from an OS: users, name
+// resolution, CA certificates and /tmp
+func scratchLayer(mtime time.Time) (oci.Layer, error) {
+	files := []oci.File{
+		{Path: "etc/passwd", Mode: 0644, Data: []byte("root:x:0:0:root:/root:/sbin/nologin\nnonroot:x:65532:65532:nonroot:/home/nonroot:/sbin/nologin\n")},
+		{Path: "etc/group", Mode: 0644, Data: []byte("root:x:0:\nnonroot:x:65532:\n")},
+		{Path: "etc/nsswitch.conf", Mode: 0644, Data: []byte("hosts: files dns\n")},
+		{Path: "home/nonroot", Mode: fs.ModeDir | 0700, UID: 65532, GID: 65532},
+		{Path: "tmp", Mode: fs.ModeDir | fs.ModeSticky | 0777},
+	}
+	for _, certs := range []string{
+		"/etc/ssl/certs/ca-certificates.crt",
+		"/etc/pki/tls/certs/ca-bundle.crt",
+		"/etc/ssl/cert.pem",
+	} {
+		if data, err := os.ReadFile(certs); err == nil {
+			files = append(files, oci.File{Path: "etc/ssl/certs/ca-certificates.crt", Mode: 0644, Data: data})
+			break
+		}
+	}
+	return oci.NewLayer(files, mtime, "meba: scratch base")
+}
+
+// appLayer holds the server and the included directories, without Go
+// sources, in /app. They are owned by root so the server cannot modify
+// them.
+func appLayer(opts ImageOptions, mtime time.Time) (oci.Layer, error) {
+	binary, err := os.ReadFile(filepath.Join(opts.Dir, opts.Binary))
+	if err != nil {
+		return oci.Layer{}, err
+	}
+	files := []oci.File{{Path: "app/server", Mode: 0755, Data: binary}}
+
+	for _, include := range opts.Include {
+		root := filepath.Join(opts.Dir, include)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.Type().IsRegular() || strings.HasSuffix(path, ".go") {
+				return err
+			}
+			rel, err := filepath.Rel(opts.Dir, path)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files = append(files, oci.File{Path: "app/" + filepath.ToSlash(rel), Mode: 0644, Data: data})
+			return nil
+		})
+		if err != nil {
+			return oci.Layer{}, fmt.Errorf("failed to add %s to the image: %w", include, err)
+		}
+	}
+	return oci.NewLayer(files, mtime, "meba build --image")
+}
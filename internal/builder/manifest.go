@@ -0,0 +1,186 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"debug/buildinfo"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+)
+
+// Manifest describes the artifacts of a build
+type Manifest struct {
+	Version   string        `json:"version"`
+	Commit    string        `json:"commit"`
+	Date      string        `json:"date"`
+	GoVersion string        `json:"go_version"`
+	Build     BuildSettings `json:"build"`
+	Artifacts []Artifact    `json:"artifacts"`
+	Images    []Image       `json:"images,omitempty"`
+	SBOM      SBOM          `json:"sbom"`
+}
+
+// BuildSettings are the options the artifacts were built with
+type BuildSettings struct {
+	Package  string `json:"package"`
+	Trimpath bool   `json:"trimpath"`
+	// CGO is "1", "0" or empty when left to go
+	CGO     string   `json:"cgo_enabled,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	LDFlags string   `json:"ldflags,omitempty"`
+}
+
+// Artifact is one built binary
+type Artifact struct {
+	Path   string `json:"path"`
+	OS     string `json:"os"`
+	Arch   string `json:"arch"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SBOM lists the Go modules compiled into the artifacts, as recorded in
+// their build information
+type SBOM struct {
+	Format  string   `json:"format"`
+	Main    Module   `json:"main"`
+	Modules []Module `json:"modules"`
+}
+
+// Module is a Go module dependency
+type Module struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version,omitempty"`
+	Sum     string  `json:"sum,omitempty"`
+	Replace *Module `json:"replace,omitempty"`
+}
+
+func newManifest(opts Options, stamp Stamp, ldflags string) *Manifest {
+	m := &Manifest{
+		Version: stamp.Version,
+		Commit:  stamp.Commit,
+		Date:    stamp.Date,
+		Build: BuildSettings{
+			Package:  opts.Package,
+			Trimpath: opts.Trimpath,
+			Tags:     opts.Tags,
+			LDFlags:  ldflags,
+		},
+		Artifacts: []Artifact{},
+		SBOM:      SBOM{Format: "go-modules", Modules: []Module{}},
+	}
+	if opts.CGO != nil {
+		m.Build.CGO = cgoValue(*opts.CGO)
+	}
+	return m
+}
+
+// add records the binary at path, relative to dir, and merges its modules
+// into the SBOM
+func (m *Manifest) add(dir, path string, t Target) error {
+	file := filepath.Join(dir, path)
+	info, err := buildinfo.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read build info of %s: %w", path, err)
+	}
+	sum, size, err := checksum(file)
+	if err != nil {
+		return err
+	}
+
+	if t == (Target{}) {
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "GOOS":
+				t.OS = s.Value
+			case "GOARCH":
+				t.Arch = s.Value
+			}
+		}
+	}
+	m.Artifacts = append(m.Artifacts, Artifact{
+		Path:   filepath.ToSlash(path),
+		OS:     t.OS,
+		Arch:   t.Arch,
+		Size:   size,
+		SHA256: sum,
+	})
+
+	m.GoVersion = info.GoVersion
+	m.SBOM.Main = module(&info.Main)
+	var deps []Module
+	for _, dep := range info.Deps {
+		deps = append(deps, module(dep))
+	}
+	m.addModules(deps)
+	return nil
+}
+
+// Merge adds the artifacts, images and modules of other, e.g. a binary
+// built separately for an image
+func (m *Manifest) Merge(other *Manifest) {
+	m.Artifacts = append(m.Artifacts, other.Artifacts...)
+	m.Images = append(m.Images, other.Images...)
+	if m.SBOM.Main.Path == "" {
+		m.SBOM.Main = other.SBOM.Main
+	}
+	m.addModules(other.SBOM.Modules)
+}
+
+func (m *Manifest) addModules(modules []Module) {
+	seen := map[string]bool{}
+	for _, dep := range m.SBOM.Modules {
+		seen[dep.Path+"@"+dep.Version] = true
+	}
+	for _, dep := range modules {
+		if key := dep.Path + "@" + dep.Version; !seen[key] {
+			seen[key] = true
+			m.SBOM.Modules = append(m.SBOM.Modules, dep)
+		}
+	}
+	sort.Slice(m.SBOM.Modules, func(i, j int) bool {
+		a, b := m.SBOM.Modules[i], m.SBOM.Modules[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Version < b.Version
+	})
+}
+
+// Write saves the manifest as indented JSON
+func (m *Manifest) Write(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func module(m *debug.Module) Module {
+	mod := Module{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := module(m.Replace)
+		mod.Replace = &replace
+	}
+	return mod
+}
+
+func checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,75 @@
+package builder
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stamp is the version information injected into a build
+type Stamp struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	Date    string `json:"date"`
+}
+
+// ReadStamp reads the version information of the git checkout in dir:
+// the closest tag from git describe, the HEAD commit, and the build date
+// from SOURCE_DATE_EPOCH or else the commit time, so rebuilding a commit
+// yields the same binary. Outside git it falls back to dev, none and the
+// current time.
+func ReadStamp(dir string) Stamp {
+	stamp := Stamp{
+		Version: git(dir, "describe", "--tags", "--always", "--dirty"),
+		Commit:  git(dir, "rev-parse", "HEAD"),
+	}
+	if stamp.Version == "" {
+		stamp.Version = "dev"
+	}
+	if stamp.Commit == "" {
+		stamp.Commit = "none"
+	}
+
+	date := time.Now()
+	if epoch, ok := unixTime(os.Getenv("SOURCE_DATE_EPOCH")); ok {
+		date = epoch
+	} else if commitTime, ok := unixTime(git(dir, "log", "-1", "--format=%ct")); ok {
+		date = commitTime
+	}
+	stamp.Date = date.UTC().Format(time.RFC3339)
+	return stamp
+}
+
+// Merge returns s with the non-empty fields of override
+func (s Stamp) Merge(override Stamp) Stamp {
+	if override.Version != "" {
+		s.Version = override.Version
+	}
+	if override.Commit != "" {
+		s.Commit = override.Commit
+	}
+	if override.Date != "" {
+		s.Date = override.Date
+	}
+	return s
+}
+
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func unixTime(s string) (time.Time, bool) {
+	sec, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0), true
+}
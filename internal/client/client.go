@@ -0,0 +1,329 @@
+// Package client generates typed HTTP clients for a meba API from its
+// OpenAPI document.
+package client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/samuel-k-w/meba-cli/internal/openapi"
+)
+
+// Languages lists the supported client languages
+var Languages = []string{"go", "ts"}
+
+// Options controls client generation
+type Options struct {
+	// Lang is go or ts
+	Lang string
+	// Package is the Go package name of the generated client
+	Package string
+}
+
+// Generate renders the client for doc and returns its files keyed by name
+func Generate(doc *openapi.Document, opts Options) (map[string][]byte, error) {
+	api := newAPI(doc)
+	switch opts.Lang {
+	case "go":
+		pkg := opts.Package
+		if pkg == "" {
+			pkg = "client"
+		}
+		return generateGo(api, pkg)
+	case "ts":
+		return generateTS(api)
+	default:
+		return nil, fmt.Errorf("unsupported language %q (use %s)", opts.Lang, strings.Join(Languages, " or "))
+	}
+}
+
+// api is the language-neutral view of a document used by the generators
+type api struct {
+	title      string
+	version    string
+	schemas    map[string]*openapi.Schema
+	typeNames  map[string]string
+	operations []*operation
+}
+
+// operation is one client method
+type operation struct {
+	name         string
+	method       string
+	path         string
+	summary      string
+	pathParams   []*openapi.Parameter
+	queryParams  []*openapi.Parameter
+	body         *openapi.Schema
+	bodyRequired bool
+	result       *openapi.Schema
+}
+
+var methodOrder = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+func newAPI(doc *openapi.Document) *api {
+	a := &api{
+		title:   doc.Info.Title,
+		version: doc.Info.Version,
+		schemas: doc.Components.Schemas,
+	}
+	if a.version == "" {
+		a.version = "0.0.0"
+	}
+	a.typeNames = typeNames(a.schemas)
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	seen := map[string]bool{}
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, method := range methodOrder {
+			op, ok := item[method]
+			if !ok {
+				continue
+			}
+			name := pascal(op.OperationID)
+			if name == "" {
+				name = pascal(method + " " + path)
+			}
+			// Any() routes share one operation across all methods
+			if seen[name] {
+				name += pascal(method)
+			}
+			seen[name] = true
+
+			o := &operation{
+				name:    name,
+				method:  strings.ToUpper(method),
+				path:    path,
+				summary: op.Summary,
+			}
+			declared := map[string]*openapi.Parameter{}
+			for _, p := range op.Parameters {
+				switch p.In {
+				case "path":
+					declared[p.Name] = p
+				case "query":
+					o.queryParams = append(o.queryParams, p)
+				}
+			}
+			for _, name := range templateParams(path) {
+				p, ok := declared[name]
+				if !ok {
+					p = &openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+				}
+				o.pathParams = append(o.pathParams, p)
+			}
+			if op.RequestBody != nil {
+				if mt, ok := op.RequestBody.Content["application/json"]; ok {
+					o.body = mt.Schema
+					o.bodyRequired = op.RequestBody.Required
+				}
+			}
+			o.result = successSchema(op)
+			a.operations = append(a.operations, o)
+		}
+	}
+	return a
+}
+
+// successSchema returns the JSON body of the lowest 2xx response
+func successSchema(op *openapi.Operation) *openapi.Schema {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if mt, ok := op.Responses[code].Content["application/json"]; ok {
+			return mt.Schema
+		}
+	}
+	return nil
+}
+
+// templateParams lists the {name} segments of an OpenAPI path in order
+func templateParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params = append(params, segment[1:len(segment)-1])
+		}
+	}
+	return params
+}
+
+// typeNames picks a type name for every component schema. Names such as
+// users.CreateUsersRequest drop the package unless two packages declare
+// the same type.
+func typeNames(schemas map[string]*openapi.Schema) map[string]string {
+	short := func(name string) string {
+		return name[strings.LastIndex(name, ".")+1:]
+	}
+	count := map[string]int{}
+	for name := range schemas {
+		count[pascal(short(name))]++
+	}
+	names := map[string]string{}
+	for name := range schemas {
+		if n := pascal(short(name)); count[n] == 1 {
+			names[name] = n
+		} else {
+			names[name] = pascal(name)
+		}
+	}
+	return names
+}
+
+// refName returns the type name behind a component reference
+func (a *api) refName(ref string) string {
+	return a.typeNames[strings.TrimPrefix(ref, "#/components/schemas/")]
+}
+
+// sortedSchemas returns the component names in a stable order
+func (a *api) sortedSchemas() []string {
+	names := make([]string, 0, len(a.schemas))
+	for name := range a.schemas {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return a.typeNames[names[i]] < a.typeNames[names[j]] })
+	return names
+}
+
+// schemaType returns the JSON type of a schema and whether null is allowed.
+// Type is a string, or a list such as ["string", "null"] in 3.1 documents.
+func schemaType(s *openapi.Schema) (string, bool) {
+	var types []string
+	switch t := s.Type.(type) {
+	case string:
+		types = []string{t}
+	case []string:
+		types = t
+	case []interface{}:
+		for _, v := range t {
+			if str, ok := v.(string); ok {
+				types = append(types, str)
+			}
+		}
+	}
+	typ, nullable := "", false
+	for _, t := range types {
+		if t == "null" {
+			nullable = true
+		} else if typ == "" {
+			typ = t
+		}
+	}
+	if typ == "" && len(s.Properties) > 0 {
+		typ = "object"
+	}
+	return typ, nullable
+}
+
+// sortedProperties returns property names with required ones first
+func sortedProperties(s *openapi.Schema) []string {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func isRequired(s *openapi.Schema, name string) bool {
+	for _, r := range s.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryLine returns the first line of the summary for doc comments
+func (o *operation) summaryLine() string {
+	return strings.TrimSpace(strings.SplitN(o.summary, "\n", 2)[0])
+}
+
+// Go initialisms kept upper case in identifiers
+var initialisms = map[string]bool{
+	"api": true, "id": true, "ids": true, "url": true, "uri": true, "http": true,
+	"json": true, "uuid": true, "ip": true, "sql": true, "html": true,
+}
+
+// words splits an identifier such as page_size, usersGetAll or
+// users.CreateUsersRequest into its words
+func words(s string) []string {
+	var out []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			out = append(out, string(current))
+			current = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsUpper(runes[i-1]) && unicode.IsLower(runes[i+1]))):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return out
+}
+
+// pascal converts s to an exported Go identifier, e.g. page_size to PageSize
+func pascal(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		lower := strings.ToLower(w)
+		if initialisms[lower] {
+			b.WriteString(strings.ToUpper(w))
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	out := b.String()
+	if out != "" && unicode.IsDigit(rune(out[0])) {
+		out = "N" + out
+	}
+	return out
+}
+
+// camel converts s to a lower camel case identifier, e.g. user_id to userID
+func camel(s string) string {
+	ws := words(s)
+	if len(ws) == 0 {
+		return ""
+	}
+	first := strings.ToLower(ws[0])
+	rest := pascal(strings.Join(ws[1:], "_"))
+	out := first + rest
+	if unicode.IsDigit(rune(out[0])) {
+		out = "n" + out
+	}
+	return out
+}
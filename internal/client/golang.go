@@ -0,0 +1,351 @@
+package client
+
+import (
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/openapi"
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+const generatedHeader = "// Code generated by meba client generate. DO NOT EDIT.\n\n"
+
+// goGen renders Go types, naming inline objects after where they are used
+type goGen struct {
+	api      *api
+	declared map[string]bool
+	decls    strings.Builder
+}
+
+func generateGo(a *api, pkg string) (map[string][]byte, error) {
+	g := &goGen{api: a, declared: map[string]bool{}}
+	for _, name := range a.typeNames {
+		g.declared[name] = true
+	}
+
+	for _, name := range a.sortedSchemas() {
+		g.structType(a.typeNames[name], a.schemas[name])
+	}
+	var ops strings.Builder
+	for _, op := range a.operations {
+		g.operation(&ops, op)
+	}
+
+	models := generatedHeader + "package " + pkg + "\n\n" + goImports(g.decls.String()) + g.decls.String()
+	operations := generatedHeader + "package " + pkg + "\n\n" +
+		goImports(ops.String(), "context", "net/url") + ops.String()
+
+	files := map[string]string{
+		"client.go":     templates.ClientGo(pkg, a.title, a.version),
+		"models.go":     models,
+		"operations.go": operations,
+	}
+	out := map[string][]byte{}
+	for name, src := range files {
+		formatted, err := format.Source([]byte(src))
+		if err != nil {
+			return nil, fmt.Errorf("generated %s is invalid: %w", name, err)
+		}
+		out[name] = formatted
+	}
+	return out, nil
+}
+
+// goImports returns the import block for the packages src refers to
+func goImports(src string, always ...string) string {
+	imports := append([]string{}, always...)
+	for pkg, use := range map[string]string{
+		"encoding/json": "json.",
+		"fmt":           "fmt.",
+		"net/http":      "http.",
+		"time":          "time.",
+	} {
+		if strings.Contains(src, use) {
+			imports = append(imports, pkg)
+		}
+	}
+	if len(imports) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("import (\n")
+	for _, pkg := range imports {
+		fmt.Fprintf(&b, "\t%q\n", pkg)
+	}
+	b.WriteString(")\n\n")
+	return b.String()
+}
+
+// uniqueName returns name, or name with a numeric suffix if it is taken
+func (g *goGen) uniqueName(name string) string {
+	candidate := name
+	for i := 2; g.declared[candidate]; i++ {
+		candidate = name + strconv.Itoa(i)
+	}
+	g.declared[candidate] = true
+	return candidate
+}
+
+// structType declares a named struct for an object schema
+func (g *goGen) structType(name string, s *openapi.Schema) {
+	var b strings.Builder
+	if s.Description != "" {
+		for _, line := range strings.Split(strings.TrimSpace(s.Description), "\n") {
+			fmt.Fprintf(&b, "// %s\n", line)
+		}
+	} else {
+		fmt.Fprintf(&b, "// %s is a generated model\n", name)
+	}
+	typ, _ := schemaType(s)
+	if typ == "object" && len(s.Properties) == 0 && s.AdditionalProperties == nil {
+		// empty DTO structs have no properties
+		fmt.Fprintf(&b, "type %s struct{}\n\n", name)
+		g.decls.WriteString(b.String())
+		return
+	}
+	if typ != "object" || len(s.Properties) == 0 {
+		fmt.Fprintf(&b, "type %s %s\n\n", name, g.typeOf(s, name, false))
+		g.decls.WriteString(b.String())
+		return
+	}
+
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	for _, prop := range sortedProperties(s) {
+		field := pascal(prop)
+		if field == "" {
+			field = "Field"
+		}
+		required := isRequired(s, prop)
+		tag := prop
+		if !required {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&b, "\t%s %s `json:%q`\n", field, g.typeOf(s.Properties[prop], name+field, !required), tag)
+	}
+	b.WriteString("}\n\n")
+	g.decls.WriteString(b.String())
+}
+
+// typeOf returns the Go type for a schema. hint names inline objects;
+// optional makes struct values pointers so they can be omitted.
+func (g *goGen) typeOf(s *openapi.Schema, hint string, optional bool) string {
+	if s == nil {
+		return "json.RawMessage"
+	}
+	if s.Ref != "" {
+		return pointerIf(g.api.refName(s.Ref), optional)
+	}
+	if len(s.OneOf) == 1 {
+		return g.typeOf(s.OneOf[0], hint, optional)
+	}
+	if len(s.OneOf) > 1 {
+		return "json.RawMessage"
+	}
+
+	typ, nullable := schemaType(s)
+	var t string
+	switch typ {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			t = "time.Time"
+		case "byte", "binary":
+			t = "[]byte"
+		default:
+			t = "string"
+		}
+	case "integer":
+		if s.Format == "int32" {
+			t = "int32"
+		} else {
+			t = "int64"
+		}
+	case "number":
+		if s.Format == "float" {
+			t = "float32"
+		} else {
+			t = "float64"
+		}
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + g.typeOf(s.Items, hint+"Item", false)
+	case "object":
+		if len(s.Properties) > 0 {
+			name := g.uniqueName(hint)
+			g.structType(name, s)
+			return pointerIf(name, optional || nullable)
+		}
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.typeOf(s.AdditionalProperties, hint+"Value", false)
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+	return pointerIf(t, nullable || (optional && t == "time.Time"))
+}
+
+func pointerIf(t string, pointer bool) string {
+	if pointer && !collection(t) {
+		return "*" + t
+	}
+	return t
+}
+
+// collection reports whether t needs no pointer to be returned or omitted
+func collection(t string) bool {
+	return strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[") ||
+		t == "interface{}" || t == "json.RawMessage"
+}
+
+// goParam turns a parameter name into a Go identifier that is safe to use
+// as a function argument
+func goParam(name string) string {
+	id := camel(name)
+	switch {
+	case id == "":
+		return "param"
+	case token.IsKeyword(id), id == "ctx", id == "body", id == "params", id == "path", id == "query", id == "out":
+		return id + "Param"
+	}
+	return id
+}
+
+// operation writes the params type and method for one operation
+func (g *goGen) operation(b *strings.Builder, op *operation) {
+	var args []string
+	args = append(args, "ctx context.Context")
+	for _, p := range op.pathParams {
+		args = append(args, goParam(p.Name)+" "+g.typeOf(p.Schema, op.name+pascal(p.Name), false))
+	}
+
+	paramsType := ""
+	if len(op.queryParams) > 0 {
+		paramsType = g.uniqueName(op.name + "Params")
+		fmt.Fprintf(&g.decls, "// %s holds the query parameters of %s\n", paramsType, op.name)
+		fmt.Fprintf(&g.decls, "type %s struct {\n", paramsType)
+		for _, p := range op.queryParams {
+			t := g.typeOf(p.Schema, paramsType+pascal(p.Name), false)
+			if !p.Required {
+				t = pointerIf(t, true)
+			}
+			fmt.Fprintf(&g.decls, "\t%s %s\n", pascal(p.Name), t)
+		}
+		g.decls.WriteString("}\n\n")
+	}
+
+	bodyArg := "nil"
+	if op.body != nil {
+		t := g.typeOf(op.body, op.name+"Request", false)
+		if !collection(t) && !strings.HasPrefix(t, "*") {
+			t = "*" + t
+		}
+		args = append(args, "body "+t)
+		bodyArg = "body"
+	}
+	if paramsType != "" {
+		args = append(args, "params *"+paramsType)
+	}
+
+	result := ""
+	if op.result != nil {
+		result = g.typeOf(op.result, op.name+"Response", false)
+		result = strings.TrimPrefix(result, "*")
+	}
+
+	fmt.Fprintf(b, "// %s calls %s %s\n", op.name, op.method, op.path)
+	if summary := op.summaryLine(); summary != "" {
+		fmt.Fprintf(b, "//\n// %s\n", summary)
+	}
+	returns := "error"
+	if result != "" {
+		if collection(result) {
+			returns = "(" + result + ", error)"
+		} else {
+			returns = "(*" + result + ", error)"
+		}
+	}
+	fmt.Fprintf(b, "func (c *Client) %s(%s) %s {\n", op.name, strings.Join(args, ", "), returns)
+
+	fmt.Fprintf(b, "\tpath := %s\n", goPathExpr(op))
+	b.WriteString("\tquery := url.Values{}\n")
+	if paramsType != "" {
+		b.WriteString("\tif params != nil {\n")
+		for _, p := range op.queryParams {
+			field := "params." + pascal(p.Name)
+			typ, _ := schemaType(p.Schema)
+			switch {
+			case typ == "array":
+				fmt.Fprintf(b, "\t\tfor _, v := range %s {\n\t\t\tquery.Add(%q, fmt.Sprint(v))\n\t\t}\n", field, p.Name)
+			case p.Required:
+				fmt.Fprintf(b, "\t\tquery.Set(%q, %s)\n", p.Name, goFormat(field, p.Schema))
+			default:
+				fmt.Fprintf(b, "\t\tif %s != nil {\n\t\t\tquery.Set(%q, %s)\n\t\t}\n", field, p.Name, goFormat("*"+field, p.Schema))
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+
+	method := "http.Method" + strings.ToUpper(op.method[:1]) + strings.ToLower(op.method[1:])
+	switch {
+	case result == "":
+		fmt.Fprintf(b, "\treturn c.do(ctx, %s, path, query, %s, nil)\n", method, bodyArg)
+	case collection(result):
+		fmt.Fprintf(b, "\tvar out %s\n", result)
+		fmt.Fprintf(b, "\terr := c.do(ctx, %s, path, query, %s, &out)\n", method, bodyArg)
+		b.WriteString("\treturn out, err\n")
+	default:
+		fmt.Fprintf(b, "\tvar out %s\n", result)
+		fmt.Fprintf(b, "\tif err := c.do(ctx, %s, path, query, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", method, bodyArg)
+		b.WriteString("\treturn &out, nil\n")
+	}
+	b.WriteString("}\n\n")
+}
+
+// goPathExpr builds the request path, escaping path parameters
+func goPathExpr(op *operation) string {
+	var parts []string
+	literal := ""
+	for _, segment := range strings.SplitAfter(op.path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			name := strings.TrimSuffix(strings.TrimSuffix(segment, "/"), "}")[1:]
+			parts = append(parts, strconv.Quote(literal))
+			literal = ""
+			var param *openapi.Parameter
+			for _, p := range op.pathParams {
+				if p.Name == name {
+					param = p
+				}
+			}
+			parts = append(parts, "url.PathEscape("+goFormat(goParam(name), param.Schema)+")")
+			if strings.HasSuffix(segment, "/") {
+				literal = "/"
+			}
+			continue
+		}
+		literal += segment
+	}
+	if literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+	return strings.Join(parts, " + ")
+}
+
+// goFormat renders a parameter value as a string expression
+func goFormat(expr string, s *openapi.Schema) string {
+	typ, _ := schemaType(s)
+	switch {
+	case typ == "string" && s.Format == "date-time":
+		if strings.HasPrefix(expr, "*") {
+			expr = "(" + expr + ")"
+		}
+		return expr + ".Format(time.RFC3339)"
+	case typ == "string" && s.Format != "byte" && s.Format != "binary":
+		return expr
+	}
+	return "fmt.Sprint(" + expr + ")"
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/openapi"
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsGen renders TypeScript types. Named interfaces are emitted for
+// components and for inline request and response bodies.
+type tsGen struct {
+	api      *api
+	declared map[string]bool
+	decls    strings.Builder
+	// used collects the models client.ts refers to while collecting is set
+	used       map[string]bool
+	collecting bool
+}
+
+func generateTS(a *api) (map[string][]byte, error) {
+	g := &tsGen{api: a, declared: map[string]bool{}, used: map[string]bool{}}
+	for _, name := range a.typeNames {
+		g.declared[name] = true
+	}
+
+	for _, name := range a.sortedSchemas() {
+		g.declare(a.typeNames[name], a.schemas[name])
+	}
+	var methods strings.Builder
+	g.collecting = true
+	for _, op := range a.operations {
+		g.operation(&methods, op)
+	}
+
+	var used []string
+	for name := range g.used {
+		used = append(used, name)
+	}
+	sort.Strings(used)
+	imports := ""
+	if len(used) > 0 {
+		imports = "\nimport type { " + strings.Join(used, ", ") + " } from \"./models\";\n"
+	}
+
+	models := "// Code generated by meba client generate. DO NOT EDIT.\n\n" + g.decls.String()
+	if g.decls.Len() == 0 {
+		models += "export {};\n"
+	}
+	return map[string][]byte{
+		"models.ts": []byte(models),
+		"client.ts": []byte(templates.ClientTS(a.title, a.version, imports, methods.String())),
+		"index.ts": []byte("// Code generated by meba client generate. DO NOT EDIT.\n\n" +
+			"export * from \"./models\";\nexport * from \"./client\";\n"),
+	}, nil
+}
+
+// declare emits an interface, or a type alias for non-object schemas
+func (g *tsGen) declare(name string, s *openapi.Schema) {
+	if s.Description != "" {
+		fmt.Fprintf(&g.decls, "/** %s */\n", strings.ReplaceAll(strings.TrimSpace(s.Description), "\n", " "))
+	}
+	typ, nullable := schemaType(s)
+	if typ == "object" && len(s.Properties) > 0 && !nullable {
+		fmt.Fprintf(&g.decls, "export interface %s %s\n\n", name, g.object(s, "\n", "  "))
+		return
+	}
+	fmt.Fprintf(&g.decls, "export type %s = %s;\n\n", name, g.typeOf(s, ""))
+}
+
+// object renders the members of an object schema
+func (g *tsGen) object(s *openapi.Schema, sep, indent string) string {
+	var b strings.Builder
+	b.WriteString("{" + sep)
+	for _, prop := range sortedProperties(s) {
+		key := prop
+		if !tsIdentifier.MatchString(key) {
+			key = strconv.Quote(key)
+		}
+		optional := ""
+		if !isRequired(s, prop) {
+			optional = "?"
+		}
+		fmt.Fprintf(&b, "%s%s%s: %s;%s", indent, key, optional, g.typeOf(s.Properties[prop], indent+"  "), sep)
+	}
+	if sep == "\n" {
+		b.WriteString(indent[2:])
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// typeOf returns the TypeScript type of a schema; members of nested
+// objects are rendered inline at the given indent
+func (g *tsGen) typeOf(s *openapi.Schema, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref != "" {
+		name := g.api.refName(s.Ref)
+		if g.collecting {
+			g.used[name] = true
+		}
+		return name
+	}
+	if len(s.OneOf) > 0 {
+		var members []string
+		for _, m := range s.OneOf {
+			members = append(members, g.typeOf(m, indent))
+		}
+		return strings.Join(members, " | ")
+	}
+
+	typ, nullable := schemaType(s)
+	var t string
+	switch typ {
+	case "string":
+		t = "string"
+		if len(s.Enum) > 0 {
+			var values []string
+			for _, v := range s.Enum {
+				values = append(values, strconv.Quote(fmt.Sprint(v)))
+			}
+			t = strings.Join(values, " | ")
+		}
+	case "integer", "number":
+		t = "number"
+	case "boolean":
+		t = "boolean"
+	case "array":
+		elem := g.typeOf(s.Items, indent)
+		if strings.Contains(elem, " ") && !strings.HasPrefix(elem, "{") {
+			elem = "(" + elem + ")"
+		}
+		t = elem + "[]"
+	case "object":
+		switch {
+		case len(s.Properties) > 0:
+			if indent == "" {
+				indent = "  "
+			}
+			t = g.object(s, "\n", indent)
+		case s.AdditionalProperties != nil:
+			t = "Record<string, " + g.typeOf(s.AdditionalProperties, indent) + ">"
+		default:
+			t = "Record<string, unknown>"
+		}
+	default:
+		t = "unknown"
+	}
+	if nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// named returns a type name for a body schema, declaring an interface for
+// inline objects
+func (g *tsGen) named(s *openapi.Schema, name string) string {
+	typ, _ := schemaType(s)
+	if s.Ref == "" && typ == "object" && len(s.Properties) > 0 {
+		candidate := name
+		for i := 2; g.declared[candidate]; i++ {
+			candidate = name + strconv.Itoa(i)
+		}
+		g.declared[candidate] = true
+		g.collecting = false
+		g.declare(candidate, s)
+		g.collecting = true
+		g.used[candidate] = true
+		return candidate
+	}
+	return g.typeOf(s, "    ")
+}
+
+// operation writes the method for one operation
+func (g *tsGen) operation(b *strings.Builder, op *operation) {
+	var args []string
+	for _, p := range op.pathParams {
+		args = append(args, tsParam(p.Name)+": "+g.typeOf(p.Schema, "    "))
+	}
+	if op.body != nil {
+		optional := ""
+		if !op.bodyRequired {
+			optional = "?"
+		}
+		args = append(args, "body"+optional+": "+g.named(op.body, op.name+"Request"))
+	}
+	query := ""
+	if len(op.queryParams) > 0 {
+		var fields []string
+		required := false
+		for _, p := range op.queryParams {
+			key := p.Name
+			if !tsIdentifier.MatchString(key) {
+				key = strconv.Quote(key)
+			}
+			optional := "?"
+			if p.Required {
+				optional = ""
+				required = true
+			}
+			fields = append(fields, key+optional+": "+g.typeOf(p.Schema, "    "))
+		}
+		arg := "params: { " + strings.Join(fields, "; ") + " }"
+		if !required {
+			arg += " = {}"
+		}
+		args = append(args, arg)
+		query = "query: params, "
+	}
+	args = append(args, "init?: RequestInit")
+
+	result := "void"
+	if op.result != nil {
+		result = g.named(op.result, op.name+"Response")
+	}
+
+	body := ""
+	if op.body != nil {
+		body = "body, "
+	}
+
+	b.WriteString("\n  /**\n")
+	if summary := op.summaryLine(); summary != "" {
+		fmt.Fprintf(b, "   * %s\n   *\n", summary)
+	}
+	fmt.Fprintf(b, "   * %s %s\n   */\n", op.method, op.path)
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", camel(op.name), strings.Join(args, ", "), result)
+	fmt.Fprintf(b, "    return this.request<%s>(%q, %s, { %s%sinit });\n", result, op.method, tsPathExpr(op.path), query, body)
+	b.WriteString("  }\n")
+}
+
+// tsPathExpr builds a template literal that encodes path parameters
+func tsPathExpr(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			segments[i] = "${encodeURIComponent(String(" + tsParam(s[1:len(s)-1]) + "))}"
+		}
+	}
+	return "`" + strings.Join(segments, "/") + "`"
+}
+
+// tsParam turns a parameter name into a safe argument name
+func tsParam(name string) string {
+	id := camel(name)
+	switch id {
+	case "", "body", "params", "init", "delete", "new", "default", "function", "class", "in":
+		return id + "Param"
+	}
+	return id
+}
@@ -0,0 +1,118 @@
+package deploy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmValues renders values as the values.yaml of the chart generated by
+// meba add deploy helm. Secrets are left empty for --set-string.
+func HelmValues(values *Values) ([]byte, error) {
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(values.ConfigYAML), &config); err != nil {
+		return nil, err
+	}
+	repository, tag := values.Image, ""
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	secrets := map[string]string{}
+	for _, s := range values.Secrets {
+		secrets[s.Env] = ""
+	}
+
+	data, err := yaml.Marshal(map[string]any{
+		"replicaCount": values.Replicas,
+		"image": map[string]any{
+			"repository": repository,
+			"tag":        tag,
+			"pullPolicy": "IfNotPresent",
+		},
+		"service": map[string]any{
+			"type":       "ClusterIP",
+			"port":       80,
+			"targetPort": values.Port,
+		},
+		"probes":                        map[string]any{"path": values.HealthPath},
+		"terminationGracePeriodSeconds": values.StopGracePeriod,
+		"metrics": map[string]any{
+			"enabled": values.Metrics.Enabled,
+			"path":    values.Metrics.Path,
+		},
+		"autoscaling": map[string]any{
+			"enabled":                        values.Autoscaling.Enabled,
+			"minReplicas":                    values.Autoscaling.MinReplicas,
+			"maxReplicas":                    values.Autoscaling.MaxReplicas,
+			"targetCPUUtilizationPercentage": values.Autoscaling.TargetCPU,
+		},
+		"resources": map[string]any{
+			"requests": map[string]any{"cpu": values.Resources.Requests.CPU, "memory": values.Resources.Requests.Memory},
+			"limits":   map[string]any{"cpu": values.Resources.Limits.CPU, "memory": values.Resources.Limits.Memory},
+		},
+		"config":  config,
+		"secrets": secrets,
+	})
+	if err != nil {
+		return nil, err
+	}
+	header := "# Derived from configs/config.yaml by meba. meba deploy render --target helm\n" +
+		"# overrides these values with the config of the chosen environment.\n"
+	return append([]byte(header), data...), nil
+}
+
+// ErrHelmNotInstalled is returned when rendering the helm target without
+// the helm binary
+var ErrHelmNotInstalled = errors.New("helm is not installed; the chart is in deployments/helm")
+
+// renderHelm runs helm template on the chart with the values of the
+// environment and the secrets set in the environment
+func renderHelm(dir string, values *Values) (*Result, error) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		return nil, ErrHelmNotInstalled
+	}
+
+	envValues, err := HelmValues(values)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp("", "meba-values-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(envValues); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	tmp.Close()
+
+	result := &Result{}
+	args := []string{"template", values.Name, filepath.Join("deployments", "helm"),
+		"--namespace", values.Namespace, "--values", tmp.Name()}
+	for _, s := range values.Secrets {
+		value := os.Getenv(s.Env)
+		if value == "" {
+			result.MissingSecrets = append(result.MissingSecrets, s.Env)
+			value = SecretPlaceholder
+		}
+		args = append(args, "--set-string", "secrets."+s.Env+"="+value)
+	}
+
+	cmd := exec.Command("helm", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("helm template failed: %v\n%s", err, stderr.String())
+	}
+	result.Files = []File{{Path: "deployments/helm", Content: string(out)}}
+	return result, nil
+}
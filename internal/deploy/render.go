@@ -0,0 +1,107 @@
+package deploy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// SecretPlaceholder is rendered for secrets missing from the environment
+const SecretPlaceholder = "CHANGE_ME"
+
+// File is a rendered manifest
+type File struct {
+	// Path is the template it was rendered from, relative to the project
+	Path    string
+	Content string
+}
+
+// Result is the output of Render
+type Result struct {
+	Files []File
+	// MissingSecrets lists the secret variables that were not set and got
+	// SecretPlaceholder
+	MissingSecrets []string
+}
+
+// Installed returns the targets with a directory in deployments/
+func Installed(dir string) []string {
+	var targets []string
+	for _, t := range Targets {
+		if info, err := os.Stat(filepath.Join(dir, "deployments", t)); err == nil && info.IsDir() {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// Render renders the templates of target for the environment of values.
+// Secrets are read from the environment variables named in Secrets.
+// Helm charts are rendered with helm template.
+func Render(dir, target string, values *Values) (*Result, error) {
+	root := filepath.Join(dir, "deployments", target)
+	if _, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("deployments/%s does not exist, generate it with meba add deploy %s", target, target)
+	}
+	if target == "helm" {
+		return renderHelm(dir, values)
+	}
+
+	result := &Result{}
+	missing := map[string]bool{}
+	funcs := template.FuncMap{
+		"quote": strconv.Quote,
+		"indent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return pad + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+pad)
+		},
+		"secret": func(env string) string {
+			if value := os.Getenv(env); value != "" {
+				return value
+			}
+			missing[env] = true
+			return SecretPlaceholder
+		},
+	}
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tmpl, err := template.New(rel).Funcs(funcs).Option("missingkey=error").Parse(string(src))
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := tmpl.Execute(&out, values); err != nil {
+			return err
+		}
+		// Templates whose resource is disabled render to nothing
+		if content := strings.TrimSpace(out.String()); content != "" {
+			result.Files = append(result.Files, File{Path: filepath.ToSlash(rel), Content: content + "\n"})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for env := range missing {
+		result.MissingSecrets = append(result.MissingSecrets, env)
+	}
+	sort.Strings(result.MissingSecrets)
+	return result, nil
+}
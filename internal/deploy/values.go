@@ -0,0 +1,216 @@
+// Package deploy renders the deployment manifests in deployments/ for an
+// environment, from configs/config.yaml with deployments/env/<env>.yaml
+// merged over it.
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Targets are the kinds of deployment meba add deploy generates
+var Targets = []string{"k8s", "helm", "compose", "systemd"}
+
+// Secret is a config value kept out of the rendered config and passed
+// through the environment instead
+type Secret struct {
+	// Key in config.yaml, e.g. jwt.secret
+	Key string
+	// Env is the variable the app reads it from, e.g. JWT_SECRET
+	Env string
+}
+
+// Secrets are the config values that never appear in rendered manifests
+var Secrets = []Secret{
+	{Key: "jwt.secret", Env: "JWT_SECRET"},
+	{Key: "database.password", Env: "DATABASE_PASSWORD"},
+}
+
+// cliSections are config.yaml sections read by meba rather than the app
+var cliSections = []string{"build", "watch", "image", "deploy"}
+
+// Values are the parameters of the deployment templates
+type Values struct {
+	Env       string
+	Name      string
+	Version   string
+	Namespace string
+	Image     string
+	Replicas  int
+	Port      int
+	// HealthPath is monitoring.health_check.path, used by the probes
+	HealthPath string
+	// StopGracePeriod is how many seconds the app gets to exit after
+	// SIGTERM: server.shutdown_timeout plus time for the shutdown hooks
+	StopGracePeriod int
+	Metrics         Metrics
+	Autoscaling     Autoscaling
+	Resources       Resources
+	Database        Database
+	Secrets         []Secret
+	// ConfigYAML is the merged config.yaml without secrets and meba
+	// sections, mounted into the app
+	ConfigYAML string
+	// ConfigChecksum changes with ConfigYAML to roll the pods
+	ConfigChecksum string
+	// Config holds every merged setting for custom templates
+	Config map[string]any
+}
+
+// Metrics is monitoring.prometheus
+type Metrics struct {
+	Enabled bool
+	Path    string
+}
+
+// Autoscaling configures the HorizontalPodAutoscaler
+type Autoscaling struct {
+	Enabled     bool
+	MinReplicas int
+	MaxReplicas int
+	TargetCPU   int
+}
+
+// Resources are the container requests and limits
+type Resources struct {
+	Requests Quantities
+	Limits   Quantities
+}
+
+// Quantities are CPU and memory amounts, e.g. 100m and 128Mi
+type Quantities struct {
+	CPU    string
+	Memory string
+}
+
+// Database is the non-secret part of the database config
+type Database struct {
+	Host   string
+	Port   int
+	User   string
+	DBName string
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// EnvFile returns the overrides file of env
+func EnvFile(dir, env string) string {
+	return filepath.Join(dir, "deployments", "env", env+".yaml")
+}
+
+// Load reads configs/config.yaml, merges deployments/env/<env>.yaml over
+// it when env is set, and derives the template values
+func Load(dir, env string) (*Values, error) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(dir, "configs", "config.yaml"))
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read configs/config.yaml: %w", err)
+	}
+	if env != "" {
+		file := EnvFile(dir, env)
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("unknown environment %q: %s does not exist", env, filepath.Join("deployments", "env", env+".yaml"))
+		}
+		v.SetConfigFile(file)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+
+	setDefaults(v)
+	values := &Values{
+		Env:             env,
+		Name:            dnsName(v.GetString("app.name")),
+		Version:         v.GetString("app.version"),
+		Namespace:       v.GetString("deploy.namespace"),
+		Image:           v.GetString("deploy.image"),
+		Replicas:        v.GetInt("deploy.replicas"),
+		Port:            v.GetInt("app.port"),
+		HealthPath:      v.GetString("monitoring.health_check.path"),
+		StopGracePeriod: int(math.Ceil(v.GetDuration("server.shutdown_timeout").Seconds())) + 5,
+		Metrics: Metrics{
+			Enabled: v.GetBool("monitoring.prometheus.enabled"),
+			Path:    v.GetString("monitoring.prometheus.path"),
+		},
+		Autoscaling: Autoscaling{
+			Enabled:     v.GetBool("deploy.autoscaling.enabled"),
+			MinReplicas: v.GetInt("deploy.autoscaling.min_replicas"),
+			MaxReplicas: v.GetInt("deploy.autoscaling.max_replicas"),
+			TargetCPU:   v.GetInt("deploy.autoscaling.target_cpu"),
+		},
+		Resources: Resources{
+			Requests: Quantities{CPU: v.GetString("deploy.resources.requests.cpu"), Memory: v.GetString("deploy.resources.requests.memory")},
+			Limits:   Quantities{CPU: v.GetString("deploy.resources.limits.cpu"), Memory: v.GetString("deploy.resources.limits.memory")},
+		},
+		Database: Database{
+			Host:   v.GetString("database.host"),
+			Port:   v.GetInt("database.port"),
+			User:   v.GetString("database.user"),
+			DBName: v.GetString("database.dbname"),
+		},
+		Secrets: Secrets,
+		Config:  v.AllSettings(),
+	}
+	if values.Image == "" {
+		values.Image = values.Name + ":" + values.Version
+	}
+
+	appConfig := v.AllSettings()
+	for _, section := range cliSections {
+		delete(appConfig, section)
+	}
+	for _, s := range Secrets {
+		section, key, _ := strings.Cut(s.Key, ".")
+		if m, ok := appConfig[section].(map[string]any); ok {
+			// Keep the key so viper picks the value up from the environment
+			m[key] = ""
+		}
+	}
+	data, err := yaml.Marshal(appConfig)
+	if err != nil {
+		return nil, err
+	}
+	values.ConfigYAML = string(data)
+	sum := sha256.Sum256(data)
+	values.ConfigChecksum = hex.EncodeToString(sum[:])
+	return values, nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("app.name", "meba-app")
+	v.SetDefault("app.version", "1.0.0")
+	v.SetDefault("app.port", 8080)
+	v.SetDefault("server.shutdown_timeout", "30s")
+	v.SetDefault("monitoring.health_check.path", "/api/v1/health")
+	v.SetDefault("monitoring.prometheus.path", "/metrics")
+	v.SetDefault("deploy.namespace", "default")
+	v.SetDefault("deploy.replicas", 2)
+	v.SetDefault("deploy.autoscaling.min_replicas", 2)
+	v.SetDefault("deploy.autoscaling.max_replicas", 10)
+	v.SetDefault("deploy.autoscaling.target_cpu", 70)
+	v.SetDefault("deploy.resources.requests.cpu", "100m")
+	v.SetDefault("deploy.resources.requests.memory", "128Mi")
+	v.SetDefault("deploy.resources.limits.cpu", "500m")
+	v.SetDefault("deploy.resources.limits.memory", "256Mi")
+}
+
+// dnsName turns the app name into a valid Kubernetes resource name
+func dnsName(name string) string {
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(name) > 63 {
+		name = strings.TrimRight(name[:63], "-")
+	}
+	if name == "" {
+		return "app"
+	}
+	return name
+}
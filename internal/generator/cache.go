@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+// redisModule is the Redis client used by the redis driver of pkg/cache,
+// pinned to a version that builds with the go directive of meba new
+const redisModule = "github.com/redis/go-redis/v9@v9.5.1"
+
+// cacheDriverLine matches the driver of the cache section of config.yaml,
+// keeping its indentation and comment
+var cacheDriverLine = regexp.MustCompile(`(?m)^([ \t]+driver:[ \t]*)("[^"]*"|[^\s#]+)`)
+
+// GenerateCache adds pkg/cache with the memory driver, and the redis
+// driver when driver is redis, selects driver in the config and wires the
+// cache into the app. Run again with another driver to add it, or to
+// finish wiring a project it left half done. Every edit is made before
+// any file is written.
+func GenerateCache(driver string, dryRun, skipInstall bool) error {
+	valid := false
+	for _, d := range templates.CacheDrivers {
+		valid = valid || d == driver
+	}
+	if !valid {
+		return fmt.Errorf("unknown driver %q, expected one of %s", driver, strings.Join(templates.CacheDrivers, ", "))
+	}
+
+	moduleName := getCurrentModuleName()
+	files := map[string]string{
+		filepath.Join("pkg", "cache", "cache.go"):      templates.CacheGo(moduleName),
+		filepath.Join("pkg", "cache", "memory.go"):     templates.CacheMemoryGo(moduleName),
+		filepath.Join("pkg", "cache", "middleware.go"): templates.CacheMiddlewareGo(),
+		filepath.Join("internal", "cache.go"):          templates.AppCacheGo(moduleName),
+	}
+	if driver == templates.CacheDriverRedis {
+		files[filepath.Join("pkg", "cache", "redis.go")] = templates.CacheRedisGo(moduleName)
+	}
+	for path := range files {
+		if fileExists(path) {
+			delete(files, path)
+		}
+	}
+
+	configGoPath := filepath.Join("configs", "config.go")
+	configPath := filepath.Join("configs", "config.yaml")
+	appPath := filepath.Join("internal", "app.go")
+	wirePath := filepath.Join("internal", "wire.go")
+	original := map[string]string{}
+	for _, path := range []string{configGoPath, configPath, appPath, wirePath} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%s not found; it is created by meba new", path)
+		}
+		original[path] = string(content)
+	}
+
+	if err := checkInjectorConfig(original[wirePath], wirePath); err != nil {
+		return err
+	}
+	edited := map[string]string{
+		configPath: setCacheDriver(original[configPath], driver),
+	}
+	var err error
+	if edited[configGoPath], err = addCacheDriverConfig(original[configGoPath], configGoPath); err != nil {
+		return err
+	}
+	if edited[appPath], err = addCacheApp(original[appPath], appPath, moduleName); err != nil {
+		return err
+	}
+	wire := original[wirePath]
+	if wire, err = addWireBuildSet(wire, wirePath, "InitializeApp", "CacheSet"); err != nil {
+		return err
+	}
+	if edited[wirePath], err = addWireBuildSet(wire, wirePath, "InitializeTestApp", "MemoryCacheSet"); err != nil {
+		return err
+	}
+	for path, content := range edited {
+		if content == original[path] {
+			delete(edited, path)
+		}
+	}
+	if len(files) == 0 && len(edited) == 0 {
+		return fmt.Errorf("pkg/cache already has the %s driver", driver)
+	}
+
+	if dryRun {
+		for path := range files {
+			fmt.Printf("Would create: %s\n", path)
+		}
+		for path := range edited {
+			fmt.Printf("Would update: %s\n", path)
+		}
+		return nil
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	for path, content := range edited {
+		if path == configPath {
+			err = os.WriteFile(path, []byte(content), 0644)
+		} else {
+			err = writeFormatted(path, content)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", path, err)
+		}
+	}
+
+	if !skipInstall && driver == templates.CacheDriverRedis {
+		if out, err := exec.Command("go", "get", redisModule).CombinedOutput(); err != nil {
+			fmt.Printf("Warning: Could not add the Redis client: %v\n%s", err, out)
+		} else if err := runGoModTidy("."); err != nil {
+			fmt.Printf("Warning: Could not run go mod tidy: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// checkInjectorConfig makes sure InitializeApp takes the config, which
+// NewCache is built from. Projects created before the injectors took it
+// have to add it first.
+func checkInjectorConfig(content, wirePath string) error {
+	fnIndex := strings.Index(content, "func InitializeApp(")
+	if fnIndex == -1 {
+		return fmt.Errorf("InitializeApp not found in %s", wirePath)
+	}
+	paramsStart := fnIndex + len("func InitializeApp(")
+	params := content[paramsStart : paramsStart+strings.Index(content[paramsStart:], ")")]
+	if !strings.Contains(params, "*configs.Config") {
+		return fmt.Errorf("InitializeApp in %s does not take the config; make it InitializeApp(cfg *configs.Config) and pass the config loaded in main", wirePath)
+	}
+	return nil
+}
+
+// setCacheDriver sets cache.driver in config.yaml, adding the cache section
+// to configs that lack it
+func setCacheDriver(text, driver string) string {
+	line := fmt.Sprintf("  driver: %q", driver)
+
+	start := strings.Index(text, "\ncache:\n")
+	if start == -1 {
+		return text + "\n# Cache Configuration\ncache:\n" + line + "\n"
+	}
+	start += len("\ncache:\n")
+
+	// The section ends at the first line that is not indented
+	end := start
+	for end < len(text) && (text[end] == ' ' || text[end] == '\t' || text[end] == '\n') {
+		next := strings.IndexByte(text[end:], '\n')
+		if next == -1 {
+			end = len(text)
+			break
+		}
+		end += next + 1
+	}
+
+	section := text[start:end]
+	if cacheDriverLine.MatchString(section) {
+		section = cacheDriverLine.ReplaceAllString(section, fmt.Sprintf("${1}%q", driver))
+		return text[:start] + section + text[end:]
+	}
+	return text[:start] + line + "\n" + text[start:]
+}
+
+// addCacheDriverConfig adds the Driver field to CacheConfig
+func addCacheDriverConfig(content, configGoPath string) (string, error) {
+	structIndex := strings.Index(content, "type CacheConfig struct {")
+	if structIndex == -1 {
+		return "", fmt.Errorf("CacheConfig struct not found in %s", configGoPath)
+	}
+	structEnd := structIndex + strings.Index(content[structIndex:], "\n}")
+	if strings.Contains(content[structIndex:structEnd], "Driver ") {
+		return content, nil
+	}
+	insertPos := structIndex + len("type CacheConfig struct {")
+	field := "\n\tDriver string `mapstructure:\"driver\"`"
+	return content[:insertPos] + field + content[insertPos:], nil
+}
+
+// addCacheApp makes NewApp take the cache and close it on shutdown
+func addCacheApp(content, appPath, moduleName string) (string, error) {
+	if strings.Contains(content, "cache.Cache") {
+		return content, nil
+	}
+
+	updatedContent, err := addImport(content, moduleName, moduleName+"/pkg/cache")
+	if err != nil {
+		return "", fmt.Errorf("%w in %s", err, appPath)
+	}
+
+	ctorIndex := strings.Index(updatedContent, "func NewApp(")
+	if ctorIndex == -1 {
+		return "", fmt.Errorf("NewApp not found in %s", appPath)
+	}
+	paramsStart := ctorIndex + len("func NewApp(")
+	paramsEnd := paramsStart + strings.Index(updatedContent[paramsStart:], ")")
+	param := "c cache.Cache"
+	if strings.TrimSpace(updatedContent[paramsStart:paramsEnd]) != "" {
+		param = ", " + param
+	}
+	updatedContent = updatedContent[:paramsEnd] + param + updatedContent[paramsEnd:]
+
+	anchor := "hooks := lifecycle.New()\n"
+	index := strings.Index(updatedContent, anchor)
+	if index == -1 {
+		return "", fmt.Errorf("%q not found in %s; close the cache on shutdown in NewApp", strings.TrimSpace(anchor), appPath)
+	}
+	insertPos := index + len(anchor)
+	return updatedContent[:insertPos] + templates.AppCacheShutdown() + updatedContent[insertPos:], nil
+}
+
+// addWireBuildSet adds set to the wire.Build call of the injector fn
+func addWireBuildSet(content, wirePath, fn, set string) (string, error) {
+	fnIndex := strings.Index(content, "func "+fn+"(")
+	if fnIndex == -1 {
+		return content, nil
+	}
+	buildIndex := strings.Index(content[fnIndex:], "wire.Build(")
+	if buildIndex == -1 {
+		return "", fmt.Errorf("wire.Build not found in %s of %s; add %s to it", fn, wirePath, set)
+	}
+	argsStart := fnIndex + buildIndex + len("wire.Build(")
+	argsEnd := argsStart + strings.Index(content[argsStart:], ")")
+	for _, arg := range strings.Split(content[argsStart:argsEnd], ",") {
+		if strings.TrimSpace(arg) == set {
+			return content, nil
+		}
+	}
+	return content[:argsEnd] + ", " + set + content[argsEnd:], nil
+}
@@ -0,0 +1,137 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/samuel-k-w/meba-cli/internal/deploy"
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+	"github.com/spf13/viper"
+)
+
+// deployEnvs are the environments created next to the first deployment
+var deployEnvs = []string{"dev", "prod"}
+
+// GenerateDeploy writes the deployment files of target to
+// deployments/<target>, with the deploy section of configs/config.yaml
+// and the deployments/env overrides when they are missing
+func GenerateDeploy(target string, dryRun bool) error {
+	files, err := deployFiles(target)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join("deployments", target)); err == nil {
+		return fmt.Errorf("deployments/%s already exists", target)
+	}
+
+	for _, env := range deployEnvs {
+		if file := deploy.EnvFile(".", env); !fileExists(file) {
+			files[file] = templates.DeployEnvYaml(env)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	config := filepath.Join("configs", "config.yaml")
+	addSection := !hasConfigKey(config, "deploy")
+	if dryRun {
+		for _, path := range paths {
+			fmt.Printf("Would create: %s\n", path)
+		}
+		if addSection {
+			fmt.Printf("Would add the deploy section to: %s\n", config)
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(files[path]), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if addSection {
+		f, err := os.OpenFile(config, os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", config, err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(templates.DeployConfigSection()); err != nil {
+			return fmt.Errorf("failed to update %s: %w", config, err)
+		}
+	}
+	return nil
+}
+
+func deployFiles(target string) (map[string]string, error) {
+	dir := filepath.Join("deployments", target)
+	switch target {
+	case "k8s":
+		return map[string]string{
+			filepath.Join(dir, "deployment.yaml"):     templates.K8sDeploymentYaml(),
+			filepath.Join(dir, "service.yaml"):        templates.K8sServiceYaml(),
+			filepath.Join(dir, "configmap.yaml"):      templates.K8sConfigMapYaml(),
+			filepath.Join(dir, "secret.yaml"):         templates.K8sSecretYaml(),
+			filepath.Join(dir, "hpa.yaml"):            templates.K8sHPAYaml(),
+			filepath.Join(dir, "servicemonitor.yaml"): templates.K8sServiceMonitorYaml(),
+		}, nil
+	case "compose":
+		return map[string]string{
+			filepath.Join(dir, "docker-compose.yml"): templates.ComposeDeployYaml(),
+		}, nil
+	case "systemd":
+		values, err := deploy.Load(".", "")
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			filepath.Join(dir, values.Name+".service"): templates.SystemdServiceUnit(),
+			filepath.Join(dir, values.Name+".env"):     templates.SystemdEnvFile(),
+			filepath.Join(dir, "config.yaml"):          templates.SystemdConfigYaml(),
+		}, nil
+	case "helm":
+		values, err := deploy.Load(".", "")
+		if err != nil {
+			return nil, err
+		}
+		helmValues, err := deploy.HelmValues(values)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			filepath.Join(dir, "Chart.yaml"):                       templates.HelmChartYaml(values.Name, values.Version),
+			filepath.Join(dir, "values.yaml"):                      string(helmValues),
+			filepath.Join(dir, "templates", "_helpers.tpl"):        templates.HelmHelpersTpl(),
+			filepath.Join(dir, "templates", "deployment.yaml"):     templates.HelmDeploymentYaml(),
+			filepath.Join(dir, "templates", "service.yaml"):        templates.HelmServiceYaml(),
+			filepath.Join(dir, "templates", "configmap.yaml"):      templates.HelmConfigMapYaml(),
+			filepath.Join(dir, "templates", "secret.yaml"):         templates.HelmSecretYaml(),
+			filepath.Join(dir, "templates", "hpa.yaml"):            templates.HelmHPAYaml(),
+			filepath.Join(dir, "templates", "servicemonitor.yaml"): templates.HelmServiceMonitorYaml(),
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown deploy target %q, expected one of k8s, helm, compose, systemd", target)
+}
+
+// hasConfigKey reports whether the YAML config file sets key
+func hasConfigKey(file, key string) bool {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return false
+	}
+	return v.IsSet(key)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
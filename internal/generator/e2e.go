@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+// e2eHarnessFiles returns the files of the e2e harness, relative to the
+// project root
+func e2eHarnessFiles(moduleName, basePath string) map[string]string {
+	return map[string]string{
+		"test/e2e/harness/harness.go": templates.E2EHarnessGo(moduleName),
+		"test/e2e/apitest/apitest.go": templates.E2EApiTestGo(moduleName),
+		"pkg/snapshot/snapshot.go":    templates.SnapshotGo(),
+		"test/e2e/main_test.go":       templates.E2EMainTestGo(moduleName),
+		"test/e2e/health_test.go":     templates.E2EHealthTestGo(moduleName, basePath),
+	}
+}
+
+// GenerateE2EHarness creates the missing files of the e2e harness in
+// test/e2e and the snapshot package in pkg/snapshot. Existing files are
+// kept.
+func GenerateE2EHarness(basePath string, dryRun bool) error {
+	moduleName := getCurrentModuleName()
+	for filePath, content := range e2eHarnessFiles(moduleName, basePath) {
+		if _, err := os.Stat(filePath); err == nil {
+			continue
+		}
+		if dryRun {
+			fmt.Printf("Would create: %s\n", filePath)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", filePath, err)
+		}
+	}
+
+	wireContent, err := os.ReadFile(filepath.Join("internal", "wire.go"))
+	if err == nil && !strings.Contains(string(wireContent), "InitializeTestApp(cfg *configs.Config, db *gorm.DB)") {
+		fmt.Println("Warning: internal/wire.go has no InitializeTestApp(cfg *configs.Config, db *gorm.DB); add it so the harness can inject the config and the test database")
+	}
+	return nil
+}
+
+// GenerateE2EResource creates CRUD e2e tests for a resource in
+// test/e2e/<name>_test.go, together with the harness if needed
+func GenerateE2EResource(name, basePath string, dryRun bool) error {
+	modulePath, exists := findModulePath(name)
+	if !exists {
+		return fmt.Errorf("module %s not found in internal/", name)
+	}
+	if err := GenerateE2EHarness(basePath, dryRun); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join("test", "e2e", name+"_test.go")
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("%s already exists", filePath)
+	}
+	if dryRun {
+		fmt.Printf("Would create: %s\n", filePath)
+		return nil
+	}
+
+	content := templates.E2EResourceTestGo(getCurrentModuleName(), name, basePath, resourcePagination(modulePath))
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// resourcePagination tells whether a resource was generated with cursor
+// or offset pagination from the DTOs of its list endpoint
+func resourcePagination(modulePath string) string {
+	dto, err := os.ReadFile(filepath.Join(modulePath, "dto.go"))
+	if err == nil && strings.Contains(string(dto), "type CursorRequest struct") {
+		return templates.PaginationCursor
+	}
+	return templates.PaginationOffset
+}
@@ -0,0 +1,106 @@
+package generator
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+// GenerateHealthCheck creates a custom checker in internal/checks and
+// registers it in internal/health.go, so wire injects it into the health
+// checks served on /health/ready
+func GenerateHealthCheck(name string, dryRun bool) error {
+	filePath := filepath.Join("internal", "checks", name+".go")
+	healthPath := filepath.Join("internal", "health.go")
+
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("%s already exists", filePath)
+	}
+	if _, err := os.Stat(healthPath); err != nil {
+		return fmt.Errorf("%s not found; it is created by meba new", healthPath)
+	}
+
+	if dryRun {
+		fmt.Printf("Would create: %s\n", filePath)
+		fmt.Printf("Would register %s in: %s\n", name, healthPath)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, []byte(templates.HealthCheckGo(name)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return registerHealthCheck(healthPath, name)
+}
+
+// registerHealthCheck adds the checker to HealthSet and injects it into
+// NewHealth
+func registerHealthCheck(healthPath, name string) error {
+	content, err := os.ReadFile(healthPath)
+	if err != nil {
+		return err
+	}
+	updatedContent := string(content)
+	titleName := strings.Title(name)
+	ctor := fmt.Sprintf("checks.New%sChecker", titleName)
+	if strings.Contains(updatedContent, ctor) {
+		return nil
+	}
+
+	// Add import
+	importLine := fmt.Sprintf("\"%s/internal/checks\"", getCurrentModuleName())
+	if !strings.Contains(updatedContent, importLine) {
+		importIndex := strings.Index(updatedContent, "import (")
+		if importIndex == -1 {
+			return fmt.Errorf("import block not found in %s", healthPath)
+		}
+		insertPos := importIndex + len("import (")
+		updatedContent = updatedContent[:insertPos] + "\n\t" + importLine + updatedContent[insertPos:]
+	}
+
+	// Add the provider to the wire set
+	setIndex := strings.Index(updatedContent, "HealthSet = wire.NewSet(")
+	if setIndex == -1 {
+		return fmt.Errorf("HealthSet not found in %s", healthPath)
+	}
+	insertPos := setIndex + strings.Index(updatedContent[setIndex:], ")")
+	updatedContent = strings.TrimRight(updatedContent[:insertPos], " \t\n") + "\n\t" + ctor + ",\n" + updatedContent[insertPos:]
+
+	// Add the constructor parameter
+	param := fmt.Sprintf("%sChecker *checks.%sChecker", name, titleName)
+	ctorIndex := strings.Index(updatedContent, "func NewHealth(")
+	if ctorIndex == -1 {
+		return fmt.Errorf("NewHealth not found in %s", healthPath)
+	}
+	paramsStart := ctorIndex + len("func NewHealth(")
+	paramsEnd := paramsStart + strings.Index(updatedContent[paramsStart:], ")")
+	if strings.TrimSpace(updatedContent[paramsStart:paramsEnd]) != "" {
+		param = ", " + param
+	}
+	updatedContent = updatedContent[:paramsEnd] + param + updatedContent[paramsEnd:]
+
+	// Register the checker
+	add := fmt.Sprintf("h.Add(%sChecker)", name)
+	marker := "// Custom checkers are added here by meba g health-check"
+	markerIndex := strings.Index(updatedContent, marker)
+	if markerIndex == -1 {
+		return fmt.Errorf("%q comment not found in %s; add %s to NewHealth", marker, healthPath, add)
+	}
+	insertPos = markerIndex + len(marker)
+	if returnIndex := strings.Index(updatedContent[insertPos:], "\n\treturn "); returnIndex != -1 {
+		insertPos += returnIndex
+	}
+	updatedContent = updatedContent[:insertPos] + "\n\t" + add + updatedContent[insertPos:]
+
+	formatted, err := format.Source([]byte(updatedContent))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", healthPath, err)
+	}
+	return os.WriteFile(healthPath, formatted, 0644)
+}
@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// GenerateMetric creates a Prometheus metric in a module and adds its
+// constructor to the module's wire set, so services can inject it
+func GenerateMetric(moduleName, name, metricType string, labels []string, dryRun bool) error {
+	name = strings.ReplaceAll(name, "-", "_")
+	if !metricNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid metric name %q, use snake_case such as orders_created", name)
+	}
+	valid := false
+	for _, t := range templates.MetricTypes {
+		valid = valid || t == metricType
+	}
+	if !valid {
+		return fmt.Errorf("unknown metric type %q, expected one of %s", metricType, strings.Join(templates.MetricTypes, ", "))
+	}
+	for _, label := range labels {
+		if !metricNamePattern.MatchString(label) {
+			return fmt.Errorf("invalid label %q", label)
+		}
+	}
+
+	modulePath, ok := findModulePath(moduleName)
+	if !ok {
+		return fmt.Errorf("module %s not found in internal/", moduleName)
+	}
+	filePath := filepath.Join(modulePath, name+"_metric.go")
+	if _, err := os.Stat(filePath); err == nil {
+		return fmt.Errorf("%s already exists", filePath)
+	}
+
+	if dryRun {
+		fmt.Printf("Would create: %s\n", filePath)
+		fmt.Printf("Would register New%s in: %s\n", templates.MetricTypeName(name), filepath.Join(modulePath, "module.go"))
+		return nil
+	}
+
+	content := templates.MetricGo(filepath.Base(modulePath), name, metricType, labels)
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return addWireProvider(filepath.Join(modulePath, "module.go"), "wire.NewSet(", "New"+templates.MetricTypeName(name))
+}
+
+// addWireProvider appends provider to the wire set starting at anchor,
+// e.g. "AppSet = wire.NewSet(", in the Go file at path
+func addWireProvider(path, anchor, provider string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updatedContent := string(content)
+	if strings.Contains(updatedContent, "\t"+provider+",") {
+		return nil
+	}
+	wireIndex := strings.Index(updatedContent, anchor)
+	if wireIndex == -1 {
+		return fmt.Errorf("%s not found in %s; add %s to the wire set", strings.TrimSuffix(anchor, "("), path, provider)
+	}
+
+	// The set ends at the parenthesis closing wire.NewSet(
+	depth := 0
+	end := -1
+	for i := wireIndex + len(anchor) - 1; i < len(updatedContent); i++ {
+		switch updatedContent[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return fmt.Errorf("unterminated wire.NewSet in %s", path)
+	}
+	updatedContent = strings.TrimRight(updatedContent[:end], " \t\n") + "\n\t" + provider + ",\n" + updatedContent[end:]
+	return os.WriteFile(path, []byte(updatedContent), 0644)
+}
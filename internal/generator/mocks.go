@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/samuel-k-w/meba-cli/internal/mockgen"
+)
+
+// GenerateMocks writes testify mocks for the interfaces of a module, or of
+// all modules when name is empty. File paths are returned relative to the
+// project root.
+func GenerateMocks(name string) ([]mockgen.File, error) {
+	pattern := "./internal/..."
+	if name != "" {
+		modulePath, exists := findModulePath(name)
+		if !exists {
+			return nil, fmt.Errorf("module %s not found in internal/", name)
+		}
+		pattern = "./" + filepath.ToSlash(modulePath)
+	}
+
+	files, err := mockgen.Generate(".", pattern)
+	if err != nil {
+		return nil, err
+	}
+	if wd, err := os.Getwd(); err == nil {
+		for i := range files {
+			if rel, err := filepath.Rel(wd, files[i].Path); err == nil {
+				files[i].Path = rel
+			}
+		}
+	}
+	return files, nil
+}
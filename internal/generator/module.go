@@ -168,15 +168,32 @@ func GenerateRepository(name string, dryRun, flat, noSpec bool) error {
 	return nil
 }
 
-func GenerateResource(name string, dryRun, noSpec bool) error {
+func GenerateResource(name string, dryRun, noSpec bool, pagination string, cached bool) error {
 	var modulePath string
 	modulePath = filepath.Join("internal", name)
 
+	valid := false
+	for _, mode := range templates.PaginationModes {
+		valid = valid || mode == pagination
+	}
+	if !valid {
+		return fmt.Errorf("unknown pagination %q, expected one of %s", pagination, strings.Join(templates.PaginationModes, ", "))
+	}
+	if cached && !fileExists(filepath.Join("pkg", "cache", "cache.go")) {
+		return fmt.Errorf("pkg/cache not found; set it up with meba add cache")
+	}
+
 	if dryRun {
-		fmt.Printf("Would create complete CRUD resource for: %s\n", name)
+		fmt.Printf("Would create complete CRUD resource for: %s (%s pagination)\n", name, pagination)
 		fmt.Printf("Files: module.go, handlers.go, service.go, repository.go, entity.go, dto.go\n")
+		if cached {
+			fmt.Printf("Cached service: cache.go\n")
+		}
 		if !noSpec {
-			fmt.Printf("Test files: handlers_test.go, service_test.go, repository_test.go\n")
+			fmt.Printf("Test files: handlers_test.go, service_test.go, repository_test.go, mocks/mocks.go\n")
+			if cached {
+				fmt.Printf("Cached service test: cache_test.go\n")
+			}
 		}
 		return nil
 	}
@@ -188,19 +205,27 @@ func GenerateResource(name string, dryRun, noSpec bool) error {
 
 	// Generate complete resource files
 	files := map[string]string{
-		"module.go":     templates.ModuleGo(name),
-		"handlers.go":   templates.ModuleHandlersGo(name),
-		"service.go":    templates.ModuleServiceGoSimple(name),
-		"repository.go": templates.ModuleRepositoryGoSimple(name),
-		"entity.go":     templates.ModuleEntityGoSimple(name),
-		"dto.go":        templates.ModuleDtoGoSimple(name),
+		"module.go":     templates.ModuleGo(name, cached),
+		"handlers.go":   templates.ModuleHandlersGo(getCurrentModuleName(), name, pagination, cached),
+		"service.go":    templates.ModuleServiceGoSimple(getCurrentModuleName(), name, pagination),
+		"repository.go": templates.ModuleRepositoryGoSimple(getCurrentModuleName(), name, pagination),
+		"entity.go":     templates.ModuleEntityGoSimple(getCurrentModuleName(), name),
+		"dto.go":        templates.ModuleDtoGoSimple(name, pagination),
 	}
 	
 	// Add test files unless --no-spec
 	if !noSpec {
-		files["handlers_test.go"] = templates.HandlersTestGoModule(name)
-		files["service_test.go"] = templates.ServiceTestGoModule(name)
-		files["repository_test.go"] = templates.RepositoryTestGoModule(name)
+		files["handlers_test.go"] = templates.ResourceHandlersTestGo(getCurrentModuleName(), name, pagination)
+		files["service_test.go"] = templates.ResourceServiceTestGo(getCurrentModuleName(), name, pagination)
+		files["repository_test.go"] = templates.ResourceRepositoryTestGo(getCurrentModuleName(), name, pagination)
+	}
+
+	// The cached service reads GetByID and GetAll through pkg/cache
+	if cached {
+		files["cache.go"] = templates.CachedServiceGo(getCurrentModuleName(), name, pagination)
+		if !noSpec {
+			files["cache_test.go"] = templates.CachedServiceTestGo(getCurrentModuleName(), name, pagination)
+		}
 	}
 
 	for fileName, content := range files {
@@ -210,11 +235,41 @@ func GenerateResource(name string, dryRun, noSpec bool) error {
 		}
 	}
 
+	// The list endpoint parses its query with pkg/query, shared by every
+	// resource; projects created before it existed get it here
+	queryFiles := map[string]string{
+		filepath.Join("pkg", "query", "query.go"):  templates.QueryGo(getCurrentModuleName()),
+		filepath.Join("pkg", "query", "cursor.go"): templates.QueryCursorGo(getCurrentModuleName()),
+	}
+	for filePath, content := range queryFiles {
+		if fileExists(filePath) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", filePath, err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", filePath, err)
+		}
+	}
+
 	// Update app.go to register the module
 	if err := updateAppModule(name); err != nil {
 		fmt.Printf("Warning: Could not auto-register module in app.go: %v\n", err)
 	}
 
+	// Mount the module routes
+	if err := updateHandlersModule(name); err != nil {
+		fmt.Printf("Warning: Could not mount %s routes in handlers.go: %v\n", name, err)
+	}
+
+	// The handler and service tests use the mocks of the interfaces
+	if !noSpec {
+		if _, err := GenerateMocks(name); err != nil {
+			fmt.Printf("Warning: Could not generate mocks, run meba g mocks %s: %v\n", name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -256,7 +311,7 @@ func GenerateGuard(name string, dryRun, flat bool) error {
 		return nil
 	}
 
-	content := templates.GuardGo(name)
+	content := templates.GuardGo(getCurrentModuleName(), name)
 	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write guard file: %w", err)
 	}
@@ -270,7 +325,7 @@ func updateModuleFile(modulePath, moduleName, componentType string) error {
 	// Check if module.go exists
 	if _, err := os.Stat(moduleFilePath); os.IsNotExist(err) {
 		// Create module.go if it doesn't exist
-		content := templates.ModuleGo(moduleName)
+		content := templates.ModuleGo(moduleName, false)
 		return os.WriteFile(moduleFilePath, []byte(content), 0644)
 	}
 
@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"go/format"
 	"os"
 	"path/filepath"
 	"strings"
@@ -41,28 +42,48 @@ func CreateProject(name, targetDir string, skipGit, skipInstall bool) error {
 		"go.mod":                    templates.GoMod(moduleName),
 		"README.md":                 templates.ReadmeMd(name),
 		"cmd/server/main.go":        templates.MainGo(moduleName),
-		"internal/app.go":           templates.AppGo(),
-		"internal/handlers.go":      templates.HandlersGo(),
+		"internal/app.go":           templates.AppGo(moduleName),
+		"internal/handlers.go":      templates.HandlersGo(moduleName),
+		"internal/health.go":        templates.AppHealthGo(moduleName),
+		"internal/metrics.go":       templates.AppMetricsGo(moduleName),
 		"internal/service.go":       templates.ServiceGo(),
 		"internal/entity.go":        templates.EntityGo(),
 		"internal/dto.go":           templates.DtoGo(),
 		"internal/repository.go":    templates.RepositoryGo(),
-		"internal/wire.go":         templates.WireGo(),
-		"pkg/middleware/logger.go":  templates.LoggerMiddleware(),
-		"pkg/middleware/recover.go": templates.RecoverMiddleware(),
-		"pkg/middleware/auth.go":    templates.AuthMiddleware(),
+		"internal/wire.go":         templates.WireGo(moduleName),
+		"internal/version/version.go": templates.VersionGo(),
+		"pkg/middleware/logger.go":  templates.LoggerMiddleware(moduleName),
+		"pkg/middleware/recover.go": templates.RecoverMiddleware(moduleName),
+		"pkg/middleware/errors.go":  templates.ErrorsMiddleware(moduleName),
+		"pkg/middleware/auth.go":    templates.AuthMiddleware(moduleName),
 		"pkg/validator/validator.go": templates.ValidatorGo(),
+		"pkg/validator/password.go":  templates.ValidatorPasswordGo(),
+		"pkg/validator/validator_test.go": templates.ValidatorTestGo(moduleName),
+		"pkg/lifecycle/lifecycle.go": templates.LifecycleGo(),
+		"pkg/logging/logging.go":     templates.LoggingGo(moduleName),
+		"pkg/errors/errors.go":       templates.ErrorsGo(moduleName),
+		"pkg/query/query.go":         templates.QueryGo(moduleName),
+		"pkg/query/cursor.go":        templates.QueryCursorGo(moduleName),
+		"pkg/query/query_test.go":    templates.QueryTestGo(moduleName),
+		"pkg/health/health.go":       templates.HealthGo(),
+		"pkg/health/checkers.go":     templates.HealthCheckersGo(),
+		"pkg/health/disk_unix.go":    templates.HealthDiskUnixGo(),
+		"pkg/health/disk_other.go":   templates.HealthDiskOtherGo(),
+		"pkg/metrics/metrics.go":     templates.MetricsGo(),
 		"configs/config.yaml":       templates.ConfigYaml(),
 		"configs/config.go":         templates.ConfigGo(),
-		"internal/handlers_test.go": templates.HandlersTestGo(),
+		"internal/handlers_test.go": templates.HandlersTestGo(moduleName),
 		"internal/service_test.go":  templates.ServiceTestGo(),
 		"docs/docs.go":              templates.SwaggerDocsGo(name),
-		".air.toml":                 templates.AirToml(),
 		".gitignore":                templates.GitIgnore(),
 		"Dockerfile":                templates.Dockerfile(),
 		"docker-compose.yml":        templates.DockerCompose(name),
 	}
 
+	for filePath, content := range e2eHarnessFiles(moduleName, "/api/v1") {
+		files[filePath] = content
+	}
+
 	for filePath, content := range files {
 		fullPath := filepath.Join(targetDir, filePath)
 		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
@@ -119,16 +140,9 @@ func updateAppModule(moduleName string) error {
 	updatedContent := string(content)
 	
 	// Add import
-	importLine := fmt.Sprintf("\"%s/internal/%s\"", getCurrentModuleName(), moduleName)
-	if !strings.Contains(updatedContent, importLine) {
-		importIndex := strings.Index(updatedContent, "import (")
-		if importIndex != -1 {
-			endIndex := strings.Index(updatedContent[importIndex:], ")")
-			if endIndex != -1 {
-				insertPos := importIndex + endIndex
-				updatedContent = updatedContent[:insertPos] + "\n\t" + importLine + updatedContent[insertPos:]
-			}
-		}
+	updatedContent, err = addImport(updatedContent, getCurrentModuleName(), getCurrentModuleName()+"/internal/"+moduleName)
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, appPath)
 	}
 
 	// Add module registration
@@ -138,13 +152,96 @@ func updateAppModule(moduleName string) error {
 		if wireIndex != -1 {
 			endIndex := strings.Index(updatedContent[wireIndex:], ")")
 			if endIndex != -1 {
-				insertPos := wireIndex + endIndex
-				updatedContent = updatedContent[:insertPos] + "\n\t" + regLine + updatedContent[insertPos:]
+				// gofmt drops the comma after the last set when the
+				// paren follows it on the same line
+				insertPos := wireIndex + len(strings.TrimRight(updatedContent[wireIndex:wireIndex+endIndex], " \t\n"))
+				sep := "\n\t"
+				if last := updatedContent[insertPos-1]; last != ',' && last != '(' {
+					sep = "," + sep
+				}
+				updatedContent = updatedContent[:insertPos] + sep + regLine + "\n" + updatedContent[insertPos:]
 			}
 		}
 	}
 
-	return os.WriteFile(appPath, []byte(updatedContent), 0644)
+	return writeFormatted(appPath, updatedContent)
+}
+
+// updateHandlersModule injects the handlers of a module into
+// internal.Handlers and mounts its routes under the API group
+func updateHandlersModule(moduleName string) error {
+	handlersPath := "internal/handlers.go"
+	content, err := os.ReadFile(handlersPath)
+	if err != nil {
+		return err
+	}
+
+	updatedContent := string(content)
+	field := fmt.Sprintf("%s *%s.Handlers", moduleName, moduleName)
+	if strings.Contains(updatedContent, "\n\t"+field) {
+		return nil
+	}
+
+	// Add import
+	importLine := fmt.Sprintf("\"%s/internal/%s\"", getCurrentModuleName(), moduleName)
+	if !strings.Contains(updatedContent, importLine) {
+		importIndex := strings.Index(updatedContent, "import (")
+		if importIndex == -1 {
+			return fmt.Errorf("import block not found in %s", handlersPath)
+		}
+		insertPos := importIndex + len("import (")
+		updatedContent = updatedContent[:insertPos] + "\n\t" + importLine + updatedContent[insertPos:]
+	}
+
+	// Add the struct field
+	structIndex := strings.Index(updatedContent, "type Handlers struct {")
+	if structIndex == -1 {
+		return fmt.Errorf("Handlers struct not found in %s", handlersPath)
+	}
+	insertPos := structIndex + strings.Index(updatedContent[structIndex:], "\n}")
+	updatedContent = updatedContent[:insertPos] + "\n\t" + field + updatedContent[insertPos:]
+
+	// Add the constructor parameter and assignment
+	param := fmt.Sprintf("%sHandlers *%s.Handlers", moduleName, moduleName)
+	ctorIndex := strings.Index(updatedContent, "func NewHandlers(")
+	if ctorIndex == -1 {
+		return fmt.Errorf("NewHandlers not found in %s", handlersPath)
+	}
+	paramsStart := ctorIndex + len("func NewHandlers(")
+	paramsEnd := paramsStart + strings.Index(updatedContent[paramsStart:], ")")
+	if strings.TrimSpace(updatedContent[paramsStart:paramsEnd]) != "" {
+		param = ", " + param
+	}
+	updatedContent = updatedContent[:paramsEnd] + param + updatedContent[paramsEnd:]
+
+	returnIndex := strings.Index(updatedContent[ctorIndex:], "return &Handlers{")
+	if returnIndex == -1 {
+		return fmt.Errorf("NewHandlers does not return &Handlers{...}")
+	}
+	literalStart := ctorIndex + returnIndex + len("return &Handlers{")
+	insertPos = literalStart + strings.Index(updatedContent[literalStart:], "}")
+	updatedContent = strings.TrimRight(updatedContent[:insertPos], " \t\n") +
+		fmt.Sprintf("\n\t\t%s: %sHandlers,\n\t", moduleName, moduleName) + updatedContent[insertPos:]
+
+	// Mount the routes
+	mount := fmt.Sprintf("h.%s.SetupRoutes(api)", moduleName)
+	marker := "// Setup module routes here"
+	markerIndex := strings.Index(updatedContent, marker)
+	if markerIndex == -1 {
+		return fmt.Errorf("%q comment not found in %s; add %s to SetupRoutes", marker, handlersPath, mount)
+	}
+	insertPos = markerIndex + strings.Index(updatedContent[markerIndex:], "\n}")
+	// The first module creates the group its routes are mounted on
+	if !strings.Contains(updatedContent, "api := ") {
+		mount = "api := r.Group(\"/api/v1\")\n\t" + mount
+	}
+	updatedContent = updatedContent[:insertPos] + "\n\t" + mount + updatedContent[insertPos:]
+
+	formatted, err := format.Source([]byte(updatedContent))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", handlersPath, err)
+	}
+	return os.WriteFile(handlersPath, formatted, 0644)
 }
 
 func getCurrentModuleName() string {
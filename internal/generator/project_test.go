@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+// TestCreateProjectBuilds generates a project, then a resource in it, and
+// vets both with the wireinject tag, which compiles the injectors of
+// internal/wire.go in place of the wire_gen.go meba wire writes. The tests
+// generated for pkg, such as those of query.Parse, run on the scaffold.
+func TestCreateProjectBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated project")
+	}
+	dir := filepath.Join(t.TempDir(), "app")
+	if err := CreateProject("app", dir, true, true); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := goCommand(dir, "mod", "tidy"); err != nil {
+		t.Skipf("dependencies unavailable: %v\n%s", err, out)
+	}
+	if out, err := goCommand(dir, "vet", "-tags", "wireinject", "./..."); err != nil {
+		t.Fatalf("scaffold does not build: %v\n%s", err, out)
+	}
+	if out, err := goCommand(dir, "test", "./pkg/..."); err != nil {
+		t.Fatalf("tests of the scaffold fail: %v\n%s", err, out)
+	}
+
+	chdir(t, dir)
+	if err := GenerateResource("orders", false, false, templates.PaginationOffset, false); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := goCommand(dir, "vet", "-tags", "wireinject", "./..."); err != nil {
+		t.Fatalf("scaffold with a resource does not build: %v\n%s", err, out)
+	}
+}
+
+func goCommand(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// chdir changes the working directory for the rest of the test, as the
+// generators work on the project in it
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -0,0 +1,265 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// tracingModules are the OpenTelemetry modules used by pkg/tracing,
+// pinned to versions that build with the go directive of meba new
+var tracingModules = []string{
+	"go.opentelemetry.io/otel@v1.24.0",
+	"go.opentelemetry.io/otel/sdk@v1.24.0",
+	"go.opentelemetry.io/otel/trace@v1.24.0",
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp@v1.24.0",
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc@v1.24.0",
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace@v1.24.0",
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin@v0.49.0",
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp@v0.49.0",
+	"gorm.io/plugin/opentelemetry@v0.1.8",
+}
+
+// GenerateTracing adds pkg/tracing to the project and wires it into the
+// config, the app and cmd/server/main.go
+func GenerateTracing(dryRun, skipInstall bool) error {
+	moduleName := getCurrentModuleName()
+	files := map[string]string{
+		filepath.Join("pkg", "tracing", "tracing.go"): templates.TracingGo(),
+		filepath.Join("pkg", "tracing", "gorm.go"):    templates.TracingGormGo(),
+		filepath.Join("internal", "tracing.go"):       templates.AppTracingGo(moduleName),
+	}
+	for path := range files {
+		if fileExists(path) {
+			return fmt.Errorf("%s already exists; tracing is already set up", path)
+		}
+	}
+
+	mainPath := filepath.Join("cmd", "server", "main.go")
+	configGoPath := filepath.Join("configs", "config.go")
+	configPath := filepath.Join("configs", "config.yaml")
+	appPath := filepath.Join("internal", "app.go")
+	for _, path := range []string{mainPath, configGoPath, configPath, appPath} {
+		if !fileExists(path) {
+			return fmt.Errorf("%s not found; it is created by meba new", path)
+		}
+	}
+
+	if dryRun {
+		for path := range files {
+			fmt.Printf("Would create: %s\n", path)
+		}
+		fmt.Printf("Would add the tracing section to: %s\n", configPath)
+		fmt.Printf("Would update: %s, %s, %s\n", configGoPath, appPath, mainPath)
+		return nil
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	if !hasConfigKey(configPath, "tracing") {
+		if err := appendFile(configPath, templates.TracingConfigSection()); err != nil {
+			return err
+		}
+	}
+	if err := addTracingConfig(configGoPath, moduleName); err != nil {
+		return err
+	}
+	if err := addWireProvider(appPath, "AppSet = wire.NewSet(", "TracingSet"); err != nil {
+		return err
+	}
+	if err := addTracingMain(mainPath, moduleName); err != nil {
+		return err
+	}
+
+	if !skipInstall {
+		args := append([]string{"get"}, tracingModules...)
+		if out, err := exec.Command("go", args...).CombinedOutput(); err != nil {
+			fmt.Printf("Warning: Could not add the tracing dependencies: %v\n%s", err, out)
+		} else if err := runGoModTidy("."); err != nil {
+			fmt.Printf("Warning: Could not run go mod tidy: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// addTracingConfig adds the Tracing field to the Config struct
+func addTracingConfig(configGoPath, moduleName string) error {
+	content, err := os.ReadFile(configGoPath)
+	if err != nil {
+		return err
+	}
+	updatedContent := string(content)
+	if strings.Contains(updatedContent, "tracing.Config") {
+		return nil
+	}
+
+	updatedContent, err = addImport(updatedContent, moduleName, moduleName+"/pkg/tracing")
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, configGoPath)
+	}
+
+	structIndex := strings.Index(updatedContent, "type Config struct {")
+	if structIndex == -1 {
+		return fmt.Errorf("Config struct not found in %s", configGoPath)
+	}
+	insertPos := structIndex + strings.Index(updatedContent[structIndex:], "\n}")
+	field := "\n\tTracing tracing.Config `mapstructure:\"tracing\"`"
+	updatedContent = updatedContent[:insertPos] + field + updatedContent[insertPos:]
+
+	return writeFormatted(configGoPath, updatedContent)
+}
+
+// addTracingMain sets up tracing in run and adds the Gin middleware
+func addTracingMain(mainPath, moduleName string) error {
+	content, err := os.ReadFile(mainPath)
+	if err != nil {
+		return err
+	}
+	updatedContent := string(content)
+	if strings.Contains(updatedContent, "tracing.Setup(") {
+		return nil
+	}
+
+	updatedContent, err = addImport(updatedContent, moduleName, moduleName+"/pkg/tracing")
+	if err != nil {
+		return fmt.Errorf("%w in %s", err, mainPath)
+	}
+
+	anchor := "defer cleanup()\n"
+	index := strings.Index(updatedContent, anchor)
+	if index == -1 {
+		return fmt.Errorf("%q not found in %s; set up tracing after InitializeApp", strings.TrimSpace(anchor), mainPath)
+	}
+	insertPos := index + len(anchor)
+	updatedContent = updatedContent[:insertPos] + templates.TracingMainSetup() + updatedContent[insertPos:]
+
+	// The span must start before the request logger reads its IDs
+	index = -1
+	for _, anchor = range []string{"r := gin.New()\n", "r := gin.Default()\n"} {
+		if index = strings.Index(updatedContent, anchor); index != -1 {
+			break
+		}
+	}
+	if index == -1 {
+		return fmt.Errorf("gin engine not found in %s; add r.Use(tracing.Middleware(cfg.App.Name))", mainPath)
+	}
+	insertPos = index + len(anchor)
+	updatedContent = updatedContent[:insertPos] + "\tr.Use(tracing.Middleware(cfg.App.Name))\n" + updatedContent[insertPos:]
+	updatedContent = strings.Replace(updatedContent, "middleware.Logger(logger)", "middleware.Logger(logger, tracing.Fields)", 1)
+
+	return writeFormatted(mainPath, updatedContent)
+}
+
+// addImport adds path, a package of module, to the imports of a Go source
+// file. It goes in the group of the module's other packages, or in a new
+// group after the others, and the imports already there stay as they are.
+func addImport(content, module, path string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse: %w", err)
+	}
+	for _, spec := range file.Imports {
+		if importPath(spec) == path {
+			return content, nil
+		}
+	}
+
+	var decl *ast.GenDecl
+	for _, d := range file.Decls {
+		if d, ok := d.(*ast.GenDecl); ok && d.Tok == token.IMPORT && d.Lparen.IsValid() {
+			decl = d
+		}
+	}
+	if decl == nil {
+		if !astutil.AddImport(fset, file, path) {
+			return content, nil
+		}
+		return printFile(fset, file)
+	}
+
+	line := "\t" + strconv.Quote(path) + "\n"
+	var local []*ast.ImportSpec
+	for _, spec := range decl.Specs {
+		spec := spec.(*ast.ImportSpec)
+		if p := importPath(spec); p == module || strings.HasPrefix(p, module+"/") {
+			local = append(local, spec)
+		}
+	}
+	if len(local) == 0 {
+		offset := fset.Position(decl.Rparen).Offset
+		return content[:offset] + "\n" + line + content[offset:], nil
+	}
+	// Go before the first import of the group that sorts after path
+	for _, spec := range local {
+		if importPath(spec) > path {
+			offset := lineStart(content, fset.Position(spec.Pos()).Offset)
+			return content[:offset] + line + content[offset:], nil
+		}
+	}
+	offset := fset.Position(local[len(local)-1].End()).Offset
+	offset += strings.IndexByte(content[offset:], '\n') + 1
+	return content[:offset] + line + content[offset:], nil
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path
+}
+
+func lineStart(content string, offset int) int {
+	return strings.LastIndexByte(content[:offset], '\n') + 1
+}
+
+// writeFormatted writes content formatted like gofmt, apart from sorting the
+// imports, which would move the ones the user placed by hand
+func writeFormatted(path, content string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	formatted, err := printFile(fset, file)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return os.WriteFile(path, []byte(formatted), 0644)
+}
+
+func printFile(fset *token.FileSet, file *ast.File) (string, error) {
+	var buf bytes.Buffer
+	config := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := config.Fprint(&buf, fset, file); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+var validatorTagPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// GenerateValidator creates a custom validation rule in pkg/validator. It
+// registers itself from an init function, so every request bound by Gin
+// can use it as a validate tag.
+func GenerateValidator(name string, dryRun bool) error {
+	tag := strings.ReplaceAll(name, "-", "_")
+	if !validatorTagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid rule name %q, use snake_case such as strong_password", name)
+	}
+	validatorPath := filepath.Join("pkg", "validator", "validator.go")
+	if !fileExists(validatorPath) {
+		return fmt.Errorf("%s not found; it is created by meba new", validatorPath)
+	}
+	filePath := filepath.Join("pkg", "validator", tag+".go")
+	if fileExists(filePath) {
+		return fmt.Errorf("%s already exists", filePath)
+	}
+
+	if dryRun {
+		fmt.Printf("Would create: %s\n", filePath)
+		return nil
+	}
+
+	if err := os.WriteFile(filePath, []byte(templates.ValidatorRuleGo(tag)), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filePath, err)
+	}
+	return nil
+}
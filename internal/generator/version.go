@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/samuel-k-w/meba-cli/internal/templates"
+)
+
+// EnsureVersionPackage creates internal/version in projects generated
+// before it existed, and returns its import path for -ldflags -X
+func EnsureVersionPackage() (pkgPath string, created bool, err error) {
+	pkgPath = getCurrentModuleName() + "/internal/version"
+
+	file := filepath.Join("internal", "version", "version.go")
+	if _, err := os.Stat(file); err == nil {
+		return pkgPath, false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", false, fmt.Errorf("failed to create internal/version: %w", err)
+	}
+	if err := os.WriteFile(file, []byte(templates.VersionGo()), 0644); err != nil {
+		return "", false, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return pkgPath, true, nil
+}
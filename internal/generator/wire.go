@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/wire"
+)
+
+// WireDiagnostic is a wire error attributed to the module that caused it
+type WireDiagnostic struct {
+	// Module is the module directory under internal/, or "app" for the
+	// application wiring in internal/ itself
+	Module  string
+	Message string
+}
+
+// WireError reports why wire could not generate the injectors
+type WireError struct {
+	Diagnostics []WireDiagnostic
+}
+
+func (e *WireError) Error() string {
+	return fmt.Sprintf("wire generation failed with %d error(s)", len(e.Diagnostics))
+}
+
+var (
+	wirePosition  = regexp.MustCompile(`([^\s()"]+\.go):\d+`)
+	wireQualifier = regexp.MustCompile(`\b([a-z][a-z0-9_]*)\.[A-Z]\w*`)
+)
+
+// GenerateWire runs wire's generator on ./internal of the project in dir and
+// writes wire_gen.go. It returns the files written or a *WireError.
+func GenerateWire(dir string) ([]string, error) {
+	wd, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	results, errs := wire.Generate(context.Background(), wd, os.Environ(), []string{"./internal"}, &wire.GenerateOptions{})
+	for _, r := range results {
+		errs = append(errs, r.Errs...)
+	}
+	if len(errs) > 0 {
+		return nil, &WireError{Diagnostics: wireDiagnostics(wd, errs)}
+	}
+
+	var written []string
+	for _, r := range results {
+		if len(r.Content) == 0 {
+			continue
+		}
+		if err := r.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", r.OutputPath, err)
+		}
+		if rel, err := filepath.Rel(wd, r.OutputPath); err == nil {
+			written = append(written, rel)
+		} else {
+			written = append(written, r.OutputPath)
+		}
+	}
+	return written, nil
+}
+
+// wireDiagnostics makes paths relative to the project, drops repeated errors
+// and attributes each error to a module
+func wireDiagnostics(wd string, errs []error) []WireDiagnostic {
+	modules := map[string]bool{}
+	if entries, err := os.ReadDir(filepath.Join(wd, "internal")); err == nil {
+		for _, e := range entries {
+			if e.IsDir() {
+				modules[e.Name()] = true
+			}
+		}
+	}
+
+	// Errors in a set shared by several injectors are reported once per
+	// injector with the same position and message
+	diagnostics := make([]WireDiagnostic, 0, len(errs))
+	seen := map[string]bool{}
+	for _, err := range errs {
+		msg := strings.ReplaceAll(err.Error(), wd+string(filepath.Separator), "")
+		if seen[msg] {
+			continue
+		}
+		seen[msg] = true
+		diagnostics = append(diagnostics, WireDiagnostic{
+			Module:  wireModule(msg, modules),
+			Message: msg,
+		})
+	}
+	return diagnostics
+}
+
+// wireModule picks the module an error is about. Wire lists the provider
+// closest to the problem first, so the first position inside
+// internal/<module>/ wins, then package qualifiers such as *users.Service.
+func wireModule(msg string, modules map[string]bool) string {
+	for _, pos := range wirePosition.FindAllStringSubmatch(msg, -1) {
+		parts := strings.Split(filepath.ToSlash(pos[1]), "/")
+		if len(parts) >= 3 && parts[0] == "internal" && modules[parts[1]] {
+			return parts[1]
+		}
+	}
+	for _, m := range wireQualifier.FindAllStringSubmatch(msg, -1) {
+		if modules[m[1]] {
+			return m[1]
+		}
+	}
+	return "app"
+}
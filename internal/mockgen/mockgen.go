@@ -0,0 +1,336 @@
+// Package mockgen generates testify mocks for the exported interfaces of a
+// package into its mocks subpackage, without an external binary.
+package mockgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const mockPkgPath = "github.com/stretchr/testify/mock"
+
+// File is a generated mocks file
+type File struct {
+	// Package is the import path of the mocked package
+	Package string
+	// Path is the written file
+	Path string
+	// Mocks maps mock type names to the interfaces they implement
+	Mocks map[string]string
+	// Skipped lists interfaces that cannot be mocked from another package
+	Skipped []string
+}
+
+// Generate writes mocks/mocks.go for each package matching patterns in
+// dir that declares exported interfaces
+func Generate(dir string, patterns ...string) ([]File, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports |
+			packages.NeedDeps,
+		Dir: dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+
+	var files []File
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			return nil, fmt.Errorf("%s does not compile: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+		if pkg.Types == nil || len(pkg.GoFiles) == 0 || pkg.Name == "mocks" {
+			continue
+		}
+		file, src, ok, err := generate(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(file.Path), 0755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(file.Path, src, 0644); err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// generate renders the mocks of one package. ok is false when it has no
+// interfaces to mock.
+func generate(pkg *packages.Package) (file File, src []byte, ok bool, err error) {
+	file = File{
+		Package: pkg.PkgPath,
+		Path:    filepath.Join(filepath.Dir(pkg.GoFiles[0]), "mocks", "mocks.go"),
+		Mocks:   map[string]string{},
+	}
+
+	scope := pkg.Types.Scope()
+	var ifaces []*types.TypeName
+	for _, name := range scope.Names() {
+		obj, isType := scope.Lookup(name).(*types.TypeName)
+		if !isType || !obj.Exported() || obj.IsAlias() {
+			continue
+		}
+		named, isNamed := obj.Type().(*types.Named)
+		if !isNamed || named.TypeParams().Len() > 0 {
+			continue
+		}
+		iface, isIface := named.Underlying().(*types.Interface)
+		if !isIface || iface.NumMethods() == 0 || !iface.IsMethodSet() {
+			continue
+		}
+		if !exportable(iface, map[types.Type]bool{}) {
+			file.Skipped = append(file.Skipped, name)
+			continue
+		}
+		ifaces = append(ifaces, obj)
+	}
+	if len(ifaces) == 0 {
+		return file, nil, false, nil
+	}
+
+	g := &generator{imports: map[string]string{}, names: map[string]string{}}
+	g.importName(mockPkgPath, "mock")
+	names := mockNames(ifaces)
+
+	var body bytes.Buffer
+	for _, obj := range ifaces {
+		name := names[obj.Name()]
+		file.Mocks[name] = obj.Name()
+		g.writeMock(&body, name, obj, pkg.Types)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by meba g mocks. DO NOT EDIT.\n\n")
+	out.WriteString("// Package mocks provides testify mocks for the interfaces of " + pkg.Name + ".\n")
+	out.WriteString("package mocks\n\nimport (\n")
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if name := g.imports[path]; name != lastElem(path) {
+			fmt.Fprintf(&out, "\t%s %q\n", name, path)
+		} else {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	src, err = format.Source(out.Bytes())
+	if err != nil {
+		return file, nil, false, fmt.Errorf("failed to format mocks for %s: %w", pkg.PkgPath, err)
+	}
+	return file, src, true, nil
+}
+
+// mockNames names each mock after its interface without the Interface
+// suffix, e.g. ServiceInterface -> Service, unless that name is taken
+func mockNames(ifaces []*types.TypeName) map[string]string {
+	names := map[string]string{}
+	used := map[string]bool{}
+	for _, obj := range ifaces {
+		used[obj.Name()] = true
+	}
+	for _, obj := range ifaces {
+		name := strings.TrimSuffix(obj.Name(), "Interface")
+		if name == "" || (name != obj.Name() && used[name]) {
+			name = obj.Name()
+		}
+		used[name] = true
+		names[obj.Name()] = name
+	}
+	return names
+}
+
+// exportable reports whether t only refers to types another package can
+// name
+func exportable(t types.Type, seen map[types.Type]bool) bool {
+	if seen[t] {
+		return true
+	}
+	seen[t] = true
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && !obj.Exported() {
+			return false
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if !exportable(t.TypeArgs().At(i), seen) {
+				return false
+			}
+		}
+		return true
+	case *types.Alias:
+		return exportable(types.Unalias(t), seen)
+	case *types.Pointer:
+		return exportable(t.Elem(), seen)
+	case *types.Slice:
+		return exportable(t.Elem(), seen)
+	case *types.Array:
+		return exportable(t.Elem(), seen)
+	case *types.Chan:
+		return exportable(t.Elem(), seen)
+	case *types.Map:
+		return exportable(t.Key(), seen) && exportable(t.Elem(), seen)
+	case *types.Signature:
+		return exportable(t.Params(), seen) && exportable(t.Results(), seen)
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if !exportable(t.At(i).Type(), seen) {
+				return false
+			}
+		}
+		return true
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !t.Field(i).Exported() || !exportable(t.Field(i).Type(), seen) {
+				return false
+			}
+		}
+		return true
+	case *types.Interface:
+		for i := 0; i < t.NumMethods(); i++ {
+			m := t.Method(i)
+			if !m.Exported() || !exportable(m.Type(), seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+type generator struct {
+	// imports maps import paths to the names used in the file
+	imports map[string]string
+	// names maps names in use to their import paths
+	names map[string]string
+}
+
+// importName returns the name pkg is referred to by, adding the import
+func (g *generator) importName(path, name string) string {
+	if existing, ok := g.imports[path]; ok {
+		return existing
+	}
+	alias := name
+	for i := 2; g.names[alias] != "" || alias == "mocks"; i++ {
+		alias = fmt.Sprintf("%s%d", name, i)
+	}
+	g.imports[path] = alias
+	g.names[alias] = path
+	return alias
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	return g.importName(pkg.Path(), pkg.Name())
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) writeMock(w *bytes.Buffer, name string, obj *types.TypeName, pkg *types.Package) {
+	iface := obj.Type().Underlying().(*types.Interface)
+	ifaceName := g.importName(pkg.Path(), pkg.Name()) + "." + obj.Name()
+
+	fmt.Fprintf(w, "\n// %s is a mock of %s\n", name, ifaceName)
+	fmt.Fprintf(w, "type %s struct {\n\tmock.Mock\n}\n\n", name)
+	fmt.Fprintf(w, "var _ %s = (*%s)(nil)\n\n", ifaceName, name)
+	fmt.Fprintf(w, "// New%s creates a mock that asserts its expectations when the test\n// ends\n", name)
+	fmt.Fprintf(w, "func New%s(t interface {\n\tmock.TestingT\n\tCleanup(func())\n}) *%s {\n", name, name)
+	fmt.Fprintf(w, "\tm := &%s{}\n\tm.Mock.Test(t)\n\tt.Cleanup(func() { m.AssertExpectations(t) })\n\treturn m\n}\n", name)
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		g.writeMethod(w, name, ifaceName, iface.Method(i))
+	}
+}
+
+func (g *generator) writeMethod(w *bytes.Buffer, mockName, ifaceName string, method *types.Func) {
+	sig := method.Type().(*types.Signature)
+
+	// Resolve the types first so parameters cannot shadow the imports
+	params := make([]string, sig.Params().Len())
+	paramTypes := make([]string, sig.Params().Len())
+	for i := range params {
+		p := sig.Params().At(i)
+		if sig.Variadic() && i == len(params)-1 {
+			paramTypes[i] = "..." + g.typeString(p.Type().(*types.Slice).Elem())
+		} else {
+			paramTypes[i] = g.typeString(p.Type())
+		}
+	}
+	results := make([]string, sig.Results().Len())
+	for i := range results {
+		results[i] = g.typeString(sig.Results().At(i).Type())
+	}
+	for i := range params {
+		name := sig.Params().At(i).Name()
+		if g.reserved(name) {
+			name = fmt.Sprintf("p%d", i)
+		}
+		params[i] = name
+	}
+
+	var decl []string
+	for i := range params {
+		decl = append(decl, params[i]+" "+paramTypes[i])
+	}
+	resultDecl := strings.Join(results, ", ")
+	if len(results) > 1 {
+		resultDecl = "(" + resultDecl + ")"
+	}
+
+	fmt.Fprintf(w, "\n// %s mocks %s.%s\n", method.Name(), ifaceName, method.Name())
+	fmt.Fprintf(w, "func (m *%s) %s(%s) %s {\n", mockName, method.Name(), strings.Join(decl, ", "), resultDecl)
+	if len(results) == 0 {
+		fmt.Fprintf(w, "\tm.Called(%s)\n}\n", strings.Join(params, ", "))
+		return
+	}
+	fmt.Fprintf(w, "\targs := m.Called(%s)\n", strings.Join(params, ", "))
+	errorType := types.Universe.Lookup("error").Type()
+	var returns []string
+	for i, result := range results {
+		if types.Identical(sig.Results().At(i).Type(), errorType) {
+			returns = append(returns, fmt.Sprintf("args.Error(%d)", i))
+			continue
+		}
+		fmt.Fprintf(w, "\tr%d, _ := args.Get(%d).(%s)\n", i, i, result)
+		returns = append(returns, fmt.Sprintf("r%d", i))
+	}
+	fmt.Fprintf(w, "\treturn %s\n}\n", strings.Join(returns, ", "))
+}
+
+// reserved reports parameter names that would clash with the generated
+// method body: blanks, imports, the receiver and the locals
+func (g *generator) reserved(name string) bool {
+	switch {
+	case name == "" || name == "_" || name == "m" || name == "args":
+		return true
+	case g.names[name] != "":
+		return true
+	case len(name) > 1 && name[0] == 'r' && strings.Trim(name[1:], "0123456789") == "":
+		return true
+	}
+	return false
+}
+
+func lastElem(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,123 @@
+package mockgen
+
+import (
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("type-checks and builds a module")
+	}
+	dir := copyTestdata(t, filepath.Join("testdata", "app"))
+
+	files, err := Generate(dir, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	file := files[0]
+	if want := filepath.Join(dir, "store", "mocks", "mocks.go"); file.Path != want {
+		t.Errorf("path = %s, want %s", file.Path, want)
+	}
+	wantMocks := map[string]string{"Service": "ServiceInterface", "Repository": "Repository"}
+	if !reflect.DeepEqual(file.Mocks, wantMocks) {
+		t.Errorf("mocks = %v, want %v", file.Mocks, wantMocks)
+	}
+	if want := []string{"Hidden"}; !reflect.DeepEqual(file.Skipped, want) {
+		t.Errorf("skipped = %v, want %v", file.Skipped, want)
+	}
+
+	// The mocks compile against testify and implement their interfaces
+	check := `package check
+
+import (
+	"example.com/app/store"
+	"example.com/app/store/mocks"
+)
+
+var (
+	_ store.ServiceInterface = (*mocks.Service)(nil)
+	_ store.Repository       = (*mocks.Repository)(nil)
+)
+`
+	if err := os.MkdirAll(filepath.Join(dir, "check"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "check", "check.go"), []byte(check), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := goCommand(dir, "mod", "tidy"); err != nil {
+		t.Skipf("dependencies unavailable: %v\n%s", err, out)
+	}
+	if out, err := goCommand(dir, "vet", "./..."); err != nil {
+		t.Fatalf("mocks do not build: %v\n%s", err, out)
+	}
+}
+
+func TestMockNames(t *testing.T) {
+	tests := []struct {
+		ifaces []string
+		want   map[string]string
+	}{
+		{[]string{"ServiceInterface"}, map[string]string{"ServiceInterface": "Service"}},
+		{[]string{"Repository"}, map[string]string{"Repository": "Repository"}},
+		{
+			[]string{"Service", "ServiceInterface"},
+			map[string]string{"Service": "Service", "ServiceInterface": "ServiceInterface"},
+		},
+		{[]string{"Interface"}, map[string]string{"Interface": "Interface"}},
+	}
+	for _, tt := range tests {
+		var ifaces []*types.TypeName
+		for _, name := range tt.ifaces {
+			ifaces = append(ifaces, types.NewTypeName(token.NoPos, nil, name, nil))
+		}
+		if got := mockNames(ifaces); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mockNames(%v) = %v, want %v", tt.ifaces, got, tt.want)
+		}
+	}
+}
+
+// copyTestdata copies a testdata module to a temporary directory, so that
+// generating into it and tidying it leave the tree untouched
+func copyTestdata(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0644)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func goCommand(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
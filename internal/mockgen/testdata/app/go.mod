@@ -0,0 +1,5 @@
+module example.com/app
+
+go 1.21
+
+require github.com/stretchr/testify v1.8.4
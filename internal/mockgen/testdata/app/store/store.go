@@ -0,0 +1,32 @@
+package store
+
+import "context"
+
+type Item struct {
+	ID   int
+	Name string
+}
+
+// ServiceInterface is mocked as Service
+type ServiceInterface interface {
+	Get(ctx context.Context, id int) (*Item, error)
+	List(ctx context.Context, ids ...int) ([]Item, error)
+	Close()
+}
+
+// Repository keeps its name
+type Repository interface {
+	Save(item *Item) error
+}
+
+// Hidden refers to an unexported type, which the mocks package cannot name
+type Hidden interface {
+	Get() secret
+}
+
+type secret struct{}
+
+// Cache is generic and left out
+type Cache[T any] interface {
+	Get(key string) (T, bool)
+}
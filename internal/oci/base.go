@@ -0,0 +1,265 @@
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Base is the image new layers are added on top of
+type Base struct {
+	// Name is where the base was read from; empty for scratch
+	Name   string
+	Config ConfigFile
+	Layers []Layer
+}
+
+// Scratch returns the empty base image
+func Scratch(platform Platform) *Base {
+	return &Base{Config: ConfigFile{
+		OS:           platform.OS,
+		Architecture: platform.Architecture,
+		Variant:      platform.Variant,
+		RootFS:       RootFS{Type: "layers"},
+	}}
+}
+
+// LoadBase reads the image for platform from an OCI image layout, as a
+// directory or tarball, or from a docker save archive
+func LoadBase(file string, platform Platform) (*Base, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var r blobReader
+	if info.IsDir() {
+		r = func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(file, filepath.FromSlash(name)))
+		}
+	} else {
+		entries, err := readTar(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		r = func(name string) ([]byte, error) {
+			data, ok := entries[path.Clean(name)]
+			if !ok {
+				return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+			}
+			return data, nil
+		}
+	}
+
+	var base *Base
+	if _, err := r("index.json"); err == nil {
+		base, err = r.loadLayout(platform)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	} else {
+		base, err = r.loadDockerArchive(platform)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	base.Name = file
+	return base, nil
+}
+
+// blobReader reads a file of an image layout or archive
+type blobReader func(name string) ([]byte, error)
+
+func (r blobReader) blob(d Descriptor) ([]byte, error) {
+	alg, hex, ok := strings.Cut(d.Digest, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid digest %q", d.Digest)
+	}
+	data, err := r(path.Join("blobs", alg, hex))
+	if err != nil {
+		return nil, err
+	}
+	if digest(data) != d.Digest {
+		return nil, fmt.Errorf("blob %s does not match its digest", d.Digest)
+	}
+	return data, nil
+}
+
+func (r blobReader) json(d Descriptor, v any) error {
+	data, err := r.blob(d)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (r blobReader) loadLayout(platform Platform) (*Base, error) {
+	data, err := r("index.json")
+	if err != nil {
+		return nil, err
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid index.json: %w", err)
+	}
+	base, err := r.findManifest(index.Manifests, platform)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, fmt.Errorf("no image for %s", platform)
+	}
+	return base, nil
+}
+
+// findManifest returns the first image in descs, or in the indexes they
+// point to, that runs on platform
+func (r blobReader) findManifest(descs []Descriptor, platform Platform) (*Base, error) {
+	for _, d := range descs {
+		if d.Platform != nil && !matches(*d.Platform, platform) {
+			continue
+		}
+		switch d.MediaType {
+		case MediaTypeIndex, dockerMediaTypeList:
+			var index Index
+			if err := r.json(d, &index); err != nil {
+				return nil, err
+			}
+			base, err := r.findManifest(index.Manifests, platform)
+			if err != nil || base != nil {
+				return base, err
+			}
+		case MediaTypeManifest, dockerMediaTypeManifest:
+			var manifest Manifest
+			if err := r.json(d, &manifest); err != nil {
+				return nil, err
+			}
+			var config ConfigFile
+			if err := r.json(manifest.Config, &config); err != nil {
+				return nil, err
+			}
+			if !matches(Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}, platform) {
+				continue
+			}
+			if len(config.RootFS.DiffIDs) != len(manifest.Layers) {
+				return nil, fmt.Errorf("manifest %s has %d layers but %d diff_ids", d.Digest, len(manifest.Layers), len(config.RootFS.DiffIDs))
+			}
+			base := &Base{Config: config}
+			for i, l := range manifest.Layers {
+				data, err := r.blob(l)
+				if err != nil {
+					return nil, err
+				}
+				base.Layers = append(base.Layers, Layer{
+					MediaType: layerMediaType(l.MediaType, data),
+					Data:      data,
+					DiffID:    config.RootFS.DiffIDs[i],
+				})
+			}
+			return base, nil
+		}
+	}
+	return nil, nil
+}
+
+// dockerManifest is an entry of the manifest.json of docker save
+type dockerManifest struct {
+	Config   string
+	RepoTags []string
+	Layers   []string
+}
+
+func (r blobReader) loadDockerArchive(platform Platform) (*Base, error) {
+	data, err := r("manifest.json")
+	if err != nil {
+		return nil, errors.New("neither an OCI image layout nor a docker save archive")
+	}
+	var manifests []dockerManifest
+	if err := json.Unmarshal(data, &manifests); err != nil {
+		return nil, fmt.Errorf("invalid manifest.json: %w", err)
+	}
+
+	for _, m := range manifests {
+		data, err := r(m.Config)
+		if err != nil {
+			return nil, err
+		}
+		var config ConfigFile
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("invalid image config %s: %w", m.Config, err)
+		}
+		if !matches(Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant}, platform) {
+			continue
+		}
+		if len(config.RootFS.DiffIDs) != len(m.Layers) {
+			return nil, fmt.Errorf("image %s has %d layers but %d diff_ids", m.Config, len(m.Layers), len(config.RootFS.DiffIDs))
+		}
+		base := &Base{Config: config}
+		for i, name := range m.Layers {
+			data, err := r(name)
+			if err != nil {
+				return nil, err
+			}
+			base.Layers = append(base.Layers, Layer{
+				MediaType: layerMediaType("", data),
+				Data:      data,
+				DiffID:    config.RootFS.DiffIDs[i],
+			})
+		}
+		return base, nil
+	}
+	return nil, fmt.Errorf("no image for %s", platform)
+}
+
+func matches(have, want Platform) bool {
+	if have.OS != want.OS || have.Architecture != want.Architecture {
+		return false
+	}
+	return have.Variant == "" || want.Variant == "" || have.Variant == want.Variant
+}
+
+// layerMediaType returns the OCI media type of a layer, also for layers
+// of Docker images
+func layerMediaType(mediaType string, data []byte) string {
+	switch {
+	case strings.HasSuffix(mediaType, "zstd"):
+		return MediaTypeLayer + "+zstd"
+	case strings.HasSuffix(mediaType, "gzip"), len(data) > 1 && data[0] == 0x1f && data[1] == 0x8b:
+		return MediaTypeLayerGzip
+	}
+	return MediaTypeLayer
+}
+
+func readTar(file string) (map[string][]byte, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		entries[path.Clean(hdr.Name)] = data
+	}
+}
@@ -0,0 +1,109 @@
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Layer is a filesystem layer blob
+type Layer struct {
+	MediaType string
+	Data      []byte
+	// DiffID is the digest of the uncompressed tar
+	DiffID string
+	// CreatedBy describes the layer in the image history
+	CreatedBy string
+}
+
+// Digest returns the digest of the blob
+func (l Layer) Digest() string {
+	return digest(l.Data)
+}
+
+func (l Layer) descriptor() Descriptor {
+	return Descriptor{MediaType: l.MediaType, Digest: l.Digest(), Size: int64(len(l.Data))}
+}
+
+// File is an entry of a layer built with NewLayer
+type File struct {
+	// Path inside the image, without the leading slash
+	Path string
+	// Mode holds the permissions, and fs.ModeDir for directories
+	Mode fs.FileMode
+	Data []byte
+	UID  int
+	GID  int
+}
+
+// NewLayer builds an uncompressed layer from files. Missing parent
+// directories are added, entries are sorted and stamped with mtime, so
+// the same files always give the same digest.
+func NewLayer(files []File, mtime time.Time, createdBy string) (Layer, error) {
+	entries := map[string]File{}
+	for _, f := range files {
+		name := strings.Trim(path.Clean("/"+f.Path), "/")
+		if name == "" {
+			return Layer{}, fmt.Errorf("invalid layer path %q", f.Path)
+		}
+		f.Path = name
+		entries[name] = f
+		for dir := path.Dir(name); dir != "."; dir = path.Dir(dir) {
+			if _, ok := entries[dir]; !ok {
+				entries[dir] = File{Path: dir, Mode: fs.ModeDir | 0755}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		f := entries[name]
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    int64(f.Mode.Perm()),
+			Uid:     f.UID,
+			Gid:     f.GID,
+			ModTime: mtime,
+			Format:  tar.FormatPAX,
+		}
+		if f.Mode&fs.ModeSticky != 0 {
+			hdr.Mode |= 01000
+		}
+		if f.Mode.IsDir() {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		} else {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = int64(len(f.Data))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return Layer{}, err
+		}
+		if _, err := tw.Write(f.Data); err != nil {
+			return Layer{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Layer{}, err
+	}
+
+	data := buf.Bytes()
+	return Layer{
+		MediaType: MediaTypeLayer,
+		Data:      data,
+		DiffID:    digest(data),
+		CreatedBy: createdBy,
+	}, nil
+}
@@ -0,0 +1,205 @@
+package oci
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Image is a base image with layers added on top
+type Image struct {
+	// Ref is the name:tag the image is loaded as
+	Ref    string
+	Base   *Base
+	Layers []Layer
+	// Config is the runtime configuration. Env is added to the one of the
+	// base, and empty fields keep the values of the base.
+	Config  Config
+	Created time.Time
+}
+
+// WriteTar writes the image as an OCI layout tarball that docker load and
+// podman load accept, and returns the digest of its manifest
+func (img *Image) WriteTar(file string) (string, error) {
+	created := img.Created.UTC().Format(time.RFC3339)
+	config := img.configFile(created)
+
+	configData, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	configDesc := Descriptor{MediaType: MediaTypeConfig, Digest: digest(configData), Size: int64(len(configData))}
+
+	layers := append(append([]Layer{}, img.Base.Layers...), img.Layers...)
+	manifest := Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeManifest,
+		Config:        configDesc,
+		Layers:        []Descriptor{},
+		Annotations:   map[string]string{"org.opencontainers.image.created": created},
+	}
+	for _, l := range layers {
+		manifest.Layers = append(manifest.Layers, l.descriptor())
+	}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return "", err
+	}
+	manifestDigest := digest(manifestData)
+
+	_, tag := splitRef(img.Ref)
+	index := Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeIndex,
+		Manifests: []Descriptor{{
+			MediaType: MediaTypeManifest,
+			Digest:    manifestDigest,
+			Size:      int64(len(manifestData)),
+			Platform:  &Platform{OS: config.OS, Architecture: config.Architecture, Variant: config.Variant},
+			Annotations: map[string]string{
+				"io.containerd.image.name":          img.Ref,
+				"org.opencontainers.image.ref.name": tag,
+			},
+		}},
+	}
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return "", err
+	}
+
+	// docker load before Docker 25 only reads manifest.json
+	docker := []dockerManifest{{Config: blobPath(configDesc.Digest), RepoTags: []string{img.Ref}}}
+	for _, l := range layers {
+		docker[0].Layers = append(docker[0].Layers, blobPath(l.Digest()))
+	}
+	dockerData, err := json.Marshal(docker)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	write := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: img.Created, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	type entry struct {
+		name string
+		data []byte
+	}
+	files := []entry{
+		{"oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)},
+		{"index.json", indexData},
+		{"manifest.json", dockerData},
+		{blobPath(configDesc.Digest), configData},
+		{blobPath(manifestDigest), manifestData},
+	}
+	seen := map[string]bool{}
+	for _, l := range layers {
+		if name := blobPath(l.Digest()); !seen[name] {
+			seen[name] = true
+			files = append(files, entry{name, l.Data})
+		}
+	}
+	for _, file := range files {
+		if err := write(file.name, file.data); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	return manifestDigest, f.Close()
+}
+
+func (img *Image) configFile(created string) ConfigFile {
+	config := img.Base.Config
+	config.Created = created
+	config.RootFS = RootFS{Type: "layers", DiffIDs: append([]string{}, config.RootFS.DiffIDs...)}
+	config.History = append([]History{}, config.History...)
+	for _, l := range img.Layers {
+		config.RootFS.DiffIDs = append(config.RootFS.DiffIDs, l.DiffID)
+		config.History = append(config.History, History{Created: created, CreatedBy: l.CreatedBy})
+	}
+
+	c := img.Config
+	base := config.Config
+	if c.User != "" {
+		base.User = c.User
+	}
+	if len(c.ExposedPorts) > 0 {
+		base.ExposedPorts = c.ExposedPorts
+	}
+	base.Env = mergeEnv(base.Env, c.Env)
+	if len(c.Entrypoint) > 0 {
+		base.Entrypoint = c.Entrypoint
+		base.Cmd = c.Cmd
+	}
+	if c.WorkingDir != "" {
+		base.WorkingDir = c.WorkingDir
+	}
+	if len(c.Labels) > 0 {
+		labels := map[string]string{}
+		for k, v := range base.Labels {
+			labels[k] = v
+		}
+		for k, v := range c.Labels {
+			labels[k] = v
+		}
+		base.Labels = labels
+	}
+	if c.Healthcheck != nil {
+		base.Healthcheck = c.Healthcheck
+	}
+	config.Config = base
+	return config
+}
+
+// mergeEnv overrides the KEY=value pairs of base with those of env
+func mergeEnv(base, env []string) []string {
+	merged := append([]string{}, base...)
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		replaced := false
+		for i, existing := range merged {
+			if k, _, _ := strings.Cut(existing, "="); k == key {
+				merged[i] = kv
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
+
+// splitRef splits name:tag, defaulting the tag to latest
+func splitRef(ref string) (name, tag string) {
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, "latest"
+}
+
+func blobPath(d string) string {
+	alg, hex, _ := strings.Cut(d, ":")
+	return "blobs/" + alg + "/" + hex
+}
@@ -0,0 +1,242 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/analyzer"
+)
+
+// Build converts the analyzed routes of a project into an OpenAPI document.
+// Routes served by handlers outside the project (e.g. the Swagger UI) are
+// left out.
+func Build(p *analyzer.Project, info Info, servers []string) *Document {
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+	for _, url := range servers {
+		doc.Servers = append(doc.Servers, Server{URL: url})
+	}
+
+	tags := map[string]bool{}
+	for _, route := range p.Routes {
+		h := route.Handler
+		if h == nil || h.Position.Filename == "" {
+			continue
+		}
+
+		op := &Operation{
+			OperationID: operationID(route),
+			Summary:     h.Summary(),
+			Description: h.Description(),
+			Tags:        []string{h.Package},
+			Responses:   map[string]*Response{},
+		}
+		if h.Func != "" {
+			op.Handler = h.Name
+		}
+		tags[h.Package] = true
+
+		for _, name := range analyzer.PathParams(route.Path) {
+			param := &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+			for _, hp := range h.PathParams {
+				if hp.Name == name {
+					param.Schema = fieldSchema(hp.Type, hp.Rules)
+					param.Description = hp.Description
+				}
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+		for _, qp := range h.QueryParams {
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:        qp.Name,
+				In:          "query",
+				Description: qp.Description,
+				Required:    qp.Required,
+				Schema:      fieldSchema(qp.Type, qp.Rules),
+			})
+		}
+
+		if h.Body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: typeSchema(h.Body)}},
+			}
+		}
+
+		for _, resp := range h.Responses {
+			code := strconv.Itoa(resp.Status)
+			r, ok := op.Responses[code]
+			if !ok {
+				r = &Response{Description: http.StatusText(resp.Status)}
+				op.Responses[code] = r
+			}
+			if resp.Body == nil {
+				continue
+			}
+			schema := typeSchema(resp.Body)
+			if existing, ok := r.Content["application/json"]; ok {
+				existing.Schema = mergeAlternatives(existing.Schema, schema)
+				r.Content["application/json"] = existing
+			} else {
+				r.Content = map[string]MediaType{"application/json": {Schema: schema}}
+			}
+		}
+		if len(op.Responses) == 0 {
+			op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+		}
+
+		methods := []string{strings.ToLower(route.Method)}
+		if route.Method == "ANY" {
+			methods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+		}
+		path := Path(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, m := range methods {
+			item[m] = op
+		}
+	}
+
+	for name, model := range p.Models {
+		doc.Components.Schemas[name] = modelSchema(model)
+	}
+
+	for tag := range tags {
+		doc.Tags = append(doc.Tags, Tag{Name: tag})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+
+	return doc
+}
+
+// Path converts a gin route path to an OpenAPI path template:
+// /users/:id becomes /users/{id}
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID derives the ID of a route from its method and path, e.g.
+// getApiV1OrdersById for GET /api/v1/orders/:id. Gin allows one handler
+// per method and path, so the IDs are unique and stay stable when handlers
+// are renamed.
+func operationID(route *analyzer.Route) string {
+	id := strings.ToLower(route.Method)
+	for _, segment := range strings.Split(route.Path, "/") {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			id += "By"
+			segment = segment[1:]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		}) {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+func modelSchema(m *analyzer.Model) *Schema {
+	s := objectSchema(m.Fields)
+	s.Description = strings.TrimSpace(m.Doc)
+	return s
+}
+
+func objectSchema(fields []*analyzer.Field) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fields {
+		s.Properties[f.JSONName] = fieldSchema(f.Type, f.Rules)
+		if f.Required {
+			s.Required = append(s.Required, f.JSONName)
+		}
+	}
+	return s
+}
+
+// SchemaRef returns the component reference for a model name
+func SchemaRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+func typeSchema(t *analyzer.TypeRef) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	var s *Schema
+	switch t.Kind {
+	case analyzer.KindRef:
+		return &Schema{Ref: SchemaRef(t.Ref)}
+	case analyzer.KindObject:
+		s = objectSchema(t.Properties)
+	case analyzer.KindArray:
+		s = &Schema{Type: "array", Items: typeSchema(t.Elem)}
+	case analyzer.KindMap:
+		s = &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem)}
+	case analyzer.KindAny:
+		s = &Schema{}
+	default:
+		s = &Schema{Type: string(t.Kind), Format: t.Format}
+	}
+	for _, v := range t.Enum {
+		s.Enum = append(s.Enum, v)
+	}
+	if t.Nullable && s.Type != nil {
+		s.Type = []string{s.Type.(string), "null"}
+	}
+	return s
+}
+
+// fieldSchema is typeSchema plus the field's validator rules
+func fieldSchema(t *analyzer.TypeRef, rules []analyzer.Rule) *Schema {
+	s := typeSchema(t)
+	if len(rules) == 0 {
+		return s
+	}
+	if s.Ref != "" {
+		// $ref siblings are allowed in 3.1, but keep refs clean and
+		// wrap constrained references instead
+		s = &Schema{OneOf: []*Schema{s}}
+	}
+	kind := analyzer.KindAny
+	if t != nil {
+		kind = t.Kind
+	}
+	for _, r := range rules {
+		applyRule(s, kind, r)
+	}
+	return s
+}
+
+// mergeAlternatives combines two bodies written with the same status code
+func mergeAlternatives(a, b *Schema) *Schema {
+	if sameSchema(a, b) {
+		return a
+	}
+	if a.OneOf != nil && a.Type == nil && a.Ref == "" {
+		a.OneOf = append(a.OneOf, b)
+		return a
+	}
+	return &Schema{OneOf: []*Schema{a, b}}
+}
+
+func sameSchema(a, b *Schema) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/samuel-k-w/meba-cli/internal/analyzer"
+)
+
+// Validator rules that map directly onto a JSON Schema format or pattern
+var (
+	ruleFormats = map[string]string{
+		"email":    "email",
+		"url":      "uri",
+		"uri":      "uri",
+		"uuid":     "uuid",
+		"uuid4":    "uuid",
+		"datetime": "date-time",
+		"ipv4":     "ipv4",
+		"ipv6":     "ipv6",
+		"hostname": "hostname",
+	}
+	rulePatterns = map[string]string{
+		"alpha":     "^[a-zA-Z]+$",
+		"alphanum":  "^[a-zA-Z0-9]+$",
+		"numeric":   "^[-+]?[0-9]+(?:\\.[0-9]+)?$",
+		"number":    "^[0-9]+$",
+		"e164":      "^\\+[1-9]?[0-9]{7,14}$",
+		"lowercase": "^[^A-Z]*$",
+		"uppercase": "^[^a-z]*$",
+	}
+)
+
+// applyRule translates one go-playground/validator rule into JSON Schema
+// keywords. Rules without an equivalent are kept under x-validations.
+func applyRule(s *Schema, kind analyzer.Kind, r analyzer.Rule) {
+	if format, ok := ruleFormats[r.Name]; ok {
+		s.Format = format
+		return
+	}
+	if pattern, ok := rulePatterns[r.Name]; ok {
+		s.Pattern = pattern
+		return
+	}
+
+	n, numErr := strconv.ParseFloat(r.Param, 64)
+	isNumber := kind == analyzer.KindInteger || kind == analyzer.KindNumber
+	isString := kind == analyzer.KindString
+	isList := kind == analyzer.KindArray
+
+	switch {
+	case r.Name == "oneof":
+		for _, v := range strings.Fields(r.Param) {
+			if isNumber {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					s.Enum = append(s.Enum, f)
+					continue
+				}
+			}
+			s.Enum = append(s.Enum, v)
+		}
+		return
+	case numErr != nil:
+		// fall through to x-validations
+	case isNumber:
+		switch r.Name {
+		case "min", "gte":
+			s.Minimum = &n
+			return
+		case "max", "lte":
+			s.Maximum = &n
+			return
+		case "gt":
+			s.ExclusiveMinimum = &n
+			return
+		case "lt":
+			s.ExclusiveMaximum = &n
+			return
+		case "eq", "len":
+			s.Minimum, s.Maximum = &n, &n
+			return
+		}
+	case isString || isList:
+		length := int(n)
+		minPtr, maxPtr := &s.MinLength, &s.MaxLength
+		if isList {
+			minPtr, maxPtr = &s.MinItems, &s.MaxItems
+		}
+		switch r.Name {
+		case "min", "gte":
+			*minPtr = &length
+			return
+		case "max", "lte":
+			*maxPtr = &length
+			return
+		case "gt":
+			l := length + 1
+			*minPtr = &l
+			return
+		case "lt":
+			l := length - 1
+			*maxPtr = &l
+			return
+		case "len":
+			*minPtr, *maxPtr = &length, &length
+			return
+		}
+	}
+
+	v := r.Name
+	if r.Param != "" {
+		v += "=" + r.Param
+	}
+	s.Validations = append(s.Validations, v)
+}
@@ -0,0 +1,811 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Cache drivers of meba add cache
+const (
+	CacheDriverMemory = "memory"
+	CacheDriverRedis  = "redis"
+)
+
+// CacheDrivers are the values accepted by meba add cache --driver
+var CacheDrivers = []string{CacheDriverMemory, CacheDriverRedis}
+
+// CacheGo is pkg/cache: the Cache interface, its driver registry and the
+// read-through helper
+func CacheGo(moduleName string) string {
+	return fmt.Sprintf(`// Package cache stores values by key in the driver selected by
+// cache.driver: memory, or redis when it was added with meba add cache
+// --driver redis. Remember reads through it, Middleware caches GET
+// responses.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"%[1]s/configs"
+	"%[1]s/pkg/logging"
+)
+
+// ErrMiss is returned by Get for a key that is not cached or has expired
+var ErrMiss = errors.New("cache: miss")
+
+// Cache stores values by key. A ttl of 0 or less stands for the
+// cache.default_ttl of the config.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	// DeletePrefix removes every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+	Close() error
+}
+
+// Driver opens a cache from the cache and redis sections of the config
+type Driver func(cfg configs.CacheConfig, redis configs.RedisConfig) (Cache, error)
+
+var drivers = map[string]Driver{}
+
+// Register makes a driver available to New under name. Call it from an
+// init function, like memory.go and redis.go.
+func Register(name string, driver Driver) {
+	drivers[name] = driver
+}
+
+// New opens the cache of the driver named by cfg.Driver, memory when it is
+// empty
+func New(cfg configs.CacheConfig, redis configs.RedisConfig) (Cache, error) {
+	name := cfg.Driver
+	if name == "" {
+		name = "memory"
+	}
+	driver, ok := drivers[name]
+	if !ok {
+		return nil, fmt.Errorf("cache driver %%q is not registered; meba add cache --driver redis adds redis", name)
+	}
+	return driver(cfg, redis)
+}
+
+// Remember returns the value cached under key, or the result of load,
+// which is cached for ttl when load succeeds. Values round-trip through
+// JSON, so fields tagged json:"-" come back empty. When the cache fails
+// the error is logged and load is called, so an outage only costs latency.
+func Remember[T any](ctx context.Context, c Cache, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	logger := logging.LoggerFromContext(ctx)
+	data, err := c.Get(ctx, key)
+	if err == nil {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+		logger.Warn("Cached value is corrupt", zap.String("key", key), zap.Error(err))
+	} else if !errors.Is(err, ErrMiss) {
+		logger.Warn("Cache read failed", zap.String("key", key), zap.Error(err))
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	if data, err = json.Marshal(value); err == nil {
+		err = c.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		logger.Warn("Cache write failed", zap.String("key", key), zap.Error(err))
+	}
+	return value, nil
+}
+
+// Hash returns a short key for values such as the arguments of a list
+// call, e.g. "orders:list:" + Hash(page, pageSize, q)
+func Hash(values ...interface{}) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%%#v", values))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+`, moduleName)
+}
+
+// CacheMemoryGo is the memory driver of pkg/cache
+func CacheMemoryGo(moduleName string) string {
+	return fmt.Sprintf(`package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"%s/configs"
+)
+
+func init() {
+	Register("memory", func(cfg configs.CacheConfig, _ configs.RedisConfig) (Cache, error) {
+		return NewMemory(cfg.DefaultTTL, cfg.CleanupInterval), nil
+	})
+}
+
+// Memory is a Cache in the memory of the process, for a single instance
+// and for tests. Expired entries are removed every cleanup interval.
+type Memory struct {
+	mu         sync.RWMutex
+	items      map[string]entry
+	defaultTTL time.Duration
+	stop       chan struct{}
+	closeOnce  sync.Once
+}
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// NewMemory creates an in-memory cache. Entries without a ttl live for
+// defaultTTL, or until deleted when it is 0. A cleanupInterval of 0 only
+// drops expired entries when they are read.
+func NewMemory(defaultTTL, cleanupInterval time.Duration) *Memory {
+	m := &Memory{
+		items:      map[string]entry{},
+		defaultTTL: defaultTTL,
+		stop:       make(chan struct{}),
+	}
+	if cleanupInterval > 0 {
+		go m.cleanup(cleanupInterval)
+	}
+	return m
+}
+
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	e, ok := m.items[key]
+	m.mu.RUnlock()
+	if !ok || e.expired(time.Now()) {
+		return nil, ErrMiss
+	}
+	return append([]byte(nil), e.value...), nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = m.defaultTTL
+	}
+	e := entry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+	m.mu.Lock()
+	m.items[key] = e
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	for _, key := range keys {
+		delete(m.items, key)
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	for key := range m.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.items, key)
+		}
+	}
+	m.mu.Unlock()
+	return nil
+}
+
+// Close stops the cleanup of expired entries
+func (m *Memory) Close() error {
+	m.closeOnce.Do(func() { close(m.stop) })
+	return nil
+}
+
+func (m *Memory) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case now := <-ticker.C:
+			m.mu.Lock()
+			for key, e := range m.items {
+				if e.expired(now) {
+					delete(m.items, key)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+`, moduleName)
+}
+
+// CacheRedisGo is the redis driver of pkg/cache, added by meba add cache
+// --driver redis
+func CacheRedisGo(moduleName string) string {
+	return fmt.Sprintf(`package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"%s/configs"
+)
+
+// deleteBatch is the number of keys DeletePrefix scans and deletes at once
+const deleteBatch = 100
+
+func init() {
+	Register("redis", openRedis)
+}
+
+// Redis is a Cache in Redis, shared by every instance of the app
+type Redis struct {
+	client     *redis.Client
+	defaultTTL time.Duration
+}
+
+// NewRedis creates a cache on client. Entries without a ttl live for
+// defaultTTL, or until deleted when it is 0.
+func NewRedis(client *redis.Client, defaultTTL time.Duration) *Redis {
+	return &Redis{client: client, defaultTTL: defaultTTL}
+}
+
+// openRedis connects to the server of the redis section and pings it
+func openRedis(cfg configs.CacheConfig, rc configs.RedisConfig) (Cache, error) {
+	addr := fmt.Sprintf("%%s:%%d", rc.Host, rc.Port)
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: rc.Password,
+		DB:       rc.DB,
+		PoolSize: rc.PoolSize,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to redis at %%s: %%w", addr, err)
+	}
+	return NewRedis(client, cfg.DefaultTTL), nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return value, err
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = r.defaultTTL
+	}
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// DeletePrefix scans for the keys starting with prefix, so its cost grows
+// with the number of keys in the database
+func (r *Redis) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := r.client.Scan(ctx, 0, escapePattern(prefix)+"*", deleteBatch).Iterator()
+	keys := make([]string, 0, deleteBatch)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == deleteBatch {
+			if err := r.Delete(ctx, keys...); err != nil {
+				return err
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return r.Delete(ctx, keys...)
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// escapePattern escapes the glob characters of a SCAN MATCH pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`+"`*?[]\\`"+`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+`, moduleName)
+}
+
+// CacheMiddlewareGo is the response cache of pkg/cache for GET routes
+func CacheMiddlewareGo() string {
+	return `package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responsePrefix starts the keys of cached responses
+const responsePrefix = "response:"
+
+type response struct {
+	Status      int    ` + "`json:\"status\"`" + `
+	ContentType string ` + "`json:\"content_type\"`" + `
+	Body        []byte ` + "`json:\"body\"`" + `
+}
+
+// Middleware caches the 200 responses of GET requests for ttl, keyed by
+// their path and query, and marks responses with X-Cache: HIT or MISS.
+// Requests with an Authorization header or Cache-Control: no-cache, and
+// responses setting cookies, bypass it. Add it to the routes to cache,
+// e.g.
+//
+//	group.GET("", cache.Middleware(c, time.Minute), h.GetAll)
+func Middleware(c Cache, ttl time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet || ctx.GetHeader("Authorization") != "" ||
+			strings.Contains(ctx.GetHeader("Cache-Control"), "no-cache") {
+			ctx.Next()
+			return
+		}
+
+		key := responseKey(ctx.Request)
+		if data, err := c.Get(ctx.Request.Context(), key); err == nil {
+			var cached response
+			if json.Unmarshal(data, &cached) == nil {
+				ctx.Header("X-Cache", "HIT")
+				ctx.Data(cached.Status, cached.ContentType, cached.Body)
+				ctx.Abort()
+				return
+			}
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Header("X-Cache", "MISS")
+		ctx.Next()
+
+		if recorder.Status() != http.StatusOK || len(ctx.Errors) > 0 || recorder.Header().Get("Set-Cookie") != "" {
+			return
+		}
+		data, err := json.Marshal(response{
+			Status:      recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if err == nil {
+			_ = c.Set(ctx.Request.Context(), key, data, ttl)
+		}
+	}
+}
+
+// PurgeResponses removes the cached responses of path and of the paths
+// under it, e.g. after a write to the resource they list: purging
+// /api/v1/user drops /api/v1/user and /api/v1/user/3, not /api/v1/users
+func PurgeResponses(ctx context.Context, c Cache, path string) error {
+	path = strings.TrimSuffix(path, "/")
+	// Keys are the path followed by ? and the query
+	if err := c.DeletePrefix(ctx, responsePrefix+path+"?"); err != nil {
+		return err
+	}
+	return c.DeletePrefix(ctx, responsePrefix+path+"/")
+}
+
+// responseKey is the path and the sorted query of r
+func responseKey(r *http.Request) string {
+	return responsePrefix + r.URL.Path + "?" + r.URL.Query().Encode()
+}
+
+// bodyRecorder keeps a copy of the body written to the client
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+`
+}
+
+// AppCacheGo is internal/cache.go, which provides the cache to wire
+func AppCacheGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
+
+import (
+	"github.com/google/wire"
+	"%[1]s/configs"
+	"%[1]s/pkg/cache"
+)
+
+// CacheSet provides the cache selected by cache.driver in
+// configs/config.yaml. Like DatabaseSet it is not part of AppSet, so
+// InitializeTestApp can use MemoryCacheSet instead.
+var CacheSet = wire.NewSet(
+	NewCache,
+)
+
+// MemoryCacheSet provides an in-memory cache, for the e2e tests
+var MemoryCacheSet = wire.NewSet(
+	NewMemoryCache,
+)
+
+// NewCache opens the cache configured by the cache and redis sections of
+// the config. The app closes it on shutdown.
+func NewCache(cfg *configs.Config) (cache.Cache, error) {
+	return cache.New(cfg.Cache, cfg.Redis)
+}
+
+// NewMemoryCache creates an in-memory cache whose entries do not expire
+func NewMemoryCache() cache.Cache {
+	return cache.NewMemory(0, 0)
+}
+`, moduleName)
+}
+
+// AppCacheShutdown is inserted into NewApp in internal/app.go so the app
+// closes the cache
+func AppCacheShutdown() string {
+	return `	hooks.OnShutdown("cache", func(ctx context.Context) error {
+		return c.Close()
+	})
+`
+}
+
+// CachedServiceGo is the read-through cache of a resource service,
+// generated by meba g resource --cache
+func CachedServiceGo(moduleName, name, pagination string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package %[1]s
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"%[3]s/pkg/cache"
+	"%[3]s/pkg/logging"
+	"%[3]s/pkg/query"
+)
+
+// cacheTTL is how long GetByID and GetAll results are cached; 0 is the
+// cache.default_ttl of the config
+const cacheTTL time.Duration = 0
+
+// Keys of the cached %[1]s results
+const (
+	cacheItemPrefix = "%[1]s:id:"
+	cacheListPrefix = "%[1]s:list:"
+)
+
+// CachedService is the ServiceInterface bound in module.go: it caches the
+// results of GetByID and GetAll of Service, and Create, Update and Delete
+// invalidate them along with the cached responses of the routes. Cached
+// values round-trip through JSON.
+type CachedService struct {
+	service *Service
+	cache   cache.Cache
+	// routesPath is where SetupRoutes mounted the %[1]s routes
+	routesPath string
+}
+
+var _ ServiceInterface = (*CachedService)(nil)
+
+// NewCachedService creates the cache in front of service
+func NewCachedService(service *Service, c cache.Cache) *CachedService {
+	return &CachedService{
+		service: service,
+		cache:   c,
+	}
+}
+
+// MountedAt records the path SetupRoutes mounted the %[1]s routes at, so
+// writes purge the responses cache.Middleware cached under it
+func (s *CachedService) MountedAt(path string) {
+	s.routesPath = path
+}
+
+%[4]s
+// GetByID retrieves a %[1]s by ID through the cache. Errors such as
+// ErrNotFound are not cached.
+func (s *CachedService) GetByID(ctx context.Context, id uint) (*%[2]s, error) {
+	return cache.Remember(ctx, s.cache, cacheItemKey(id), cacheTTL, func() (*%[2]s, error) {
+		return s.service.GetByID(ctx, id)
+	})
+}
+
+// Create creates a %[1]s and invalidates the cached lists
+func (s *CachedService) Create(ctx context.Context, req *Create%[2]sRequest) (*%[2]s, error) {
+	item, err := s.service.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return item, nil
+}
+
+// Update updates a %[1]s and invalidates it and the cached lists
+func (s *CachedService) Update(ctx context.Context, id uint, req *Update%[2]sRequest) (*%[2]s, error) {
+	item, err := s.service.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx, cacheItemKey(id))
+	return item, nil
+}
+
+// Delete deletes a %[1]s and invalidates it and the cached lists
+func (s *CachedService) Delete(ctx context.Context, id uint) error {
+	if err := s.service.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, cacheItemKey(id))
+	return nil
+}
+
+// invalidate removes keys, every cached list and the cached responses of
+// the routes. A failure is logged; the stale entries expire after their ttl.
+func (s *CachedService) invalidate(ctx context.Context, keys ...string) {
+	logger := logging.LoggerFromContext(ctx)
+	if err := s.cache.Delete(ctx, keys...); err != nil {
+		logger.Warn("Failed to invalidate cached %[1]s", zap.Strings("keys", keys), zap.Error(err))
+	}
+	if err := s.cache.DeletePrefix(ctx, cacheListPrefix); err != nil {
+		logger.Warn("Failed to invalidate cached %[1]s lists", zap.Error(err))
+	}
+	if s.routesPath == "" {
+		return
+	}
+	if err := cache.PurgeResponses(ctx, s.cache, s.routesPath); err != nil {
+		logger.Warn("Failed to purge cached %[1]s responses", zap.Error(err))
+	}
+}
+
+func cacheItemKey(id uint) string {
+	return cacheItemPrefix + strconv.FormatUint(uint64(id), 10)
+}
+`, name, titleName, moduleName, cachedGetAllGo(name, pagination))
+}
+
+// cachedGetAllGo is the list method of a cached service, keyed by its
+// arguments
+func cachedGetAllGo(name, pagination string) string {
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`// GetAll retrieves a page of %[1]s through the cache
+func (s *CachedService) GetAll(ctx context.Context, cursor string, limit int, q query.Query) (*CursorResponse, error) {
+	key := cacheListPrefix + cache.Hash(cursor, limit, q)
+	return cache.Remember(ctx, s.cache, key, cacheTTL, func() (*CursorResponse, error) {
+		return s.service.GetAll(ctx, cursor, limit, q)
+	})
+}
+`, name)
+	}
+	return fmt.Sprintf(`// GetAll retrieves a page of %[1]s through the cache
+func (s *CachedService) GetAll(ctx context.Context, page, pageSize int, q query.Query) (*PaginationResponse, error) {
+	key := cacheListPrefix + cache.Hash(page, pageSize, q)
+	return cache.Remember(ctx, s.cache, key, cacheTTL, func() (*PaginationResponse, error) {
+		return s.service.GetAll(ctx, page, pageSize, q)
+	})
+}
+`, name)
+}
+
+// CachedServiceTestGo tests the cached service of a resource on the
+// memory driver
+func CachedServiceTestGo(moduleName, name, pagination string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package %[1]s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"%[3]s/internal/%[1]s"
+	"%[3]s/internal/%[1]s/mocks"
+	"%[3]s/pkg/cache"
+	"%[3]s/pkg/query"
+	"%[3]s/pkg/validator"
+)
+
+func newCached%[2]sService(t *testing.T) (*%[1]s.CachedService, *mocks.Repository) {
+	repo := mocks.NewRepository(t)
+	c := cache.NewMemory(time.Minute, 0)
+	t.Cleanup(func() { c.Close() })
+	return %[1]s.NewCachedService(%[1]s.New%[2]sService(repo), c), repo
+}
+
+func Test%[2]sCachedService_GetByID(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCached%[2]sService(t)
+	repo.On("GetByID", mock.Anything, uint(1)).Return(&%[1]s.%[2]s{ID: 1}, nil).Once()
+
+	for i := 0; i < 2; i++ {
+		item, err := service.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), item.ID)
+	}
+}
+
+func Test%[2]sCachedService_GetByIDMissingNotCached(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCached%[2]sService(t)
+	repo.On("GetByID", mock.Anything, uint(42)).Return(nil, gorm.ErrRecordNotFound).Twice()
+
+	for i := 0; i < 2; i++ {
+		_, err := service.GetByID(ctx, 42)
+		assert.ErrorIs(t, err, %[1]s.ErrNotFound)
+	}
+}
+
+func Test%[2]sCachedService_UpdateInvalidates(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCached%[2]sService(t)
+	// Read, the lookup of Update, then the read after the invalidation
+	repo.On("GetByID", mock.Anything, uint(3)).Return(&%[1]s.%[2]s{ID: 3}, nil).Times(3)
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(nil).Once()
+
+	_, err := service.GetByID(ctx, 3)
+	require.NoError(t, err)
+	_, err = service.GetByID(ctx, 3)
+	require.NoError(t, err)
+
+	_, err = service.Update(ctx, 3, &%[1]s.Update%[2]sRequest{})
+	require.NoError(t, err)
+
+	_, err = service.GetByID(ctx, 3)
+	require.NoError(t, err)
+}
+
+func Test%[2]sCachedService_DeleteInvalidates(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCached%[2]sService(t)
+	repo.On("GetByID", mock.Anything, uint(3)).Return(&%[1]s.%[2]s{ID: 3}, nil).Once()
+	repo.On("Delete", mock.Anything, uint(3)).Return(nil).Once()
+	repo.On("GetByID", mock.Anything, uint(3)).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	_, err := service.GetByID(ctx, 3)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Delete(ctx, 3))
+
+	_, err = service.GetByID(ctx, 3)
+	assert.ErrorIs(t, err, %[1]s.ErrNotFound)
+}
+
+func Test%[2]sCachedService_CreateInvalidatesLists(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newCached%[2]sService(t)
+%[4]s	repo.On("Create", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(nil).Once()
+
+	require.NoError(t, list())
+	require.NoError(t, list())
+
+	_, err := service.Create(ctx, &%[1]s.Create%[2]sRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, list())
+}
+
+func Test%[2]sCachedService_UpdatePurgesResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	validator.Setup()
+	repo := mocks.NewRepository(t)
+	c := cache.NewMemory(time.Minute, 0)
+	t.Cleanup(func() { c.Close() })
+	router := gin.New()
+	router.Use(cache.Middleware(c, time.Minute))
+	service := %[1]s.NewCachedService(%[1]s.New%[2]sService(repo), c)
+	// Writes purge the responses under wherever the routes are mounted
+	%[1]s.New%[2]sHandlers(service).SetupRoutes(router.Group("/v2"))
+
+	stale := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fresh := stale.Add(time.Hour)
+	// The first read and the lookup of Update, then the read after it
+	repo.On("GetByID", mock.Anything, uint(3)).Return(&%[1]s.%[2]s{ID: 3, UpdatedAt: stale}, nil).Twice()
+	repo.On("Update", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(nil).Once()
+	repo.On("GetByID", mock.Anything, uint(3)).Return(&%[1]s.%[2]s{ID: 3, UpdatedAt: fresh}, nil).Once()
+
+	send := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/v2/%[1]s/3", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusOK, send(http.MethodGet, "").Code)
+	assert.Equal(t, "HIT", send(http.MethodGet, "").Header().Get("X-Cache"))
+
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "{}").Code)
+
+	w := send(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	assert.Contains(t, w.Body.String(), fresh.Format(time.RFC3339))
+}
+`, name, titleName, moduleName, cachedListTestGo(name, pagination))
+}
+
+// cachedListTestGo sets up the repository list expectations of the cached
+// service test and its list function
+func cachedListTestGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`	repo.On("GetAll", mock.Anything, mock.Anything).Return([]*%[1]s.%[2]s{{ID: 1}}, nil).Twice()
+	list := func() error {
+		_, err := service.GetAll(ctx, "", 10, query.Query{})
+		return err
+	}
+`, name, titleName)
+	}
+	return fmt.Sprintf(`	repo.On("GetAll", mock.Anything, 1, 10, mock.Anything).Return([]*%[1]s.%[2]s{{ID: 1}}, int64(1), nil).Twice()
+	list := func() error {
+		_, err := service.GetAll(ctx, 1, 10, query.Query{})
+		return err
+	}
+`, name, titleName)
+}
@@ -0,0 +1,379 @@
+package templates
+
+import "fmt"
+
+// ClientGo is the runtime of a generated Go API client: options, retries
+// and error types. The operations are generated next to it.
+func ClientGo(pkg, title, version string) string {
+	return fmt.Sprintf(`// Code generated by meba client generate. DO NOT EDIT.
+
+// Package %[1]s is a typed client for the %[2]s.
+package %[1]s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Version is the API version this client was generated from
+const Version = "%[3]s"
+
+// Client calls the API over HTTP. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+	maxRetries int
+	backoff    time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the underlying HTTP client
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader adds a header sent with every request
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithRetries sets how often failed requests are retried and the initial
+// backoff, which doubles on every attempt
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the API served at baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		header:     http.Header{},
+		maxRetries: 2,
+		backoff:    200 * time.Millisecond,
+	}
+	c.header.Set("User-Agent", "meba-client/"+Version)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned when the API responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("api error %%d: %%s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("api error %%d: %%s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// StatusCode returns the HTTP status of an *APIError, or 0 for other errors
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether err is a 404 response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// Ptr returns a pointer to v, for optional parameters
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, Body: body}
+	var payload struct {
+		Message string `+"`json:\"message\"`"+`
+		Error   string `+"`json:\"error\"`"+`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		apiErr.Message = payload.Message
+		if payload.Error != "" && payload.Error != payload.Message {
+			if apiErr.Message != "" {
+				apiErr.Message += ": "
+			}
+			apiErr.Message += payload.Error
+		}
+	}
+	return apiErr
+}
+
+// do sends a request and decodes the JSON response into out. Network
+// errors and 502/503/504 responses are retried for idempotent methods,
+// 429 responses for all methods.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("encode request body: %%w", err)
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			return err
+		}
+		for key, values := range c.header {
+			req.Header[key] = values
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if attempt < c.maxRetries && idempotent(method) && ctx.Err() == nil {
+				if err := c.wait(ctx, attempt); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode >= 300 {
+			if attempt < c.maxRetries && retryable(method, resp.StatusCode) {
+				if err := c.wait(ctx, attempt); err != nil {
+					return err
+				}
+				continue
+			}
+			return newAPIError(resp.StatusCode, data)
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("decode response: %%w", err)
+		}
+		return nil
+	}
+}
+
+func (c *Client) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(c.backoff << attempt)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func retryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return idempotent(method)
+	}
+	return false
+}
+`, pkg, title, version)
+}
+
+// ClientTS is a generated TypeScript API client built on fetch. methods
+// holds the generated operations and imports the models they use.
+func ClientTS(title, version, imports, methods string) string {
+	return fmt.Sprintf(`// Code generated by meba client generate. DO NOT EDIT.
+// Typed client for the %[1]s.
+%[3]s
+/** API version this client was generated from */
+export const API_VERSION = "%[2]s";
+
+export interface ClientOptions {
+  /** Base URL of the API, e.g. http://localhost:8080 */
+  baseUrl: string;
+  /** Headers sent with every request */
+  headers?: Record<string, string>;
+  /** fetch implementation, defaults to the global fetch */
+  fetch?: typeof fetch;
+  /** How often failed requests are retried (default 2) */
+  retries?: number;
+  /** Initial retry delay in milliseconds, doubled on every attempt (default 200) */
+  retryDelayMs?: number;
+}
+
+type QueryValue = string | number | boolean | null | undefined | Array<string | number | boolean>;
+
+interface RequestOptions {
+  query?: Record<string, QueryValue>;
+  body?: unknown;
+  init?: RequestInit;
+}
+
+/** Thrown when the API responds with a non-2xx status */
+export class ApiError extends Error {
+  readonly status: number;
+  readonly body: unknown;
+
+  constructor(status: number, body: unknown) {
+    super(ApiError.messageOf(status, body));
+    this.name = "ApiError";
+    this.status = status;
+    this.body = body;
+  }
+
+  private static messageOf(status: number, body: unknown): string {
+    if (body && typeof body === "object") {
+      const { message, error } = body as { message?: unknown; error?: unknown };
+      const parts = [message, error].filter((p): p is string => typeof p === "string" && p !== "");
+      if (parts.length > 0) {
+        return "api error " + status + ": " + Array.from(new Set(parts)).join(": ");
+      }
+    }
+    return "api error " + status;
+  }
+}
+
+const IDEMPOTENT = new Set(["GET", "HEAD", "PUT", "DELETE", "OPTIONS"]);
+
+export class Client {
+  private readonly baseUrl: string;
+  private readonly headers: Record<string, string>;
+  private readonly fetchImpl: typeof fetch;
+  private readonly retries: number;
+  private readonly retryDelayMs: number;
+
+  constructor(options: ClientOptions) {
+    this.baseUrl = options.baseUrl.replace(/\/+$/, "");
+    this.headers = { ...(options.headers ?? {}) };
+    this.fetchImpl = options.fetch ?? globalThis.fetch.bind(globalThis);
+    this.retries = options.retries ?? 2;
+    this.retryDelayMs = options.retryDelayMs ?? 200;
+  }
+%[4]s
+  private async request<T>(method: string, path: string, options: RequestOptions = {}): Promise<T> {
+    const url = this.baseUrl + path + Client.queryString(options.query);
+    const headers: Record<string, string> = { Accept: "application/json", ...this.headers };
+    let body: string | undefined;
+    if (options.body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      body = JSON.stringify(options.body);
+    }
+
+    for (let attempt = 0; ; attempt++) {
+      let response: Response;
+      try {
+        response = await this.fetchImpl(url, { ...options.init, method, headers, body });
+      } catch (err) {
+        if (attempt < this.retries && IDEMPOTENT.has(method) && !options.init?.signal?.aborted) {
+          await this.sleep(attempt);
+          continue;
+        }
+        throw err;
+      }
+
+      const text = await response.text();
+      let data: unknown = undefined;
+      if (text !== "") {
+        try {
+          data = JSON.parse(text);
+        } catch {
+          data = text;
+        }
+      }
+      if (!response.ok) {
+        if (attempt < this.retries && Client.retryable(method, response.status)) {
+          await this.sleep(attempt);
+          continue;
+        }
+        throw new ApiError(response.status, data);
+      }
+      return data as T;
+    }
+  }
+
+  private sleep(attempt: number): Promise<void> {
+    return new Promise((resolve) => setTimeout(resolve, this.retryDelayMs * 2 ** attempt));
+  }
+
+  private static retryable(method: string, status: number): boolean {
+    if (status === 429) {
+      return true;
+    }
+    return IDEMPOTENT.has(method) && (status === 502 || status === 503 || status === 504);
+  }
+
+  private static queryString(query?: Record<string, QueryValue>): string {
+    if (!query) {
+      return "";
+    }
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query)) {
+      if (value === undefined || value === null) {
+        continue;
+      }
+      for (const v of Array.isArray(value) ? value : [value]) {
+        params.append(key, String(v));
+      }
+    }
+    const encoded = params.toString();
+    return encoded === "" ? "" : "?" + encoded;
+  }
+}
+`, title, version, imports, methods)
+}
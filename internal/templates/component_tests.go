@@ -8,7 +8,7 @@ import (
 // ServiceTestGoModule creates test file for a specific service module
 func ServiceTestGoModule(name string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
 	"testing"
@@ -16,157 +16,1058 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestNew%sService(t *testing.T) {
-	// Test service creation
-	service := New%sService()
-	
-	// Assertions
-	assert.NotNil(t, service)
-	assert.IsType(t, &Service{}, service)
-}
+func TestNew%[2]sService(t *testing.T) {
+	service := New%[2]sService()
 
-func Test%sService_BusinessLogic(t *testing.T) {
-	// Setup
-	service := New%sService()
-	
-	// Test your service methods here
-	// Example:
-	// result := service.SomeMethod()
-	// assert.NotNil(t, result)
-	
-	// Assertions
-	assert.NotNil(t, service)
+	assert.IsType(t, &Service{}, service)
 }
 
-// Add more %s service tests as needed
-func Test%sService_Methods(t *testing.T) {
-	t.Skip("Implement your %s service method tests")
-}
-`, name, titleName, titleName, titleName, titleName, name, titleName, name)
+// Test the methods you add to the %[1]s service here. Depend on interfaces
+// for its collaborators and mock them with meba g mocks %[1]s.
+`, name, titleName)
 }
 
 // RepositoryTestGoModule creates test file for a specific repository module
 func RepositoryTestGoModule(name string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
 	"testing"
 
+	"github.com/glebarez/sqlite"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
-func TestNew%sRepository(t *testing.T) {
-	// Setup test database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	assert.NoError(t, err)
-	
-	// Test repository creation
-	repo := New%sRepository(db)
-	
-	// Assertions
-	assert.NotNil(t, repo)
-	assert.IsType(t, &Repository{}, repo)
-	assert.Equal(t, db, repo.db)
-}
-
-func Test%sRepository_DatabaseOperations(t *testing.T) {
-	// Setup test database
+// newTestDB opens an in-memory SQLite database. A single connection keeps
+// every query on the same database.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	assert.NoError(t, err)
-	
-	// Auto-migrate the schema
-	err = db.AutoMigrate(&%s{})
-	assert.NoError(t, err)
-	
-	// Create repository
-	repo := New%sRepository(db)
-	
-	// Test your repository methods here
-	// Example:
-	// entity := &%s{Name: "test"}
-	// err = repo.Create(entity)
-	// assert.NoError(t, err)
-	
-	assert.NotNil(t, repo)
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
 }
 
-// Add more %s repository tests as needed
-func Test%sRepository_CRUD(t *testing.T) {
-	t.Skip("Implement your %s repository CRUD tests")
+func TestNew%[2]sRepository(t *testing.T) {
+	db := newTestDB(t)
+
+	repo := New%[2]sRepository(db)
+
+	assert.Same(t, db, repo.db)
 }
-`, name, titleName, titleName, titleName, titleName, titleName, titleName, name, titleName, name)
+
+// Test the queries you add to the %[1]s repository here, migrating their
+// entities with db.AutoMigrate first.
+`, name, titleName)
 }
 
 // HandlersTestGoModule creates test file for a specific handlers module
 func HandlersTestGoModule(name string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
-	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestNew%sHandlers(t *testing.T) {
-	// Setup
-	service := &Service{} // Mock service
-	
-	// Test handlers creation
-	handlers := New%sHandlers(service)
-	
-	// Assertions
-	assert.NotNil(t, handlers)
-	assert.IsType(t, &Handlers{}, handlers)
-	assert.Equal(t, service, handlers.service)
-}
-
-func Test%sHandlers_SetupRoutes(t *testing.T) {
-	// Setup
+func TestNew%[2]sHandlers(t *testing.T) {
+	service := &Service{}
+
+	handlers := New%[2]sHandlers(service)
+
+	assert.Same(t, service, handlers.service)
+}
+
+func Test%[2]sHandlers_SetupRoutes(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	service := &Service{} // Mock service
-	handlers := New%sHandlers(service)
-	
-	// Setup routes
-	apiGroup := router.Group("/api/v1")
-	handlers.SetupRoutes(apiGroup)
-	
-	// Test that routes are registered
-	routes := router.Routes()
-	assert.NotEmpty(t, routes)
-}
-
-func Test%sHandlers_HTTPEndpoints(t *testing.T) {
-	// Setup
+
+	assert.NotPanics(t, func() {
+		New%[2]sHandlers(&Service{}).SetupRoutes(router.Group("/api/v1"))
+	})
+}
+
+// Test the routes you add to SetupRoutes here with httptest, e.g.
+//
+//	w := httptest.NewRecorder()
+//	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/%[1]s", nil))
+//	assert.Equal(t, http.StatusOK, w.Code)
+`, name, titleName)
+}
+
+// ResourceHandlersTestGo creates handler tests for a resource covering
+// every route and status, isolated from the service with the mocks from
+// meba g mocks
+func ResourceHandlersTestGo(moduleName, name, pagination string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package %[1]s_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"%[3]s/internal/%[1]s"
+	"%[3]s/internal/%[1]s/mocks"
+	apperrors "%[3]s/pkg/errors"
+	"%[3]s/pkg/middleware"
+	"%[3]s/pkg/validator"
+)
+
+// serve sends a request to the %[1]s routes backed by service, validating
+// it like the server and rendering errors as problem+json
+func serve(t *testing.T, service %[1]s.ServiceInterface, method, path, body string) (int, map[string]interface{}) {
+	t.Helper()
 	gin.SetMode(gin.TestMode)
+	validator.Setup()
 	router := gin.New()
-	service := &Service{} // Mock service
-	handlers := New%sHandlers(service)
-	
-	// Setup routes
-	apiGroup := router.Group("/api/v1")
-	handlers.SetupRoutes(apiGroup)
-	
-	// Test endpoints (add specific tests for your handlers)
-	// Example:
-	// w := httptest.NewRecorder()
-	// req, _ := http.NewRequest("GET", "/api/v1/%s", nil)
-	// router.ServeHTTP(w, req)
-	// assert.Equal(t, http.StatusOK, w.Code)
-	
-	assert.NotNil(t, handlers)
-}
-
-// Add more %s handlers tests as needed
-func Test%sHandlers_Methods(t *testing.T) {
-	t.Skip("Implement your %s handlers method tests")
-}
-`, name, titleName, titleName, titleName, titleName, titleName, titleName, name, name, titleName, name)
-}
\ No newline at end of file
+	router.Use(middleware.Errors(apperrors.FormatProblem))
+	%[1]s.New%[2]sHandlers(service).SetupRoutes(router.Group("/api/v1"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+// data returns the data object of a response
+func data(t *testing.T, resp map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	d, ok := resp["data"].(map[string]interface{})
+	require.True(t, ok, "response has no data object: %%v", resp)
+	return d
+}
+
+func Test%[2]sHandlers(t *testing.T) {
+	dbErr := errors.New("database is down")
+	anyCtx := mock.Anything
+	anyCreate := mock.AnythingOfType("*%[1]s.Create%[2]sRequest")
+	anyUpdate := mock.AnythingOfType("*%[1]s.Update%[2]sRequest")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		setup  func(s *mocks.Service)
+		status int
+		check  func(t *testing.T, resp map[string]interface{})
+	}{
+%[4]s		{
+			name:   "get",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s/1",
+			setup: func(s *mocks.Service) {
+				s.On("GetByID", anyCtx, uint(1)).Return(&%[1]s.%[2]s{ID: 1}, nil)
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, float64(1), data(t, resp)["id"])
+			},
+		},
+		{
+			name:   "get with invalid id",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s/abc",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Contains(t, resp["errors"], "id")
+			},
+		},
+		{
+			name:   "get missing",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s/42",
+			setup: func(s *mocks.Service) {
+				s.On("GetByID", anyCtx, uint(42)).Return(nil, %[1]s.ErrNotFound)
+			},
+			status: http.StatusNotFound,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, %[1]s.ErrNotFound.Message, resp["detail"])
+			},
+		},
+		{
+			name:   "get fails",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s/1",
+			setup: func(s *mocks.Service) {
+				s.On("GetByID", anyCtx, uint(1)).Return(nil, dbErr)
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "create",
+			method: http.MethodPost,
+			path:   "/api/v1/%[1]s",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Create", anyCtx, anyCreate).Return(&%[1]s.%[2]s{ID: 7}, nil)
+			},
+			status: http.StatusCreated,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, float64(7), data(t, resp)["id"])
+			},
+		},
+		{
+			name:   "create with invalid body",
+			method: http.MethodPost,
+			path:   "/api/v1/%[1]s",
+			body:   "{",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "create fails",
+			method: http.MethodPost,
+			path:   "/api/v1/%[1]s",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Create", anyCtx, anyCreate).Return(nil, dbErr)
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "create conflict",
+			method: http.MethodPost,
+			path:   "/api/v1/%[1]s",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Create", anyCtx, anyCreate).Return(nil, apperrors.Conflict("%[2]s already exists"))
+			},
+			status: http.StatusConflict,
+		},
+		{
+			name:   "update",
+			method: http.MethodPut,
+			path:   "/api/v1/%[1]s/7",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Update", anyCtx, uint(7), anyUpdate).Return(&%[1]s.%[2]s{ID: 7}, nil)
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, float64(7), data(t, resp)["id"])
+			},
+		},
+		{
+			name:   "update with invalid id",
+			method: http.MethodPut,
+			path:   "/api/v1/%[1]s/abc",
+			body:   "{}",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update with invalid body",
+			method: http.MethodPut,
+			path:   "/api/v1/%[1]s/7",
+			body:   "{",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update missing",
+			method: http.MethodPut,
+			path:   "/api/v1/%[1]s/42",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Update", anyCtx, uint(42), anyUpdate).Return(nil, %[1]s.ErrNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "update fails",
+			method: http.MethodPut,
+			path:   "/api/v1/%[1]s/7",
+			body:   "{}",
+			setup: func(s *mocks.Service) {
+				s.On("Update", anyCtx, uint(7), anyUpdate).Return(nil, dbErr)
+			},
+			status: http.StatusInternalServerError,
+		},
+		{
+			name:   "delete",
+			method: http.MethodDelete,
+			path:   "/api/v1/%[1]s/7",
+			setup: func(s *mocks.Service) {
+				s.On("Delete", anyCtx, uint(7)).Return(nil)
+			},
+			status: http.StatusOK,
+		},
+		{
+			name:   "delete with invalid id",
+			method: http.MethodDelete,
+			path:   "/api/v1/%[1]s/abc",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "delete missing",
+			method: http.MethodDelete,
+			path:   "/api/v1/%[1]s/42",
+			setup: func(s *mocks.Service) {
+				s.On("Delete", anyCtx, uint(42)).Return(%[1]s.ErrNotFound)
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "delete fails",
+			method: http.MethodDelete,
+			path:   "/api/v1/%[1]s/7",
+			setup: func(s *mocks.Service) {
+				s.On("Delete", anyCtx, uint(7)).Return(dbErr)
+			},
+			status: http.StatusInternalServerError,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "Internal server error", resp["detail"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := mocks.NewService(t)
+			if tt.setup != nil {
+				tt.setup(service)
+			}
+
+			status, resp := serve(t, service, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, tt.status, status)
+			if tt.status < http.StatusBadRequest {
+				assert.Equal(t, true, resp["success"])
+			} else {
+				assert.Equal(t, float64(tt.status), resp["status"])
+			}
+			if tt.check != nil {
+				tt.check(t, resp)
+			}
+		})
+	}
+}
+`, name, titleName, moduleName, handlersListTestsGo(name, pagination))
+}
+
+// handlersListTestsGo are the list cases of the handler tests of a
+// resource, paginated by page or by cursor
+func handlersListTestsGo(name, pagination string) string {
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`		{
+			name:   "list",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?cursor=abc&limit=5",
+			setup: func(s *mocks.Service) {
+				s.On("GetAll", anyCtx, "abc", 5, mock.Anything).Return(&%[1]s.CursorResponse{Limit: 5, NextCursor: "def"}, nil)
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, "def", data(t, resp)["next_cursor"])
+			},
+		},
+		{
+			name:   "list with invalid query",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?limit=abc",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, string(apperrors.KindValidation), resp["code"])
+			},
+		},
+		{
+			name:   "list with limit over the max",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?limit=1000",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Contains(t, resp["errors"], "limit")
+			},
+		},
+		{
+			name:   "list with unknown filter field",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?filter[unknown]=1&sort=-id",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Contains(t, resp["errors"], "filter[unknown]")
+			},
+		},
+		{
+			name:   "list fails",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s",
+			setup: func(s *mocks.Service) {
+				s.On("GetAll", anyCtx, "", 0, mock.Anything).Return(nil, dbErr)
+			},
+			status: http.StatusInternalServerError,
+		},
+`, name)
+	}
+	return fmt.Sprintf(`		{
+			name:   "list",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?page=2&page_size=5",
+			setup: func(s *mocks.Service) {
+				s.On("GetAll", anyCtx, 2, 5, mock.Anything).Return(&%[1]s.PaginationResponse{Page: 2, PageSize: 5, Total: 6, TotalPages: 2}, nil)
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, float64(2), data(t, resp)["total_pages"])
+			},
+		},
+		{
+			name:   "list with invalid query",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?page=abc",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Equal(t, string(apperrors.KindValidation), resp["code"])
+			},
+		},
+		{
+			name:   "list with page size over the max",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?page_size=1000",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Contains(t, resp["errors"], "page_size")
+			},
+		},
+		{
+			name:   "list with unknown filter field",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s?filter[unknown]=1&sort=-id",
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				assert.Contains(t, resp["errors"], "filter[unknown]")
+			},
+		},
+		{
+			name:   "list fails",
+			method: http.MethodGet,
+			path:   "/api/v1/%[1]s",
+			setup: func(s *mocks.Service) {
+				s.On("GetAll", anyCtx, 0, 0, mock.Anything).Return(nil, dbErr)
+			},
+			status: http.StatusInternalServerError,
+		},
+`, name)
+}
+
+// ResourceServiceTestGo creates service tests for a resource covering the
+// pagination math and not-found mapping, isolated from the database with
+// the mocks from meba g mocks
+func ResourceServiceTestGo(moduleName, name, pagination string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package %[1]s_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"%[3]s/internal/%[1]s"
+	"%[3]s/internal/%[1]s/mocks"
+	"%[3]s/pkg/query"
+)
+
+%[4]sfunc Test%[2]sService_Errors(t *testing.T) {
+	ctx := context.Background()
+	dbErr := errors.New("database is down")
+
+	tests := []struct {
+		name    string
+		setup   func(r *mocks.Repository)
+		call    func(s *%[1]s.Service) error
+		wantErr error
+	}{
+		{
+			name: "get missing",
+			setup: func(r *mocks.Repository) {
+				r.On("GetByID", mock.Anything, uint(42)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.GetByID(ctx, 42)
+				return err
+			},
+			wantErr: %[1]s.ErrNotFound,
+		},
+		{
+			name: "get fails",
+			setup: func(r *mocks.Repository) {
+				r.On("GetByID", mock.Anything, uint(1)).Return(nil, dbErr)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.GetByID(ctx, 1)
+				return err
+			},
+			wantErr: dbErr,
+		},
+		{
+			name: "create fails",
+			setup: func(r *mocks.Repository) {
+				r.On("Create", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(dbErr)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.Create(ctx, &%[1]s.Create%[2]sRequest{})
+				return err
+			},
+			wantErr: dbErr,
+		},
+		{
+			name: "update missing",
+			setup: func(r *mocks.Repository) {
+				r.On("GetByID", mock.Anything, uint(42)).Return(nil, gorm.ErrRecordNotFound)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.Update(ctx, 42, &%[1]s.Update%[2]sRequest{})
+				return err
+			},
+			wantErr: %[1]s.ErrNotFound,
+		},
+		{
+			name: "update lookup fails",
+			setup: func(r *mocks.Repository) {
+				r.On("GetByID", mock.Anything, uint(1)).Return(nil, dbErr)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.Update(ctx, 1, &%[1]s.Update%[2]sRequest{})
+				return err
+			},
+			wantErr: dbErr,
+		},
+		{
+			name: "update fails",
+			setup: func(r *mocks.Repository) {
+				r.On("GetByID", mock.Anything, uint(1)).Return(&%[1]s.%[2]s{ID: 1}, nil)
+				r.On("Update", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(dbErr)
+			},
+			call: func(s *%[1]s.Service) error {
+				_, err := s.Update(ctx, 1, &%[1]s.Update%[2]sRequest{})
+				return err
+			},
+			wantErr: dbErr,
+		},
+		{
+			name: "delete missing",
+			setup: func(r *mocks.Repository) {
+				r.On("Delete", mock.Anything, uint(42)).Return(gorm.ErrRecordNotFound)
+			},
+			call: func(s *%[1]s.Service) error {
+				return s.Delete(ctx, 42)
+			},
+			wantErr: %[1]s.ErrNotFound,
+		},
+		{
+			name: "delete fails",
+			setup: func(r *mocks.Repository) {
+				r.On("Delete", mock.Anything, uint(1)).Return(dbErr)
+			},
+			call: func(s *%[1]s.Service) error {
+				return s.Delete(ctx, 1)
+			},
+			wantErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			tt.setup(repo)
+
+			err := tt.call(%[1]s.New%[2]sService(repo))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func Test%[2]sService_GetByID(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	repo.On("GetByID", mock.Anything, uint(1)).Return(&%[1]s.%[2]s{ID: 1}, nil)
+
+	item, err := %[1]s.New%[2]sService(repo).GetByID(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), item.ID)
+}
+
+func Test%[2]sService_Create(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*%[1]s.%[2]s")).Return(nil)
+
+	item, err := %[1]s.New%[2]sService(repo).Create(ctx, &%[1]s.Create%[2]sRequest{})
+
+	require.NoError(t, err)
+	assert.NotNil(t, item)
+}
+
+func Test%[2]sService_Update(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	existing := &%[1]s.%[2]s{ID: 3}
+	repo.On("GetByID", mock.Anything, uint(3)).Return(existing, nil)
+	repo.On("Update", mock.Anything, existing).Return(nil)
+
+	item, err := %[1]s.New%[2]sService(repo).Update(ctx, 3, &%[1]s.Update%[2]sRequest{})
+
+	require.NoError(t, err)
+	assert.Same(t, existing, item)
+}
+
+func Test%[2]sService_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	repo.On("Delete", mock.Anything, uint(3)).Return(nil)
+
+	err := %[1]s.New%[2]sService(repo).Delete(ctx, 3)
+
+	assert.NoError(t, err)
+}
+`, name, titleName, moduleName, serviceListTestsGo(name, pagination))
+}
+
+// serviceListTestsGo are the GetAll tests of the service tests of a
+// resource, paginated by page or by cursor
+func serviceListTestsGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`func Test%[2]sService_GetAll(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		limit     int
+		rows      int
+		wantLimit int
+		wantItems int
+		wantNext  bool
+	}{
+		{name: "default limit", limit: 0, rows: 3, wantLimit: 10, wantItems: 3},
+		{name: "last page", limit: 5, rows: 5, wantLimit: 5, wantItems: 5},
+		{name: "more pages", limit: 5, rows: 6, wantLimit: 5, wantItems: 5, wantNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			rows := make([]*%[1]s.%[2]s, tt.rows)
+			for i := range rows {
+				rows[i] = &%[1]s.%[2]s{ID: uint(i + 1)}
+			}
+			repo.On("GetAll", mock.Anything, mock.Anything).Return(rows, nil)
+
+			result, err := %[1]s.New%[2]sService(repo).GetAll(ctx, "", tt.limit, query.Query{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLimit, result.Limit)
+			assert.Len(t, result.Data, tt.wantItems)
+			assert.Equal(t, tt.wantNext, result.NextCursor != "")
+			assert.Empty(t, result.PrevCursor)
+		})
+	}
+}
+
+func Test%[2]sService_GetAllNextPage(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	repo.On("GetAll", mock.Anything, mock.Anything).Return([]*%[1]s.%[2]s{{ID: 1}, {ID: 2}, {ID: 3}}, nil)
+	service := %[1]s.New%[2]sService(repo)
+
+	first, err := service.GetAll(ctx, "", 2, query.Query{})
+	require.NoError(t, err)
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := service.GetAll(ctx, first.NextCursor, 2, query.Query{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, second.PrevCursor)
+}
+
+func Test%[2]sService_GetAllInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+
+	_, err := %[1]s.New%[2]sService(repo).GetAll(ctx, "not-a-cursor", 10, query.Query{})
+
+	assert.ErrorContains(t, err, "Invalid cursor")
+}
+
+func Test%[2]sService_GetAllError(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	dbErr := errors.New("database is down")
+	repo.On("GetAll", mock.Anything, mock.Anything).Return(nil, dbErr)
+
+	_, err := %[1]s.New%[2]sService(repo).GetAll(ctx, "", 10, query.Query{})
+
+	assert.ErrorIs(t, err, dbErr)
+}
+
+func Test%[2]sService_GetAllSelectsFields(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	q, err := query.Parse(url.Values{"fields": {"id"}}, query.FieldsOf(&%[1]s.%[2]s{}))
+	require.NoError(t, err)
+	repo.On("GetAll", mock.Anything, mock.Anything).Return([]*%[1]s.%[2]s{{ID: 1}}, nil)
+
+	result, err := %[1]s.New%[2]sService(repo).GetAll(ctx, "", 10, q)
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1)}}, result.Data)
+}
+
+`, name, titleName)
+	}
+	return fmt.Sprintf(`func Test%[2]sService_GetAll(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		page, pageSize int
+		total          int64
+		wantPage       int
+		wantPageSize   int
+		wantTotalPages int
+	}{
+		{name: "defaults", page: 0, pageSize: 0, total: 0, wantPage: 1, wantPageSize: 10, wantTotalPages: 0},
+		{name: "negative values", page: -1, pageSize: -5, total: 5, wantPage: 1, wantPageSize: 10, wantTotalPages: 1},
+		{name: "exact pages", page: 1, pageSize: 10, total: 20, wantPage: 1, wantPageSize: 10, wantTotalPages: 2},
+		{name: "partial last page", page: 2, pageSize: 10, total: 21, wantPage: 2, wantPageSize: 10, wantTotalPages: 3},
+		{name: "page past the end", page: 9, pageSize: 25, total: 30, wantPage: 9, wantPageSize: 25, wantTotalPages: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewRepository(t)
+			items := []*%[1]s.%[2]s{{ID: 1}}
+			repo.On("GetAll", mock.Anything, tt.wantPage, tt.wantPageSize, query.Query{}).Return(items, tt.total, nil)
+
+			result, err := %[1]s.New%[2]sService(repo).GetAll(ctx, tt.page, tt.pageSize, query.Query{})
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPage, result.Page)
+			assert.Equal(t, tt.wantPageSize, result.PageSize)
+			assert.Equal(t, tt.total, result.Total)
+			assert.Equal(t, tt.wantTotalPages, result.TotalPages)
+			assert.Equal(t, items, result.Data)
+		})
+	}
+}
+
+func Test%[2]sService_GetAllError(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	dbErr := errors.New("database is down")
+	repo.On("GetAll", mock.Anything, 1, 10, query.Query{}).Return(nil, int64(0), dbErr)
+
+	_, err := %[1]s.New%[2]sService(repo).GetAll(ctx, 1, 10, query.Query{})
+
+	assert.ErrorIs(t, err, dbErr)
+}
+
+func Test%[2]sService_GetAllSelectsFields(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewRepository(t)
+	q, err := query.Parse(url.Values{"fields": {"id"}}, query.FieldsOf(&%[1]s.%[2]s{}))
+	require.NoError(t, err)
+	repo.On("GetAll", mock.Anything, 1, 10, q).Return([]*%[1]s.%[2]s{{ID: 1}}, int64(1), nil)
+
+	result, err := %[1]s.New%[2]sService(repo).GetAll(ctx, 1, 10, q)
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1)}}, result.Data)
+}
+
+`, name, titleName)
+}
+
+// ResourceRepositoryTestGo creates repository tests for a resource against
+// in-memory SQLite
+func ResourceRepositoryTestGo(moduleName, name, pagination string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package %[1]s_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"%[3]s/internal/%[1]s"
+	apperrors "%[3]s/pkg/errors"
+	"%[3]s/pkg/query"
+)
+
+// newTestRepository returns a repository on a migrated in-memory SQLite
+// database holding n %[1]s. A single connection keeps every query on the
+// same database.
+func newTestRepository(t *testing.T, n int) *%[1]s.Repository {
+	t.Helper()
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, db.AutoMigrate(&%[1]s.%[2]s{}))
+	repo := %[1]s.New%[2]sRepository(db)
+	for i := 0; i < n; i++ {
+		require.NoError(t, repo.Create(ctx, &%[1]s.%[2]s{}))
+	}
+	return repo
+}
+
+%[4]sfunc Test%[2]sRepository_CreateAndGetByID(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, 0)
+	item := &%[1]s.%[2]s{}
+
+	require.NoError(t, repo.Create(ctx, item))
+	require.NotZero(t, item.ID)
+
+	found, err := repo.GetByID(ctx, item.ID)
+	require.NoError(t, err)
+	assert.Equal(t, item.ID, found.ID)
+	assert.False(t, found.CreatedAt.IsZero())
+}
+
+func Test%[2]sRepository_GetByIDMissing(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, 0)
+
+	_, err := repo.GetByID(ctx, 42)
+
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.Equal(t, apperrors.KindNotFound, apperrors.From(err).Kind)
+}
+
+func Test%[2]sRepository_Update(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepository(t, 1)
+	item, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	updatedAt := item.UpdatedAt
+
+	require.NoError(t, repo.Update(ctx, item))
+
+	found, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, found.UpdatedAt.Before(updatedAt))
+}
+
+func Test%[2]sRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		id      uint
+		wantErr error
+		left    int64
+	}{
+		{name: "existing", id: 2, left: 2},
+		{name: "missing", id: 42, wantErr: gorm.ErrRecordNotFound, left: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepository(t, 3)
+
+			err := repo.Delete(ctx, tt.id)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				_, err := repo.GetByID(ctx, tt.id)
+				assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+			}
+%[5]s		})
+	}
+}
+`, name, titleName, moduleName, repositoryListTestsGo(name, pagination), repositoryCountGo(pagination))
+}
+
+// repositoryListTestsGo are the GetAll tests of the repository tests of a
+// resource, paginated by offset or by keyset
+func repositoryListTestsGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`// page loads a page of the %[1]s matching q like the service does
+func page(t *testing.T, repo *%[1]s.Repository, q query.Query, cursor string, limit int) (ids []uint, next, prev string) {
+	t.Helper()
+	keyset, err := query.NewKeyset(q, query.FieldsOf(&%[1]s.%[2]s{}), cursor, limit)
+	require.NoError(t, err)
+	rows, err := repo.GetAll(context.Background(), keyset)
+	require.NoError(t, err)
+	items, next, prev := query.Paginate(keyset, rows)
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids, next, prev
+}
+
+func Test%[2]sRepository_GetAll(t *testing.T) {
+	repo := newTestRepository(t, 25)
+
+	ids, next, prev := page(t, repo, query.Query{}, "", 10)
+	assert.Equal(t, []uint{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+	assert.Empty(t, prev)
+
+	ids, next, prev = page(t, repo, query.Query{}, next, 10)
+	assert.Equal(t, []uint{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, ids)
+	assert.NotEmpty(t, prev)
+
+	ids, last, prev := page(t, repo, query.Query{}, next, 10)
+	assert.Equal(t, []uint{21, 22, 23, 24, 25}, ids)
+	assert.Empty(t, last)
+
+	ids, next, _ = page(t, repo, query.Query{}, prev, 10)
+	assert.Equal(t, []uint{11, 12, 13, 14, 15, 16, 17, 18, 19, 20}, ids)
+	assert.NotEmpty(t, next)
+}
+
+func Test%[2]sRepository_GetAllQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  []uint
+	}{
+		{name: "filter", query: url.Values{"filter[id][gt]": {"20"}}, want: []uint{21, 22, 23, 24, 25}},
+		{name: "sort descending", query: url.Values{"sort": {"-id"}}, want: []uint{25, 24, 23, 22, 21, 20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{name: "sort by another field", query: url.Values{"sort": {"created_at"}, "filter[id][lte]": {"9"}}, want: []uint{1, 2, 3, 4, 5, 6, 7, 8, 9}},
+	}
+
+	repo := newTestRepository(t, 25)
+	fields := query.FieldsOf(&%[1]s.%[2]s{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, fields)
+			require.NoError(t, err)
+
+			// Walk every page, 4 items at a time
+			var all []uint
+			cursor := ""
+			for {
+				ids, next, _ := page(t, repo, q, cursor, 4)
+				all = append(all, ids...)
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			assert.Equal(t, tt.want, all)
+		})
+	}
+}
+
+`, name, titleName)
+	}
+	return fmt.Sprintf(`func Test%[2]sRepository_GetAll(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		page, pageSize int
+		wantItems      int
+	}{
+		{name: "first page", page: 1, pageSize: 10, wantItems: 10},
+		{name: "last page", page: 3, pageSize: 10, wantItems: 5},
+		{name: "past the end", page: 4, pageSize: 10, wantItems: 0},
+		{name: "everything", page: 1, pageSize: 100, wantItems: 25},
+	}
+
+	repo := newTestRepository(t, 25)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, total, err := repo.GetAll(ctx, tt.page, tt.pageSize, query.Query{})
+
+			require.NoError(t, err)
+			assert.Equal(t, int64(25), total)
+			assert.Len(t, items, tt.wantItems)
+			if tt.wantItems > 0 {
+				assert.Equal(t, uint((tt.page-1)*tt.pageSize+1), items[0].ID)
+			}
+		})
+	}
+}
+
+func Test%[2]sRepository_GetAllQuery(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantTotal int64
+		wantFirst uint
+	}{
+		{name: "filter", query: url.Values{"filter[id][gt]": {"20"}}, wantTotal: 5, wantFirst: 21},
+		{name: "filter in", query: url.Values{"filter[id][in]": {"3,7"}}, wantTotal: 2, wantFirst: 3},
+		{name: "sort descending", query: url.Values{"sort": {"-id"}}, wantTotal: 25, wantFirst: 25},
+		{name: "filter and sort", query: url.Values{"filter[id][lte]": {"10"}, "sort": {"-id"}}, wantTotal: 10, wantFirst: 10},
+	}
+
+	repo := newTestRepository(t, 25)
+	fields := query.FieldsOf(&%[1]s.%[2]s{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query, fields)
+			require.NoError(t, err)
+
+			items, total, err := repo.GetAll(ctx, 1, 10, q)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTotal, total)
+			require.NotEmpty(t, items)
+			assert.Equal(t, tt.wantFirst, items[0].ID)
+		})
+	}
+}
+
+`, name, titleName)
+}
+
+// repositoryCountGo checks how many items the Delete test left
+func repositoryCountGo(pagination string) string {
+	if pagination == PaginationCursor {
+		return `			ids, _, _ := page(t, repo, query.Query{}, "", 10)
+			assert.Len(t, ids, int(tt.left))
+`
+	}
+	return `			_, total, err := repo.GetAll(ctx, 1, 10, query.Query{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.left, total)
+`
+}
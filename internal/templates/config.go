@@ -11,6 +11,19 @@ app:
   env: "development"
   debug: true
 
+# HTTP Server Configuration
+server:
+  read_timeout: "15s"
+  read_header_timeout: "5s"
+  write_timeout: "30s"
+  idle_timeout: "60s"
+  max_header_bytes: 1048576
+  shutdown_timeout: "30s"   # drain time for in-flight requests on SIGINT/SIGTERM
+  error_format: "problem"   # problem (application/problem+json) or envelope ({"success": false, ...})
+  tls:
+    cert_file: ""           # serve HTTPS when both are set
+    key_file: ""
+
 # Database Configuration
 database:
   host: "localhost"
@@ -53,9 +66,14 @@ rate_limit:
 
 # Logging Configuration
 logging:
-  level: "info"
-  format: "json"
-  output: "stdout"
+  level: "info"       # debug, info, warn or error
+  format: "json"      # json or console
+  output: "stdout"    # stdout, stderr or a file, e.g. logs/app.log
+  rotation:           # for file output
+    max_size_mb: 100
+    max_backups: 5
+    max_age_days: 30
+    compress: true
 
 # Swagger Configuration
 swagger:
@@ -73,7 +91,8 @@ monitoring:
     path: "/metrics"
   health_check:
     enabled: true
-    path: "/health"
+    path: "/api/v1/health"   # with /live and /ready; probed by the image healthcheck and deploy probes
+    timeout: "3s"            # per check, unless the checker sets its own
 
 # File Upload
 upload:
@@ -92,8 +111,38 @@ email:
 
 # Cache Configuration
 cache:
+  driver: "memory"          # memory or redis; meba add cache --driver redis adds redis
   default_ttl: "1h"
   cleanup_interval: "10m"
+
+# Release builds (meba build)
+build:
+  output: "dist/server"
+  targets: []   # e.g. ["linux/amd64", "linux/arm64", "darwin/arm64"]; empty builds for this machine
+  tags: []
+  ldflags: "-s -w"
+  trimpath: true
+  # cgo: false  # unset leaves CGO_ENABLED to go
+
+# Container image (meba build --image name[:tag])
+image:
+  base: "scratch"   # or an OCI layout/docker save tarball, or a name cached in ~/.cache/meba/images
+  platform: ""      # default linux/<arch of this machine>
+  user: "65532:65532"
+  include: ["configs"]
+  env: ["GIN_MODE=release"]
+  labels: []        # e.g. ["org.opencontainers.image.source=https://github.com/you/api"]
+  healthcheck:       # probes monitoring.health_check.path
+    interval: "30s"
+    timeout: "3s"
+    retries: 3
+
+# Live reload (meba start --watch, build/test/e2e --watch)
+watch:
+  include: ["**/*.go", "configs/**/*.yaml", "**/*.tmpl", "**/*.html"]
+  exclude: [".git/**", "dist/**", "tmp/**", "vendor/**", "docs/**", "**/wire_gen.go"]
+  delay: "300ms"
+  kill_timeout: "5s"
 `
 }
 
@@ -102,6 +151,7 @@ func ConfigGo() string {
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -110,6 +160,7 @@ import (
 // Config holds all configuration for our application
 type Config struct {
 	App        AppConfig        ` + "`mapstructure:\"app\"`" + `
+	Server     ServerConfig     ` + "`mapstructure:\"server\"`" + `
 	Database   DatabaseConfig   ` + "`mapstructure:\"database\"`" + `
 	Redis      RedisConfig      ` + "`mapstructure:\"redis\"`" + `
 	JWT        JWTConfig        ` + "`mapstructure:\"jwt\"`" + `
@@ -131,6 +182,22 @@ type AppConfig struct {
 	Debug   bool   ` + "`mapstructure:\"debug\"`" + `
 }
 
+type ServerConfig struct {
+	ReadTimeout       time.Duration ` + "`mapstructure:\"read_timeout\"`" + `
+	ReadHeaderTimeout time.Duration ` + "`mapstructure:\"read_header_timeout\"`" + `
+	WriteTimeout      time.Duration ` + "`mapstructure:\"write_timeout\"`" + `
+	IdleTimeout       time.Duration ` + "`mapstructure:\"idle_timeout\"`" + `
+	MaxHeaderBytes    int           ` + "`mapstructure:\"max_header_bytes\"`" + `
+	ShutdownTimeout   time.Duration ` + "`mapstructure:\"shutdown_timeout\"`" + `
+	ErrorFormat       string        ` + "`mapstructure:\"error_format\"`" + `
+	TLS               TLSConfig     ` + "`mapstructure:\"tls\"`" + `
+}
+
+type TLSConfig struct {
+	CertFile string ` + "`mapstructure:\"cert_file\"`" + `
+	KeyFile  string ` + "`mapstructure:\"key_file\"`" + `
+}
+
 type DatabaseConfig struct {
 	Host            string        ` + "`mapstructure:\"host\"`" + `
 	Port            int           ` + "`mapstructure:\"port\"`" + `
@@ -172,9 +239,17 @@ type RateLimitConfig struct {
 }
 
 type LoggingConfig struct {
-	Level  string ` + "`mapstructure:\"level\"`" + `
-	Format string ` + "`mapstructure:\"format\"`" + `
-	Output string ` + "`mapstructure:\"output\"`" + `
+	Level    string            ` + "`mapstructure:\"level\"`" + `
+	Format   string            ` + "`mapstructure:\"format\"`" + `
+	Output   string            ` + "`mapstructure:\"output\"`" + `
+	Rotation LogRotationConfig ` + "`mapstructure:\"rotation\"`" + `
+}
+
+type LogRotationConfig struct {
+	MaxSizeMB  int  ` + "`mapstructure:\"max_size_mb\"`" + `
+	MaxBackups int  ` + "`mapstructure:\"max_backups\"`" + `
+	MaxAgeDays int  ` + "`mapstructure:\"max_age_days\"`" + `
+	Compress   bool ` + "`mapstructure:\"compress\"`" + `
 }
 
 type SwaggerConfig struct {
@@ -197,8 +272,9 @@ type PrometheusConfig struct {
 }
 
 type HealthCheckConfig struct {
-	Enabled bool   ` + "`mapstructure:\"enabled\"`" + `
-	Path    string ` + "`mapstructure:\"path\"`" + `
+	Enabled bool          ` + "`mapstructure:\"enabled\"`" + `
+	Path    string        ` + "`mapstructure:\"path\"`" + `
+	Timeout time.Duration ` + "`mapstructure:\"timeout\"`" + `
 }
 
 type UploadConfig struct {
@@ -217,6 +293,7 @@ type EmailConfig struct {
 }
 
 type CacheConfig struct {
+	Driver          string        ` + "`mapstructure:\"driver\"`" + `
 	DefaultTTL      time.Duration ` + "`mapstructure:\"default_ttl\"`" + `
 	CleanupInterval time.Duration ` + "`mapstructure:\"cleanup_interval\"`" + `
 }
@@ -227,7 +304,8 @@ func LoadConfig(path string) (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 
-	// Enable environment variable support
+	// Enable environment variable support, e.g. JWT_SECRET for jwt.secret
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	// Set default values
@@ -252,6 +330,14 @@ func setDefaults() {
 	viper.SetDefault("app.env", "development")
 	viper.SetDefault("app.debug", true)
 
+	viper.SetDefault("server.read_timeout", "15s")
+	viper.SetDefault("server.read_header_timeout", "5s")
+	viper.SetDefault("server.write_timeout", "30s")
+	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.max_header_bytes", 1<<20)
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.error_format", "problem")
+
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "postgres")
@@ -267,9 +353,28 @@ func setDefaults() {
 	viper.SetDefault("jwt.expires_in", "24h")
 	viper.SetDefault("jwt.refresh_expires_in", "168h")
 
+	viper.SetDefault("monitoring.prometheus.enabled", true)
+	viper.SetDefault("monitoring.prometheus.path", "/metrics")
+	viper.SetDefault("monitoring.health_check.enabled", true)
+	viper.SetDefault("monitoring.health_check.path", "/api/v1/health")
+	viper.SetDefault("monitoring.health_check.timeout", "3s")
+
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
 	viper.SetDefault("logging.output", "stdout")
+	viper.SetDefault("logging.rotation.max_size_mb", 100)
+	viper.SetDefault("logging.rotation.max_backups", 5)
+	viper.SetDefault("logging.rotation.max_age_days", 30)
+	viper.SetDefault("logging.rotation.compress", true)
+
+	viper.SetDefault("cache.driver", "memory")
+	viper.SetDefault("cache.default_ttl", "1h")
+	viper.SetDefault("cache.cleanup_interval", "10m")
+}
+
+// TLSEnabled reports whether the server should serve HTTPS
+func (s *ServerConfig) TLSEnabled() bool {
+	return s.TLS.CertFile != "" && s.TLS.KeyFile != ""
 }
 
 // GetDSN returns the database connection string
@@ -290,54 +395,6 @@ func (a *AppConfig) IsDevelopment() bool {
 `
 }
 
-func AirToml() string {
-	return `root = "."
-testdata_dir = "testdata"
-tmp_dir = "tmp"
-
-[build]
-  args_bin = []
-  bin = "./tmp/main"
-  cmd = "go build -o ./tmp/main ./cmd/server"
-  delay = 1000
-  exclude_dir = ["assets", "tmp", "vendor", "testdata"]
-  exclude_file = []
-  exclude_regex = ["_test.go"]
-  exclude_unchanged = false
-  follow_symlink = false
-  full_bin = ""
-  include_dir = []
-  include_ext = ["go", "tpl", "tmpl", "html", "yaml", "yml"]
-  include_file = []
-  kill_delay = "0s"
-  log = "build-errors.log"
-  poll = false
-  poll_interval = 0
-  rerun = false
-  rerun_delay = 500
-  send_interrupt = false
-  stop_on_root = false
-
-[color]
-  app = ""
-  build = "yellow"
-  main = "magenta"
-  runner = "green"
-  watcher = "cyan"
-
-[log]
-  main_only = false
-  time = false
-
-[misc]
-  clean_on_exit = false
-
-[screen]
-  clear_on_rebuild = false
-  keep_scroll = true
-`
-}
-
 func GitIgnore() string {
 	return `# Binaries for programs and plugins
 *.exe
@@ -387,9 +444,6 @@ uploads/
 .env.local
 .env.*.local
 
-# Air
-.air.toml.local
-
 # Database
 *.db
 *.sqlite
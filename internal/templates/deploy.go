@@ -0,0 +1,531 @@
+package templates
+
+import "fmt"
+
+// The deployment templates are rendered by meba deploy render with the
+// values of internal/deploy, except the Helm chart which helm renders.
+
+func DeployConfigSection() string {
+	return `
+# Deployment (meba add deploy, meba deploy render --env <env>)
+deploy:
+  namespace: "default"
+  image: ""          # default <app.name>:<app.version>
+  replicas: 2
+  resources:
+    requests: { cpu: "100m", memory: "128Mi" }
+    limits: { cpu: "500m", memory: "256Mi" }
+  autoscaling:
+    enabled: true
+    min_replicas: 2
+    max_replicas: 10
+    target_cpu: 70
+`
+}
+
+func DeployEnvYaml(env string) string {
+	switch env {
+	case "prod":
+		return `# Overrides of configs/config.yaml for meba deploy render --env prod.
+# Secrets (jwt.secret, database.password) come from JWT_SECRET and
+# DATABASE_PASSWORD at render time, never from this file.
+app:
+  env: "production"
+  debug: false
+
+database:
+  host: "postgres"
+  sslmode: "require"
+
+logging:
+  level: "info"
+  format: "json"
+
+deploy:
+  replicas: 3
+  autoscaling:
+    enabled: true
+    min_replicas: 3
+    max_replicas: 20
+`
+	default:
+		return fmt.Sprintf(`# Overrides of configs/config.yaml for meba deploy render --env %s
+app:
+  env: "development"
+  debug: true
+
+database:
+  host: "postgres"
+
+deploy:
+  replicas: 1
+  autoscaling:
+    enabled: false
+`, env)
+	}
+}
+
+func K8sDeploymentYaml() string {
+	return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+    app.kubernetes.io/version: {{ quote .Version }}
+spec:
+  {{- if not .Autoscaling.Enabled }}
+  replicas: {{ .Replicas }}
+  {{- end }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Name }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{ .Name }}
+      annotations:
+        checksum/config: {{ .ConfigChecksum }}
+    spec:
+      terminationGracePeriodSeconds: {{ .StopGracePeriod }}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 65532
+        runAsGroup: 65532
+      containers:
+        - name: server
+          image: {{ .Image }}
+          ports:
+            - name: http
+              containerPort: {{ .Port }}
+          envFrom:
+            - secretRef:
+                name: {{ .Name }}
+          livenessProbe:
+            httpGet:
+              path: {{ .HealthPath }}/live
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+          readinessProbe:
+            httpGet:
+              path: {{ .HealthPath }}/ready
+              port: http
+            periodSeconds: 5
+          resources:
+            requests:
+              cpu: {{ .Resources.Requests.CPU }}
+              memory: {{ .Resources.Requests.Memory }}
+            limits:
+              cpu: {{ .Resources.Limits.CPU }}
+              memory: {{ .Resources.Limits.Memory }}
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]
+          volumeMounts:
+            - name: config
+              mountPath: /app/configs/config.yaml
+              subPath: config.yaml
+              readOnly: true
+            - name: tmp
+              mountPath: /tmp
+      volumes:
+        - name: config
+          configMap:
+            name: {{ .Name }}-config
+        - name: tmp
+          emptyDir: {}
+`
+}
+
+func K8sServiceYaml() string {
+	return `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+spec:
+  type: ClusterIP
+  selector:
+    app.kubernetes.io/name: {{ .Name }}
+  ports:
+    - name: http
+      port: 80
+      targetPort: http
+`
+}
+
+func K8sConfigMapYaml() string {
+	return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}-config
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+data:
+  config.yaml: |
+{{ indent 4 .ConfigYAML }}
+`
+}
+
+func K8sSecretYaml() string {
+	return `# Values come from the environment of meba deploy render, e.g.
+#   JWT_SECRET=... DATABASE_PASSWORD=... meba deploy render --env prod
+apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+type: Opaque
+stringData:
+{{- range .Secrets }}
+  {{ .Env }}: {{ quote (secret .Env) }}
+{{- end }}
+`
+}
+
+func K8sHPAYaml() string {
+	return `{{- if .Autoscaling.Enabled -}}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ .Name }}
+  minReplicas: {{ .Autoscaling.MinReplicas }}
+  maxReplicas: {{ .Autoscaling.MaxReplicas }}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .Autoscaling.TargetCPU }}
+{{- end }}
+`
+}
+
+func K8sServiceMonitorYaml() string {
+	return `{{- if .Metrics.Enabled -}}
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    app.kubernetes.io/name: {{ .Name }}
+spec:
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: {{ .Name }}
+  endpoints:
+    - port: http
+      path: {{ .Metrics.Path }}
+      interval: 30s
+{{- end }}
+`
+}
+
+func ComposeDeployYaml() string {
+	return `# Rendered by meba deploy render --target compose --env <env>.
+# Secrets are interpolated by docker compose from the environment.
+name: {{ .Name }}
+
+configs:
+  app_config:
+    content: |
+{{ indent 6 .ConfigYAML }}
+
+services:
+  app:
+    image: {{ .Image }}
+    ports:
+      - "{{ .Port }}:{{ .Port }}"
+    environment:
+{{- range .Secrets }}
+      {{ .Env }}: {{ printf "${%s:?set %s}" .Env .Env }}
+{{- end }}
+    configs:
+      - source: app_config
+        target: /app/configs/config.yaml
+    healthcheck:
+      test: ["CMD", "/app/server", "healthcheck", "http://127.0.0.1:{{ .Port }}{{ .HealthPath }}"]
+      interval: 30s
+      timeout: 3s
+      retries: 3
+    stop_grace_period: {{ .StopGracePeriod }}s
+    depends_on:
+      postgres:
+        condition: service_healthy
+    restart: unless-stopped
+
+  postgres:
+    image: postgres:15-alpine
+    environment:
+      POSTGRES_USER: {{ quote .Database.User }}
+      POSTGRES_DB: {{ quote .Database.DBName }}
+      POSTGRES_PASSWORD: ${DATABASE_PASSWORD:?set DATABASE_PASSWORD}
+    volumes:
+      - postgres_data:/var/lib/postgresql/data
+    healthcheck:
+      test: ["CMD-SHELL", "pg_isready -U {{ .Database.User }}"]
+      interval: 10s
+      timeout: 3s
+      retries: 5
+    restart: unless-stopped
+
+volumes:
+  postgres_data:
+`
+}
+
+func SystemdServiceUnit() string {
+	return `# Install the binary and configs/ in /opt/{{ .Name }}, the rendered env
+# file in /etc/{{ .Name }}/{{ .Name }}.env, then:
+#   systemctl enable --now {{ .Name }}
+[Unit]
+Description={{ .Name }} {{ .Version }}
+After=network-online.target
+Wants=network-online.target
+
+[Service]
+Type=simple
+DynamicUser=yes
+WorkingDirectory=/opt/{{ .Name }}
+ExecStart=/opt/{{ .Name }}/server
+EnvironmentFile=/etc/{{ .Name }}/{{ .Name }}.env
+Restart=on-failure
+RestartSec=5
+TimeoutStopSec={{ .StopGracePeriod }}
+
+NoNewPrivileges=yes
+ProtectSystem=strict
+ProtectHome=yes
+PrivateTmp=yes
+PrivateDevices=yes
+ProtectKernelTunables=yes
+ProtectControlGroups=yes
+RestrictSUIDSGID=yes
+CapabilityBoundingSet=
+StateDirectory={{ .Name }}
+
+[Install]
+WantedBy=multi-user.target
+`
+}
+
+func SystemdEnvFile() string {
+	return `# Environment of the {{ .Name }} service ({{ .Env }})
+APP_ENV={{ .Config.app.env }}
+{{- range .Secrets }}
+{{ .Env }}={{ secret .Env }}
+{{- end }}
+`
+}
+
+func SystemdConfigYaml() string {
+	return `# Install as /opt/{{ .Name }}/configs/config.yaml
+{{ .ConfigYAML }}`
+}
+
+func HelmChartYaml(name, version string) string {
+	return fmt.Sprintf(`apiVersion: v2
+name: %[1]s
+description: Helm chart for %[1]s, generated by meba add deploy helm
+type: application
+version: 0.1.0
+appVersion: %[2]q
+`, name, version)
+}
+
+func HelmHelpersTpl() string {
+	return `{{- define "app.labels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+{{- end }}
+
+{{- define "app.selectorLabels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+`
+}
+
+func HelmDeploymentYaml() string {
+	return `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  {{- if not .Values.autoscaling.enabled }}
+  replicas: {{ .Values.replicaCount }}
+  {{- end }}
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        {{- include "app.selectorLabels" . | nindent 8 }}
+      annotations:
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+        checksum/secret: {{ include (print $.Template.BasePath "/secret.yaml") . | sha256sum }}
+    spec:
+      terminationGracePeriodSeconds: {{ .Values.terminationGracePeriodSeconds }}
+      securityContext:
+        runAsNonRoot: true
+        runAsUser: 65532
+        runAsGroup: 65532
+      containers:
+        - name: server
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.service.targetPort }}
+          envFrom:
+            - secretRef:
+                name: {{ .Release.Name }}
+          livenessProbe:
+            httpGet:
+              path: {{ .Values.probes.path }}/live
+              port: http
+            initialDelaySeconds: 5
+            periodSeconds: 10
+          readinessProbe:
+            httpGet:
+              path: {{ .Values.probes.path }}/ready
+              port: http
+            periodSeconds: 5
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+          securityContext:
+            allowPrivilegeEscalation: false
+            readOnlyRootFilesystem: true
+            capabilities:
+              drop: ["ALL"]
+          volumeMounts:
+            - name: config
+              mountPath: /app/configs/config.yaml
+              subPath: config.yaml
+              readOnly: true
+            - name: tmp
+              mountPath: /tmp
+      volumes:
+        - name: config
+          configMap:
+            name: {{ .Release.Name }}-config
+        - name: tmp
+          emptyDir: {}
+`
+}
+
+func HelmServiceYaml() string {
+	return `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    {{- include "app.selectorLabels" . | nindent 4 }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+`
+}
+
+func HelmConfigMapYaml() string {
+	return `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-config
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+data:
+  config.yaml: |
+    {{- toYaml .Values.config | nindent 4 }}
+`
+}
+
+func HelmSecretYaml() string {
+	return `apiVersion: v1
+kind: Secret
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+type: Opaque
+stringData:
+  {{- range $key, $value := .Values.secrets }}
+  {{ $key }}: {{ required (printf "set secrets.%s" $key) $value | quote }}
+  {{- end }}
+`
+}
+
+func HelmHPAYaml() string {
+	return `{{- if .Values.autoscaling.enabled }}
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: {{ .Release.Name }}
+  minReplicas: {{ .Values.autoscaling.minReplicas }}
+  maxReplicas: {{ .Values.autoscaling.maxReplicas }}
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{ .Values.autoscaling.targetCPUUtilizationPercentage }}
+{{- end }}
+`
+}
+
+func HelmServiceMonitorYaml() string {
+	return `{{- if .Values.metrics.enabled }}
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  name: {{ .Release.Name }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+  endpoints:
+    - port: http
+      path: {{ .Values.metrics.path }}
+      interval: 30s
+{{- end }}
+`
+}
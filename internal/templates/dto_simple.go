@@ -5,7 +5,7 @@ import (
 	"strings"
 )
 
-func ModuleDtoGoSimple(name string) string {
+func ModuleDtoGoSimple(name, pagination string) string {
 	titleName := strings.Title(name)
 	return fmt.Sprintf(`package %s
 
@@ -37,10 +37,34 @@ type %sResponse struct {
 	// Status      string `+"`json:\"status\"`"+`
 }
 
-// PaginationRequest provides common pagination parameters
+`, name, titleName, name, titleName, titleName, name, titleName, titleName, name, titleName) + paginationDtoGo(pagination)
+}
+
+// paginationDtoGo is the request and response of the list endpoint of a
+// resource, paginated by page or by cursor
+func paginationDtoGo(pagination string) string {
+	if pagination == PaginationCursor {
+		return `// CursorRequest provides cursor pagination parameters
+type CursorRequest struct {
+	Cursor string `+"`json:\"cursor\" form:\"cursor\"`"+`
+	Limit  int    `+"`json:\"limit\" form:\"limit\" validate:\"omitempty,min=1,max=100\"`"+`
+}
+
+// CursorResponse provides a page of cursor pagination. NextCursor and
+// PrevCursor are the cursor parameters of the following and preceding
+// pages, empty when there is none.
+type CursorResponse struct {
+	Limit      int         `+"`json:\"limit\"`"+`
+	NextCursor string      `+"`json:\"next_cursor,omitempty\"`"+`
+	PrevCursor string      `+"`json:\"prev_cursor,omitempty\"`"+`
+	Data       interface{} `+"`json:\"data\"`"+`
+}
+`
+	}
+	return `// PaginationRequest provides common pagination parameters
 type PaginationRequest struct {
-	Page     int `+"`json:\"page\" form:\"page\" validate:\"min=1\"`"+`
-	PageSize int `+"`json:\"page_size\" form:\"page_size\" validate:\"min=1,max=100\"`"+`
+	Page     int `+"`json:\"page\" form:\"page\" validate:\"omitempty,min=1\"`"+`
+	PageSize int `+"`json:\"page_size\" form:\"page_size\" validate:\"omitempty,min=1,max=100\"`"+`
 }
 
 // PaginationResponse provides common pagination response
@@ -51,5 +75,5 @@ type PaginationResponse struct {
 	TotalPages int         `+"`json:\"total_pages\"`"+`
 	Data       interface{} `+"`json:\"data\"`"+`
 }
-`, name, titleName, name, titleName, titleName, name, titleName, titleName, name, titleName)
+`
 }
\ No newline at end of file
@@ -0,0 +1,647 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+func E2EHarnessGo(moduleName string) string {
+	return fmt.Sprintf(`// Package harness boots the real application for end-to-end tests.
+//
+// Every test gets its own SQLite database, copied from a template that is
+// migrated and seeded once per test run, and the app built by
+// internal.InitializeTestApp served with httptest.
+package harness
+
+import (
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"%[1]s/configs"
+	"%[1]s/internal"
+	apperrors "%[1]s/pkg/errors"
+	"%[1]s/pkg/middleware"
+	"%[1]s/pkg/validator"
+	"%[1]s/test/e2e/apitest"
+)
+
+var (
+	models []interface{}
+	seeds  []func(db *gorm.DB) error
+
+	templateOnce sync.Once
+	templateDir  string
+	templatePath string
+	templateErr  error
+
+	// config is the application config from the configs directory of the
+	// project, loaded once with the template database
+	config *configs.Config
+)
+
+// RegisterModels adds entities to migrate into the test database. Call it
+// from an init function of the test file that needs them.
+func RegisterModels(m ...interface{}) {
+	models = append(models, m...)
+}
+
+// RegisterSeed adds a function that inserts data into the template
+// database after the migrations
+func RegisterSeed(seed func(db *gorm.DB) error) {
+	seeds = append(seeds, seed)
+}
+
+// Run runs the tests and removes the template database afterwards. Call
+// it from TestMain.
+func Run(m *testing.M) int {
+	gin.SetMode(gin.TestMode)
+	validator.Setup()
+	code := m.Run()
+	if templateDir != "" {
+		os.RemoveAll(templateDir)
+	}
+	return code
+}
+
+// App is a running instance of the application
+type App struct {
+	*apitest.Client
+	DB     *gorm.DB
+	Server *httptest.Server
+}
+
+// Start boots the application on a fresh copy of the test database, with
+// errors rendered as problem+json. It is stopped when the test ends.
+func Start(t *testing.T) *App {
+	t.Helper()
+
+	templateOnce.Do(buildTemplate)
+	if templateErr != nil {
+		t.Fatalf("failed to prepare the test database: %%v", templateErr)
+	}
+
+	path := filepath.Join(t.TempDir(), "e2e.db")
+	if err := copyFile(templatePath, path); err != nil {
+		t.Fatalf("failed to copy the test database: %%v", err)
+	}
+	db, err := open(path)
+	if err != nil {
+		t.Fatalf("failed to open the test database: %%v", err)
+	}
+
+	app, cleanup, err := internal.InitializeTestApp(config, db)
+	if err != nil {
+		t.Fatalf("failed to initialize the app: %%v", err)
+	}
+	router := gin.New()
+	router.Use(middleware.Errors(apperrors.FormatProblem), middleware.Recovery())
+	app.SetupRoutes(router)
+	server := httptest.NewServer(router)
+
+	t.Cleanup(func() {
+		server.Close()
+		cleanup()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return &App{
+		Client: apitest.New(t, server.URL),
+		DB:     db,
+		Server: server,
+	}
+}
+
+func open(path string) (*gorm.DB, error) {
+	return gorm.Open(sqlite.Open(path+"?_pragma=foreign_keys(1)"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+}
+
+// buildTemplate loads the config and migrates and seeds the database every
+// test starts from: registered models, then testdata/migrations/*.sql,
+// registered seeds and testdata/seeds/*.sql
+func buildTemplate() {
+	config, templateErr = configs.LoadConfig(filepath.Join("..", "..", "configs"))
+	if templateErr != nil {
+		return
+	}
+	templateDir, templateErr = os.MkdirTemp("", "e2e-template-")
+	if templateErr != nil {
+		return
+	}
+	templatePath = filepath.Join(templateDir, "template.db")
+
+	db, err := open(templatePath)
+	if err != nil {
+		templateErr = err
+		return
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	if err := db.AutoMigrate(models...); err != nil {
+		templateErr = fmt.Errorf("migrate: %%w", err)
+		return
+	}
+	if err := execSQLFiles(db, filepath.Join("testdata", "migrations")); err != nil {
+		templateErr = err
+		return
+	}
+	for _, seed := range seeds {
+		if err := seed(db); err != nil {
+			templateErr = fmt.Errorf("seed: %%w", err)
+			return
+		}
+	}
+	templateErr = execSQLFiles(db, filepath.Join("testdata", "seeds"))
+}
+
+// execSQLFiles runs the .sql files of dir in name order
+func execSQLFiles(db *gorm.DB, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := db.Exec(string(sql)).Error; err != nil {
+			return fmt.Errorf("%%s: %%w", file, err)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+`, moduleName)
+}
+
+func E2EApiTestGo(moduleName string) string {
+	return fmt.Sprintf(`// Package apitest is a small fluent client for end-to-end API tests:
+//
+//	app.POST("/api/v1/users", body).Expect().
+//		Status(http.StatusCreated).
+//		JSON("success", true).
+//		Has("data.id")
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"%s/pkg/snapshot"
+)
+
+// Client sends requests to a running server
+type Client struct {
+	t       testing.TB
+	baseURL string
+	http    *http.Client
+	headers http.Header
+}
+
+// New creates a client for the server at baseURL
+func New(t testing.TB, baseURL string) *Client {
+	return &Client{t: t, baseURL: strings.TrimSuffix(baseURL, "/"), http: &http.Client{}, headers: http.Header{}}
+}
+
+// WithHeader returns a client that sends the header with every request
+func (c *Client) WithHeader(key, value string) *Client {
+	clone := *c
+	clone.headers = c.headers.Clone()
+	clone.headers.Set(key, value)
+	return &clone
+}
+
+// WithToken returns a client that sends a bearer token with every request
+func (c *Client) WithToken(token string) *Client {
+	return c.WithHeader("Authorization", "Bearer "+token)
+}
+
+// GET starts a GET request
+func (c *Client) GET(path string) *Request {
+	return c.request(http.MethodGet, path, nil)
+}
+
+// POST starts a POST request with a JSON body
+func (c *Client) POST(path string, body interface{}) *Request {
+	return c.request(http.MethodPost, path, body)
+}
+
+// PUT starts a PUT request with a JSON body
+func (c *Client) PUT(path string, body interface{}) *Request {
+	return c.request(http.MethodPut, path, body)
+}
+
+// PATCH starts a PATCH request with a JSON body
+func (c *Client) PATCH(path string, body interface{}) *Request {
+	return c.request(http.MethodPatch, path, body)
+}
+
+// DELETE starts a DELETE request
+func (c *Client) DELETE(path string) *Request {
+	return c.request(http.MethodDelete, path, nil)
+}
+
+func (c *Client) request(method, path string, body interface{}) *Request {
+	return &Request{client: c, method: method, path: path, body: body, query: url.Values{}, headers: c.headers.Clone()}
+}
+
+// Request is a request being built
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	body    interface{}
+	query   url.Values
+	headers http.Header
+}
+
+// Query adds a query parameter
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// Header sets a request header
+func (r *Request) Header(key, value string) *Request {
+	r.headers.Set(key, value)
+	return r
+}
+
+// Expect sends the request and returns the response to assert on
+func (r *Request) Expect() *Response {
+	t := r.client.t
+	t.Helper()
+
+	target := r.client.baseURL + r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	var body io.Reader
+	if r.body != nil {
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			t.Fatalf("%%s %%s: cannot encode body: %%v", r.method, r.path, err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(r.method, target, body)
+	if err != nil {
+		t.Fatalf("%%s %%s: %%v", r.method, r.path, err)
+	}
+	req.Header = r.headers
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.http.Do(req)
+	if err != nil {
+		t.Fatalf("%%s %%s: %%v", r.method, r.path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("%%s %%s: cannot read body: %%v", r.method, r.path, err)
+	}
+
+	return &Response{
+		t:          t,
+		name:       r.method + " " + r.path,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       data,
+	}
+}
+
+// Response is a received response
+type Response struct {
+	t          testing.TB
+	name       string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+
+	decoded bool
+	json    interface{}
+}
+
+// Status fails the test immediately unless the status code matches, since
+// later assertions would only add noise
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.StatusCode != code {
+		r.t.Fatalf("%%s: status %%d, want %%d\nbody: %%s", r.name, r.StatusCode, code, r.Body)
+	}
+	return r
+}
+
+// JSON asserts the value at a dot-separated path, e.g. "data.items.0.id".
+// Numbers compare by value, so JSON("data.id", 1) matches 1.0.
+func (r *Response) JSON(path string, want interface{}) *Response {
+	r.t.Helper()
+	got, ok := r.lookup(path)
+	if !ok {
+		r.t.Errorf("%%s: %%s is missing\nbody: %%s", r.name, path, r.Body)
+		return r
+	}
+	if !reflect.DeepEqual(got, normalize(r.t, want)) {
+		r.t.Errorf("%%s: %%s is %%v, want %%v", r.name, path, got, want)
+	}
+	return r
+}
+
+// Has asserts that the path exists
+func (r *Response) Has(path string) *Response {
+	r.t.Helper()
+	if _, ok := r.lookup(path); !ok {
+		r.t.Errorf("%%s: %%s is missing\nbody: %%s", r.name, path, r.Body)
+	}
+	return r
+}
+
+// Len asserts the length of the array at path
+func (r *Response) Len(path string, want int) *Response {
+	r.t.Helper()
+	got, _ := r.lookup(path)
+	items, ok := got.([]interface{})
+	if !ok {
+		r.t.Errorf("%%s: %%s is not an array\nbody: %%s", r.name, path, r.Body)
+	} else if len(items) != want {
+		r.t.Errorf("%%s: %%s has %%d items, want %%d", r.name, path, len(items), want)
+	}
+	return r
+}
+
+// Contains asserts that the raw body contains s
+func (r *Response) Contains(s string) *Response {
+	r.t.Helper()
+	if !strings.Contains(string(r.Body), s) {
+		r.t.Errorf("%%s: body does not contain %%q\nbody: %%s", r.name, s, r.Body)
+	}
+	return r
+}
+
+// MatchSnapshot compares the body with the snapshot of the test in
+// __snapshots__, see package snapshot
+func (r *Response) MatchSnapshot(opts ...snapshot.Option) *Response {
+	r.t.Helper()
+	snapshot.MatchJSON(r.t, r.Body, opts...)
+	return r
+}
+
+// Decode unmarshals the body into v
+func (r *Response) Decode(v interface{}) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("%%s: cannot decode body: %%v\nbody: %%s", r.name, err, r.Body)
+	}
+	return r
+}
+
+// Get returns the value at path, or nil
+func (r *Response) Get(path string) interface{} {
+	v, _ := r.lookup(path)
+	return v
+}
+
+// Int returns the number at path, failing the test if there is none
+func (r *Response) Int(path string) int {
+	r.t.Helper()
+	n, ok := r.Get(path).(float64)
+	if !ok {
+		r.t.Fatalf("%%s: %%s is not a number\nbody: %%s", r.name, path, r.Body)
+	}
+	return int(n)
+}
+
+// String returns the string at path, failing the test if there is none
+func (r *Response) String(path string) string {
+	r.t.Helper()
+	s, ok := r.Get(path).(string)
+	if !ok {
+		r.t.Fatalf("%%s: %%s is not a string\nbody: %%s", r.name, path, r.Body)
+	}
+	return s
+}
+
+func (r *Response) lookup(path string) (interface{}, bool) {
+	r.t.Helper()
+	if !r.decoded {
+		r.decoded = true
+		if err := json.Unmarshal(r.Body, &r.json); err != nil {
+			r.t.Fatalf("%%s: body is not JSON: %%v\nbody: %%s", r.name, err, r.Body)
+		}
+	}
+	current := r.json
+	if path == "" {
+		return current, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			current = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// normalize converts want to the types encoding/json decodes into
+func normalize(t testing.TB, want interface{}) interface{} {
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("cannot compare with %%v: %%v", want, err)
+	}
+	var v interface{}
+	json.Unmarshal(data, &v)
+	return v
+}
+
+// Path joins path segments, e.g. Path("/api/v1/users", id)
+func Path(segments ...interface{}) string {
+	parts := make([]string, len(segments))
+	for i, s := range segments {
+		parts[i] = strings.Trim(fmt.Sprint(s), "/")
+	}
+	return "/" + strings.Join(parts, "/")
+}
+`, moduleName)
+}
+
+func E2EMainTestGo(moduleName string) string {
+	return fmt.Sprintf(`package e2e
+
+import (
+	"os"
+	"testing"
+
+	"%s/test/e2e/harness"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(harness.Run(m))
+}
+`, moduleName)
+}
+
+func E2EHealthTestGo(moduleName, basePath string) string {
+	return fmt.Sprintf(`package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"%[1]s/test/e2e/harness"
+)
+
+func TestHealth(t *testing.T) {
+	app := harness.Start(t)
+
+	app.GET("%[2]s/health").Expect().
+		Status(http.StatusOK).
+		JSON("status", "ok")
+}
+`, moduleName, basePath)
+}
+
+func E2EResourceTestGo(moduleName, name, basePath, pagination string) string {
+	title := strings.Title(name)
+	return fmt.Sprintf(`package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"%[1]s/internal/%[2]s"
+	"%[1]s/pkg/snapshot"
+	"%[1]s/test/e2e/apitest"
+	"%[1]s/test/e2e/harness"
+)
+
+func init() {
+	harness.RegisterModels(&%[2]s.%[3]s{})
+}
+
+func Test%[3]sCRUD(t *testing.T) {
+	app := harness.Start(t)
+	base := "%[4]s/%[2]s"
+
+	// Add the required fields of Create%[3]sRequest
+	created := app.POST(base, map[string]interface{}{}).Expect().
+		Status(http.StatusCreated).
+		JSON("success", true).
+		Has("data.id").
+		MatchSnapshot(snapshot.Name("create"))
+	id := created.Int("data.id")
+
+	app.GET(apitest.Path(base, id)).Expect().
+		Status(http.StatusOK).
+		JSON("data.id", id).
+		MatchSnapshot(snapshot.Name("get"))
+
+%[5]s
+	app.PUT(apitest.Path(base, id), map[string]interface{}{}).Expect().
+		Status(http.StatusOK).
+		JSON("data.id", id)
+
+	app.DELETE(apitest.Path(base, id)).Expect().
+		Status(http.StatusOK).
+		JSON("success", true)
+
+	app.GET(apitest.Path(base, id)).Expect().
+		Status(http.StatusNotFound).
+		JSON("code", "not_found")
+}
+
+func Test%[3]sValidation(t *testing.T) {
+	app := harness.Start(t)
+	base := "%[4]s/%[2]s"
+
+	app.GET(apitest.Path(base, "not-a-number")).Expect().
+		Status(http.StatusBadRequest).
+		JSON("code", "validation").
+		Has("errors.id")
+
+	app.GET(apitest.Path(base, 999999)).Expect().
+		Status(http.StatusNotFound).
+		JSON("status", http.StatusNotFound)
+}
+`, moduleName, name, title, basePath, e2eListGo(pagination))
+}
+
+// e2eListGo lists the created item in the CRUD e2e test, by page or by
+// cursor
+func e2eListGo(pagination string) string {
+	if pagination == PaginationCursor {
+		return `	app.GET(base).Query("limit", "10").Expect().
+		Status(http.StatusOK).
+		JSON("data.limit", 10).
+		Len("data.data", 1).
+		MatchSnapshot(snapshot.Name("list"))
+`
+	}
+	return `	app.GET(base).Query("page", "1").Query("page_size", "10").Expect().
+		Status(http.StatusOK).
+		JSON("data.total", 1).
+		Len("data.data", 1).
+		MatchSnapshot(snapshot.Name("list"))
+`
+}
@@ -5,43 +5,49 @@ import (
 	"strings"
 )
 
-func ModuleEntityGoSimple(name string) string {
+func ModuleEntityGoSimple(moduleName, name string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
 	"time"
 	"gorm.io/gorm"
+
+	"%[3]s/pkg/query"
 )
 
-// %s represents the %s entity
-type %s struct {
+// %[2]s represents the %[1]s entity
+type %[2]s struct {
 	ID        uint           `+"`json:\"id\" gorm:\"primarykey\"`"+`
 	CreatedAt time.Time      `+"`json:\"created_at\"`"+`
 	UpdatedAt time.Time      `+"`json:\"updated_at\"`"+`
 	DeletedAt gorm.DeletedAt `+"`json:\"deleted_at\" gorm:\"index\"`"+`
 	
-	// Add your %s fields here
+	// Add your %[1]s fields here
 	// Name        string `+"`json:\"name\" gorm:\"not null\" validate:\"required\"`"+`
 	// Description string `+"`json:\"description\"`"+`
 	// Status      string `+"`json:\"status\" gorm:\"default:active\"`"+`
 }
 
-// TableName returns the table name for %s
-func (e %s) TableName() string {
-	return "%s"
+// queryFields are the fields of %[2]s that the handlers and the service
+// filter, sort, select and search on; tag a field query:"-" to keep it out
+var queryFields = query.FieldsOf(&%[2]s{})
+
+// TableName returns the table name for %[2]s
+func (e %[2]s) TableName() string {
+	return "%[1]s"
 }
 
 // BeforeCreate hook
-func (e *%s) BeforeCreate(tx *gorm.DB) error {
+func (e *%[2]s) BeforeCreate(tx *gorm.DB) error {
 	// Add any pre-creation logic here
 	return nil
 }
 
 // BeforeUpdate hook
-func (e *%s) BeforeUpdate(tx *gorm.DB) error {
+func (e *%[2]s) BeforeUpdate(tx *gorm.DB) error {
 	// Add any pre-update logic here
 	return nil
 }
-`, name, titleName, name, titleName, name, titleName, titleName, titleName, titleName, titleName)
+`, name, titleName, moduleName)
 }
\ No newline at end of file
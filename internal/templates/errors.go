@@ -0,0 +1,206 @@
+package templates
+
+import "fmt"
+
+// ErrorsGo is pkg/errors: the typed application errors returned by
+// services and repositories and their problem+json representation
+func ErrorsGo(moduleName string) string {
+	return fmt.Sprintf(`// Package errors defines the application errors returned by services and
+// repositories. The Errors middleware renders them as problem+json or as
+// the BaseResponse envelope; their causes are logged but never sent.
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"net/http"
+
+	"gorm.io/gorm"
+	"%s/pkg/validator"
+)
+
+// Response formats of the Errors middleware, set by server.error_format
+const (
+	FormatProblem  = "problem"
+	FormatEnvelope = "envelope"
+)
+
+// Kind classifies an application error and selects its HTTP status
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindInternal     Kind = "internal"
+)
+
+var statuses = map[Kind]int{
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindValidation:   http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindInternal:     http.StatusInternalServerError,
+}
+
+// Error is an application error. Message and Fields are shown to clients,
+// Err is the cause and only appears in logs.
+type Error struct {
+	Kind    Kind
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status of the error
+func (e *Error) Status() int {
+	if status, ok := statuses[e.Kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Wrap returns a copy of e caused by err, so errors.Is still matches err
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// NotFound reports a missing resource
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+// Conflict reports a request that clashes with the current state, such as
+// a duplicate key
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+// Validation reports invalid input, with an optional message per field
+func Validation(message string, fields map[string]string) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// Unauthorized reports a missing or invalid authentication
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+// Forbidden reports an authenticated caller lacking permission
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+// Internal wraps an unexpected error; clients only see a generic message
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: "Internal server error", Err: err}
+}
+
+// Invalid returns the Validation error for a request that failed to bind,
+// with a message per field when the validator rejected it
+func Invalid(err error) *Error {
+	if fields, ok := validator.Translate(err, ""); ok {
+		return Validation("Validation failed", fields).Wrap(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case stderrors.As(err, &typeErr):
+		return Validation("Invalid request body", map[string]string{
+			typeErr.Field: "must be a " + typeErr.Type.String(),
+		}).Wrap(err)
+	case stderrors.Is(err, io.EOF):
+		return Validation("Request body is empty", nil).Wrap(err)
+	case stderrors.As(err, &syntaxErr), stderrors.Is(err, io.ErrUnexpectedEOF):
+		return Validation("Malformed JSON body", nil).Wrap(err)
+	}
+	return Validation("Invalid request", nil).Wrap(err)
+}
+
+// Translate returns e with the messages of the fields the validator
+// rejected in the language an Accept-Language header prefers
+func (e *Error) Translate(acceptLanguage string) *Error {
+	if acceptLanguage == "" {
+		return e
+	}
+	if fields, ok := validator.Translate(e.Err, acceptLanguage); ok {
+		translated := *e
+		translated.Fields = fields
+		return &translated
+	}
+	return e
+}
+
+// FromDB maps the GORM errors clients should see, record not found and
+// duplicated key (with gorm.Config.TranslateError), to NotFound and
+// Conflict, and returns any other error unchanged
+func FromDB(err error) error {
+	switch {
+	case stderrors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound("Resource not found").Wrap(err)
+	case stderrors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict("Resource already exists").Wrap(err)
+	}
+	return err
+}
+
+// From returns the application error in err's chain, or an Internal error
+// wrapping err
+func From(err error) *Error {
+	var appErr *Error
+	if stderrors.As(err, &appErr) {
+		return appErr
+	}
+	if mapped := FromDB(err); stderrors.As(mapped, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// Problem is an RFC 7807 problem details object. Code, RequestID and
+// Errors are extension members.
+type Problem struct {
+	Type      string            `+"`json:\"type\"`"+`
+	Title     string            `+"`json:\"title\"`"+`
+	Status    int               `+"`json:\"status\"`"+`
+	Detail    string            `+"`json:\"detail,omitempty\"`"+`
+	Instance  string            `+"`json:\"instance,omitempty\"`"+`
+	Code      Kind              `+"`json:\"code\"`"+`
+	RequestID string            `+"`json:\"request_id,omitempty\"`"+`
+	Errors    map[string]string `+"`json:\"errors,omitempty\"`"+`
+}
+
+// Problem returns the problem details of e for the request at instance
+func (e *Error) Problem(instance, requestID string) Problem {
+	status := e.Status()
+	return Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    e.Message,
+		Instance:  instance,
+		Code:      e.Kind,
+		RequestID: requestID,
+		Errors:    e.Fields,
+	}
+}
+`, moduleName)
+}
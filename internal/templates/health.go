@@ -0,0 +1,465 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HealthGo is pkg/health: the checker registry and the live and ready
+// endpoints
+func HealthGo() string {
+	return `// Package health runs the health checks of the application and serves
+// them as liveness and readiness endpoints.
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTimeout bounds a check that sets no timeout of its own
+const DefaultTimeout = 3 * time.Second
+
+// Checker checks one dependency of the application
+type Checker interface {
+	// Name identifies the check in the report, e.g. database
+	Name() string
+	// Check returns an error when the dependency is unavailable
+	Check(ctx context.Context) error
+}
+
+// Status is the state of a check or of the whole application
+type Status string
+
+const (
+	StatusOK   Status = "ok"
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of one check
+type Result struct {
+	Status Status ` + "`json:\"status\"`" + `
+	// Latency is how long the check took, e.g. 1.2ms
+	Latency string ` + "`json:\"latency\"`" + `
+	Error   string ` + "`json:\"error,omitempty\"`" + `
+}
+
+// Report is the outcome of all checks; it is down when any check is
+type Report struct {
+	Status Status            ` + "`json:\"status\"`" + `
+	Checks map[string]Result ` + "`json:\"checks,omitempty\"`" + `
+}
+
+// Health holds the checkers of the application. A nil *Health has no
+// checks and is always ready.
+type Health struct {
+	mu       sync.RWMutex
+	checkers []Checker
+	timeout  time.Duration
+}
+
+// New creates a registry with the given checkers
+func New(checkers ...Checker) *Health {
+	return &Health{checkers: checkers}
+}
+
+// Add registers checkers; nil checkers are ignored
+func (h *Health) Add(checkers ...Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range checkers {
+		if c != nil {
+			h.checkers = append(h.checkers, c)
+		}
+	}
+}
+
+// SetTimeout bounds each check that sets no timeout of its own, instead of
+// DefaultTimeout; zero keeps DefaultTimeout
+func (h *Health) SetTimeout(timeout time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeout = timeout
+}
+
+// Check runs every check concurrently, each within its timeout
+func (h *Health) Check(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: map[string]Result{}}
+	if h == nil {
+		return report
+	}
+	h.mu.RLock()
+	checkers := append([]Checker(nil), h.checkers...)
+	timeout := h.timeout
+	h.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, c := range checkers {
+		wg.Add(1)
+		go func(i int, c Checker) {
+			defer wg.Done()
+			results[i] = run(ctx, c, timeout)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checkers {
+		report.Checks[c.Name()] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Names returns the names of the registered checks
+func (h *Health) Names() []string {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.checkers))
+	for _, c := range h.checkers {
+		names = append(names, c.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func run(ctx context.Context, c Checker, timeout time.Duration) Result {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if t, ok := c.(interface{ Timeout() time.Duration }); ok && t.Timeout() > 0 {
+		timeout = t.Timeout()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- panicError{r}
+			}
+		}()
+		done <- c.Check(ctx)
+	}()
+
+	// A checker that ignores its context still reports down in time
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	latency := time.Since(start)
+	result := Result{Status: StatusOK, Latency: latency.Round(time.Microsecond).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Register mounts the endpoints on r, a group at the health path such as
+// /api/v1/health:
+//
+//	GET <path>        every check
+//	GET <path>/live   200 while the process serves requests
+//	GET <path>/ready  every check, 503 when one is down
+func (h *Health) Register(r gin.IRouter) {
+	r.GET("", h.ready)
+	r.GET("/live", h.live)
+	r.GET("/ready", h.ready)
+}
+
+// live reports the process is up without checking its dependencies, so
+// an outage of the database does not get every pod restarted
+//
+// @Summary Liveness check
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+func (h *Health) live(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// ready runs the checks and reports 503 when one is down
+//
+// @Summary Readiness check
+// @Description Runs every health check; 503 when one is down
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+func (h *Health) ready(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+type panicError struct{ value interface{} }
+
+func (e panicError) Error() string {
+	return "check panicked: " + fmt.Sprint(e.value)
+}
+`
+}
+
+// HealthCheckersGo is pkg/health/checkers.go with the built-in checkers
+func HealthCheckersGo() string {
+	return `package health
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Func adapts a function to a Checker
+type Func struct {
+	CheckName string
+	Fn        func(ctx context.Context) error
+}
+
+func (f Func) Name() string                    { return f.CheckName }
+func (f Func) Check(ctx context.Context) error { return f.Fn(ctx) }
+
+type withTimeout struct {
+	Checker
+	timeout time.Duration
+}
+
+func (c withTimeout) Timeout() time.Duration { return c.timeout }
+
+// WithTimeout bounds c by timeout instead of DefaultTimeout
+func WithTimeout(c Checker, timeout time.Duration) Checker {
+	return withTimeout{Checker: c, timeout: timeout}
+}
+
+// DB pings the database. A nil db reports down as not configured, so a
+// missing connection does not pass as ready.
+func DB(db *gorm.DB) Checker {
+	return Func{CheckName: "database", Fn: func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("not configured")
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}}
+}
+
+// Redis sends PING to the Redis server at addr (host:port), after AUTH
+// when password is set
+func Redis(addr, password string) Checker {
+	return Func{CheckName: "redis", Fn: func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		r := bufio.NewReader(conn)
+		if password != "" {
+			if err := redisCommand(conn, r, "+OK", "AUTH", password); err != nil {
+				return err
+			}
+		}
+		return redisCommand(conn, r, "+PONG", "PING")
+	}}
+}
+
+func redisCommand(conn net.Conn, r *bufio.Reader, want string, args ...string) error {
+	cmd := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		cmd += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(cmd)); err != nil {
+		return err
+	}
+	reply, err := r.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSpace(reply); reply != want {
+		return fmt.Errorf("%s: %s", args[0], strings.TrimPrefix(reply, "-"))
+	}
+	return nil
+}
+
+// HTTP checks that a GET of url answers below 400, e.g. a payment
+// provider the application depends on
+func HTTP(name, url string) Checker {
+	client := &http.Client{
+		// Do not follow redirects to a login page and report it healthy
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return Func{CheckName: name, Fn: func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return errors.New(resp.Status)
+		}
+		return nil
+	}}
+}
+
+// Disk checks that the filesystem holding path has at least minFree
+// bytes available, e.g. for uploads
+func Disk(path string, minFree uint64) Checker {
+	return Func{CheckName: "disk", Fn: func(ctx context.Context) error {
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%s has %d MB free, below %d MB", path, free>>20, minFree>>20)
+		}
+		return nil
+	}}
+}
+`
+}
+
+// HealthDiskUnixGo is the statfs implementation of the disk checker
+func HealthDiskUnixGo() string {
+	return `//go:build linux || darwin
+
+package health
+
+import "syscall"
+
+func diskFree(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+`
+}
+
+// HealthDiskOtherGo reports the disk checker as unsupported elsewhere
+func HealthDiskOtherGo() string {
+	return `//go:build !linux && !darwin
+
+package health
+
+import (
+	"errors"
+	"runtime"
+)
+
+func diskFree(path string) (uint64, error) {
+	return 0, errors.New("disk check is not supported on " + runtime.GOOS)
+}
+`
+}
+
+// AppHealthGo is internal/health.go, which wires the checkers of the
+// application; meba g health-check adds custom ones to it
+func AppHealthGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
+
+import (
+	"github.com/google/wire"
+	"gorm.io/gorm"
+	"%[1]s/configs"
+	"%[1]s/pkg/health"
+)
+
+// NewHealth creates the health checks served on
+// monitoring.health_check.path. Each check runs within
+// monitoring.health_check.timeout unless it sets its own. Built-in
+// checkers can be added here too, e.g.
+//
+//	h.Add(health.Redis("localhost:6379", ""))
+//	h.Add(health.Disk("uploads", 100<<20))
+//	h.Add(health.WithTimeout(health.HTTP("payments", "https://status.example.com"), time.Second))
+func NewHealth(cfg *configs.Config, db *gorm.DB) *health.Health {
+	h := health.New()
+	h.SetTimeout(cfg.Monitoring.HealthCheck.Timeout)
+	h.Add(health.DB(db))
+	// Custom checkers are added here by meba g health-check
+	return h
+}
+
+// HealthSet is the wire set for the health checks
+var HealthSet = wire.NewSet(
+	NewHealth,
+)
+`, moduleName)
+}
+
+// HealthCheckGo is a custom checker in internal/checks
+func HealthCheckGo(name string) string {
+	titleName := strings.Title(name)
+	return fmt.Sprintf(`package checks
+
+import (
+	"context"
+	"time"
+)
+
+// %[2]sChecker checks %[1]s for /health/ready
+type %[2]sChecker struct {
+	// Add dependencies here
+}
+
+// New%[2]sChecker creates a new %[1]s checker
+func New%[2]sChecker() *%[2]sChecker {
+	return &%[2]sChecker{}
+}
+
+// Name identifies the check in the health report
+func (c *%[2]sChecker) Name() string {
+	return "%[1]s"
+}
+
+// Timeout bounds Check
+func (c *%[2]sChecker) Timeout() time.Duration {
+	return 2 * time.Second
+}
+
+// Check returns an error when %[1]s is unavailable
+func (c *%[2]sChecker) Check(ctx context.Context) error {
+	// TODO: implement the check, e.g. ping a client
+	return nil
+}
+`, name, titleName)
+}
@@ -0,0 +1,171 @@
+package templates
+
+// LifecycleGo is pkg/lifecycle, the NestJS-style module hooks run by the
+// generated main around the life of the HTTP server
+func LifecycleGo() string {
+	return `// Package lifecycle runs the startup and shutdown hooks of the
+// application, NestJS-style. Any handler, service or repository reachable
+// from the app that implements OnModuleInit or OnApplicationShutdown is
+// called without registering it.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// OnModuleInit is implemented by components that prepare themselves once
+// the application is wired, before the server accepts requests
+type OnModuleInit interface {
+	OnModuleInit(ctx context.Context) error
+}
+
+// OnApplicationShutdown is implemented by components that release their
+// resources after the server has drained its requests
+type OnApplicationShutdown interface {
+	OnApplicationShutdown(ctx context.Context) error
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Hooks holds the components with lifecycle hooks and the shutdown
+// functions registered with OnShutdown
+type Hooks struct {
+	mu         sync.Mutex
+	components []interface{}
+	shutdown   []hook
+}
+
+// New creates an empty set of hooks
+func New() *Hooks {
+	return &Hooks{}
+}
+
+// Register adds components implementing OnModuleInit or
+// OnApplicationShutdown; others are ignored
+func (h *Hooks) Register(components ...interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range components {
+		_, init := c.(OnModuleInit)
+		_, shutdown := c.(OnApplicationShutdown)
+		if init || shutdown {
+			h.components = append(h.components, c)
+		}
+	}
+}
+
+// Discover registers every component reachable through the fields of
+// roots, e.g. the handlers of the modules and the services and
+// repositories they depend on. Only types of the project's packages are
+// walked.
+func (h *Hooks) Discover(roots ...interface{}) {
+	seen := map[uintptr]bool{}
+	for _, root := range roots {
+		t := reflect.TypeOf(root)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil {
+			continue
+		}
+		h.walk(reflect.ValueOf(root), projectPrefix(t.PkgPath()), seen)
+	}
+}
+
+func (h *Hooks) walk(v reflect.Value, prefix string, seen map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			h.walk(v.Elem(), prefix, seen)
+		}
+	case reflect.Ptr:
+		if v.IsNil() || !strings.HasPrefix(v.Type().Elem().PkgPath(), prefix) || seen[v.Pointer()] {
+			return
+		}
+		seen[v.Pointer()] = true
+		// Children first, so dependencies start before their dependents
+		h.walk(v.Elem(), prefix, seen)
+		h.Register(v.Interface())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanInterface() && field.CanAddr() {
+				// Dependencies are usually unexported fields
+				field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+			}
+			h.walk(field, prefix, seen)
+		}
+	}
+}
+
+// projectPrefix returns the import path shared by the packages of the
+// project, e.g. github.com/you/api/ for github.com/you/api/internal
+func projectPrefix(pkgPath string) string {
+	for _, dir := range []string{"/internal", "/pkg", "/cmd"} {
+		if i := strings.Index(pkgPath, dir); i >= 0 {
+			return pkgPath[:i+1]
+		}
+	}
+	return pkgPath
+}
+
+// OnShutdown registers fn to run on shutdown, e.g. closing the database
+// or flushing a queue
+func (h *Hooks) OnShutdown(name string, fn func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shutdown = append(h.shutdown, hook{name: name, fn: fn})
+}
+
+// Init calls OnModuleInit on the components in registration order and
+// stops at the first error
+func (h *Hooks) Init(ctx context.Context) error {
+	h.mu.Lock()
+	components := append([]interface{}(nil), h.components...)
+	h.mu.Unlock()
+
+	for _, c := range components {
+		if i, ok := c.(OnModuleInit); ok {
+			if err := i.OnModuleInit(ctx); err != nil {
+				return fmt.Errorf("%T: %w", c, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Shutdown calls OnApplicationShutdown on the components, then the
+// OnShutdown functions, each in reverse order. Every hook runs even if
+// another fails; the errors are joined.
+func (h *Hooks) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	components := append([]interface{}(nil), h.components...)
+	shutdown := append([]hook(nil), h.shutdown...)
+	h.mu.Unlock()
+
+	var errs []error
+	for i := len(components) - 1; i >= 0; i-- {
+		if s, ok := components[i].(OnApplicationShutdown); ok {
+			if err := s.OnApplicationShutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%T: %w", components[i], err))
+			}
+		}
+	}
+	for i := len(shutdown) - 1; i >= 0; i-- {
+		if err := shutdown[i].fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", shutdown[i].name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+`
+}
@@ -0,0 +1,112 @@
+package templates
+
+import "fmt"
+
+// LoggingGo is pkg/logging: the zap logger built from the logging config
+// and the request-scoped loggers carried in contexts
+func LoggingGo(moduleName string) string {
+	return fmt.Sprintf(`// Package logging builds the zap logger of the application and carries
+// request-scoped loggers in contexts.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"%s/configs"
+)
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+// New builds the logger described by the logging section of
+// configs/config.yaml: level, json or console format, and stdout, stderr
+// or a file rotated by size
+func New(cfg configs.LoggingConfig) (*zap.Logger, error) {
+	level := zapcore.InfoLevel
+	if cfg.Level != "" {
+		var err error
+		if level, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "json", "":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown log format %%q, expected json or console", cfg.Format)
+	}
+
+	output, err := newOutput(cfg)
+	if err != nil {
+		return nil, err
+	}
+	core := zapcore.NewCore(encoder, output, level)
+	return zap.New(core, zap.AddCaller()), nil
+}
+
+func newOutput(cfg configs.LoggingConfig) (zapcore.WriteSyncer, error) {
+	switch cfg.Output {
+	case "stdout", "":
+		return zapcore.Lock(os.Stdout), nil
+	case "stderr":
+		return zapcore.Lock(os.Stderr), nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %%w", err)
+	}
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   cfg.Output,
+		MaxSize:    cfg.Rotation.MaxSizeMB,
+		MaxBackups: cfg.Rotation.MaxBackups,
+		MaxAge:     cfg.Rotation.MaxAgeDays,
+		Compress:   cfg.Rotation.Compress,
+	}), nil
+}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFromContext returns the logger of the request handled with ctx,
+// which logs its request_id, or the global logger outside a request
+func LoggerFromContext(ctx context.Context) *zap.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+			return logger
+		}
+	}
+	return zap.L()
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the ID of the request handled with ctx, to
+// pass on to the services it calls, or "" outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+`, moduleName)
+}
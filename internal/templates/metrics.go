@@ -0,0 +1,210 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetricsGo is pkg/metrics: the Prometheus registry, the HTTP middleware
+// and the /metrics handler
+func MetricsGo() string {
+	return `// Package metrics exposes the Prometheus metrics of the application.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// unmatchedRoute labels requests no route matched, so scanners probing
+// random paths do not create a series per path
+const unmatchedRoute = "unmatched"
+
+// NewRegistry creates a registry with the Go runtime and process
+// collectors
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// RegisterDB adds the connection pool stats of db, labelled db_name=name
+func RegisterDB(reg prometheus.Registerer, db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return reg.Register(collectors.NewDBStatsCollector(sqlDB, name))
+}
+
+// Middleware records the duration and sizes of HTTP requests and the
+// requests in flight, labelled by route template such as /users/:id
+// rather than the raw path
+func Middleware(reg prometheus.Registerer) gin.HandlerFunc {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duration of HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	requestSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_size_bytes",
+		Help:    "Size of HTTP request bodies.",
+		Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+	}, []string{"method", "route"})
+	responseSize := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_response_size_bytes",
+		Help:    "Size of HTTP response bodies.",
+		Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+	}, []string{"method", "route", "status"})
+	inFlight := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests being served.",
+	}, []string{"method", "route"})
+	reg.MustRegister(duration, requestSize, responseSize, inFlight)
+
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		method := c.Request.Method
+		gauge := inFlight.WithLabelValues(method, route)
+		gauge.Inc()
+		defer gauge.Dec()
+
+		start := time.Now()
+		observe := func(code int) {
+			status := strconv.Itoa(code)
+			duration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+			requestSize.WithLabelValues(method, route).Observe(float64(max(c.Request.ContentLength, 0)))
+			responseSize.WithLabelValues(method, route, status).Observe(float64(max(c.Writer.Size(), 0)))
+		}
+		defer func() {
+			// A panicking handler is answered with 500 by the recovery
+			// middleware further up
+			if r := recover(); r != nil {
+				observe(http.StatusInternalServerError)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+		observe(c.Writer.Status())
+	}
+}
+
+// Handler serves the metrics of reg in the Prometheus text format
+func Handler(reg *prometheus.Registry) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+}
+`
+}
+
+// AppMetricsGo is internal/metrics.go, which provides the registry to
+// wire so modules can register their own metrics
+func AppMetricsGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
+
+import (
+	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"%s/pkg/metrics"
+)
+
+// NewMetricsRegistry creates the registry served on
+// monitoring.prometheus.path, with the runtime and database pool metrics
+func NewMetricsRegistry(db *gorm.DB) (*prometheus.Registry, error) {
+	reg := metrics.NewRegistry()
+	if db != nil {
+		if err := metrics.RegisterDB(reg, db, "default"); err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// MetricsSet is the wire set for the metrics registry. Modules register
+// their metrics with the prometheus.Registerer, see meba g metric.
+var MetricsSet = wire.NewSet(
+	NewMetricsRegistry,
+	wire.Bind(new(prometheus.Registerer), new(*prometheus.Registry)),
+)
+`, moduleName)
+}
+
+// MetricTypes are the metric kinds meba g metric generates
+var MetricTypes = []string{"counter", "gauge", "histogram"}
+
+// MetricGo is a custom metric of a module, registered by its constructor
+func MetricGo(module, name, metricType string, labels []string) string {
+	typeName := MetricTypeName(name)
+	metricName := name
+	if metricType == "counter" && !strings.HasSuffix(metricName, "_total") {
+		metricName += "_total"
+	}
+
+	kind := strings.Title(metricType)
+	opts := fmt.Sprintf(`prometheus.%sOpts{
+		Name: %q,
+		Help: "TODO: describe %s.",`, kind, metricName, metricName)
+	if metricType == "histogram" {
+		opts += `
+		Buckets: prometheus.DefBuckets,`
+	}
+	opts += "\n\t}"
+
+	var field, ctor string
+	if len(labels) == 0 {
+		field = "prometheus." + kind
+		ctor = fmt.Sprintf("prometheus.New%s(%s)", kind, opts)
+	} else {
+		field = "*prometheus." + kind + "Vec"
+		quoted := make([]string, len(labels))
+		for i, label := range labels {
+			quoted[i] = fmt.Sprintf("%q", label)
+		}
+		ctor = fmt.Sprintf("prometheus.New%sVec(%s, []string{%s})", kind, opts, strings.Join(quoted, ", "))
+	}
+	embedded := strings.TrimPrefix(field, "*prometheus.")
+	embedded = strings.TrimPrefix(embedded, "prometheus.")
+
+	return fmt.Sprintf(`package %[1]s
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// %[2]s is the %[3]s %[4]s
+type %[2]s struct {
+	%[5]s
+}
+
+// New%[2]s creates %[4]s and registers it with reg
+func New%[2]s(reg prometheus.Registerer) *%[2]s {
+	m := %[6]s
+	reg.MustRegister(m)
+	return &%[2]s{%[7]s: m}
+}
+`, module, typeName, metricType, metricName, field, ctor, embedded)
+}
+
+// MetricTypeName is the Go type generated for a metric, e.g.
+// OrdersCreatedMetric for orders_created
+func MetricTypeName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		b.WriteString(strings.Title(part))
+	}
+	return b.String() + "Metric"
+}
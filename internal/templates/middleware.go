@@ -2,120 +2,204 @@ package templates
 
 import "fmt"
 
-func LoggerMiddleware() string {
-	return `package middleware
+func LoggerMiddleware(moduleName string) string {
+	return fmt.Sprintf(`package middleware
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"%s/pkg/logging"
 )
 
-// Logger returns a gin.HandlerFunc for logging requests
-func Logger(logger *zap.Logger) gin.HandlerFunc {
-	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		logger.Info("HTTP Request",
-			zap.String("method", param.Method),
-			zap.String("path", param.Path),
-			zap.Int("status", param.StatusCode),
-			zap.Duration("latency", param.Latency),
-			zap.String("client_ip", param.ClientIP),
-			zap.String("user_agent", param.Request.UserAgent()),
-		)
-		return ""
-	})
-}
+// RequestIDHeader carries the request ID from the caller and back to it
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs accepted from callers
+const maxRequestIDLength = 128
 
-// ZapLogger creates a structured logger middleware
-func ZapLogger(logger *zap.Logger) gin.HandlerFunc {
+// Logger gives each request an ID, or keeps the X-Request-ID sent by the
+// caller, and returns it in the response. A child of base logging the
+// request_id is stored in the request context for
+// logging.LoggerFromContext; fields add more, e.g. tracing.Fields. The
+// request is logged when it completes.
+func Logger(base *zap.Logger, fields ...func(ctx context.Context) []zap.Field) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(RequestIDHeader, id)
 
-		c.Next()
+		ctx := c.Request.Context()
+		logger := base.With(zap.String("request_id", id))
+		for _, f := range fields {
+			logger = logger.With(f(ctx)...)
+		}
+		ctx = logging.WithRequestID(ctx, id)
+		c.Request = c.Request.WithContext(logging.WithLogger(ctx, logger))
 
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
+		c.Next()
 
-		if raw != "" {
-			path = path + "?" + raw
+		status := c.Writer.Status()
+		level := zapcore.InfoLevel
+		if status >= 500 {
+			level = zapcore.ErrorLevel
 		}
+		if ce := logger.Check(level, "Request completed"); ce != nil {
+			fields := []zap.Field{
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("route", c.FullPath()),
+				zap.Int("status", status),
+				zap.Duration("latency", time.Since(start)),
+				zap.Int("bytes", c.Writer.Size()),
+				zap.String("client_ip", c.ClientIP()),
+				zap.String("user_agent", c.Request.UserAgent()),
+			}
+			if len(c.Errors) > 0 {
+				fields = append(fields, zap.Strings("errors", c.Errors.Errors()))
+			}
+			ce.Write(fields...)
+		}
+	}
+}
 
-		logger.Info("Request completed",
-			zap.String("method", method),
-			zap.String("path", path),
-			zap.Int("status", statusCode),
-			zap.Duration("latency", latency),
-			zap.String("client_ip", clientIP),
-		)
+// validRequestID accepts short IDs of letters, digits and -_.:, so a
+// caller cannot inject arbitrary text into the logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
 	}
+	return true
 }
-`
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+`, moduleName)
 }
 
-func RecoverMiddleware() string {
-	return `package middleware
+func RecoverMiddleware(moduleName string) string {
+	return fmt.Sprintf(`package middleware
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	apperrors "%[1]s/pkg/errors"
+	"%[1]s/pkg/logging"
 )
 
-// Recovery returns a middleware that recovers from panics
-func Recovery(logger *zap.Logger) gin.HandlerFunc {
-	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		logger.Error("Panic recovered",
+// Recovery returns a middleware that recovers from panics and logs them
+// with their stack to the request logger. The Errors middleware renders
+// the 500 response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		logging.LoggerFromContext(c.Request.Context()).Error("Panic recovered",
 			zap.Any("error", recovered),
 			zap.String("path", c.Request.URL.Path),
 			zap.String("method", c.Request.Method),
+			zap.Stack("stacktrace"),
 		)
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Internal server error",
-			"error":   "Something went wrong",
-		})
+		_ = c.Error(apperrors.Internal(fmt.Errorf("panic: %%v", recovered)))
+		c.Status(http.StatusInternalServerError)
+		c.Abort()
 	})
 }
-`
+`, moduleName)
 }
 
-func AuthMiddleware() string {
-	return `package middleware
+// ErrorsMiddleware renders the errors handlers add with c.Error
+func ErrorsMiddleware(moduleName string) string {
+	return fmt.Sprintf(`package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	apperrors "%[1]s/pkg/errors"
+	"%[1]s/pkg/logging"
+)
+
+// Errors renders the last error added with c.Error when the handler wrote
+// no response. Errors from pkg/errors keep their status and message, any
+// other error is a 500 whose text stays in the request log. format is
+// apperrors.FormatProblem for application/problem+json or
+// apperrors.FormatEnvelope for the BaseResponse envelope. Validation
+// messages follow the Accept-Language of the request.
+func Errors(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := apperrors.From(c.Errors.Last().Err).Translate(c.GetHeader("Accept-Language"))
+		if format == apperrors.FormatEnvelope {
+			body := gin.H{
+				"success": false,
+				"message": err.Message,
+				"error":   err.Kind,
+			}
+			if len(err.Fields) > 0 {
+				body["errors"] = err.Fields
+			}
+			c.JSON(err.Status(), body)
+			return
+		}
+
+		c.Header("Content-Type", "application/problem+json")
+		requestID := logging.RequestIDFromContext(c.Request.Context())
+		c.JSON(err.Status(), err.Problem(c.Request.URL.Path, requestID))
+	}
+}
+`, moduleName)
+}
+
+func AuthMiddleware(moduleName string) string {
+	return fmt.Sprintf(`package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	apperrors "%s/pkg/errors"
 )
 
-// AuthMiddleware validates JWT tokens
+// AuthMiddleware validates JWT tokens, aborting with an Unauthorized error
+// rendered by the Errors middleware
 func AuthMiddleware(secretKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Authorization header required",
-			})
+			_ = c.Error(apperrors.Unauthorized("Authorization header required"))
 			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid authorization format",
-			})
+			_ = c.Error(apperrors.Unauthorized("Invalid authorization format"))
 			c.Abort()
 			return
 		}
@@ -125,10 +209,7 @@ func AuthMiddleware(secretKey string) gin.HandlerFunc {
 		})
 
 		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"message": "Invalid token",
-			})
+			_ = c.Error(apperrors.Unauthorized("Invalid token"))
 			c.Abort()
 			return
 		}
@@ -171,40 +252,7 @@ func OptionalAuth(secretKey string) gin.HandlerFunc {
 		c.Next()
 	}
 }
-`
-}
-
-func ValidatorGo() string {
-	return `package validator
-
-import (
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
-)
-
-// SetupValidator configures custom validators
-func SetupValidator() {
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		// Register custom validators here
-		v.RegisterValidation("password", validatePassword)
-	}
-}
-
-// validatePassword validates password strength
-func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-	
-	// Minimum 8 characters
-	if len(password) < 8 {
-		return false
-	}
-	
-	// Add more password validation rules as needed
-	return true
-}
-
-// Custom validation tags can be added here
-`
+`, moduleName)
 }
 
 func MiddlewareGo(name string) string {
@@ -225,28 +273,24 @@ func %sMiddleware() gin.HandlerFunc {
 `, name, name, name, name)
 }
 
-func GuardGo(name string) string {
+func GuardGo(moduleName, name string) string {
 	return fmt.Sprintf(`package middleware
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+	apperrors "%[1]s/pkg/errors"
 )
 
-// %sGuard implements %s authorization guard
-func %sGuard() gin.HandlerFunc {
+// %[2]sGuard implements %[2]s authorization guard
+func %[2]sGuard() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Implement %s guard logic here
+		// Implement %[2]s guard logic here
 		
 		// Example authorization check
 		authorized := true // Replace with actual logic
 		
 		if !authorized {
-			c.JSON(http.StatusForbidden, gin.H{
-				"success": false,
-				"message": "Access denied",
-			})
+			_ = c.Error(apperrors.Forbidden("Access denied"))
 			c.Abort()
 			return
 		}
@@ -254,5 +298,5 @@ func %sGuard() gin.HandlerFunc {
 		c.Next()
 	}
 }
-`, name, name, name, name)
+`, moduleName, name)
 }
\ No newline at end of file
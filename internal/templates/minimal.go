@@ -23,7 +23,8 @@ func MinimalServiceGo(name string) string {
 	titleName := strings.Title(name)
 	return fmt.Sprintf(`package %s
 
-// Service handles business logic for %s
+// Service handles business logic for %s. Methods take the request
+// context and log with logging.LoggerFromContext(ctx).
 type Service struct {
 	// Add dependencies here
 }
@@ -44,7 +45,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// Repository handles data access for %s
+// Repository handles data access for %s. Methods take the request
+// context, query with r.db.WithContext(ctx) and log with
+// logging.LoggerFromContext(ctx).
 type Repository struct {
 	db *gorm.DB
 }
@@ -5,120 +5,107 @@ import (
 	"strings"
 )
 
-func ModuleGo(name string) string {
+// ModuleGo is the wire set of a module. With cached, handlers get the
+// CachedService in front of the Service.
+func ModuleGo(name string, cached bool) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	service := fmt.Sprintf("\tNew%sService,\n\twire.Bind(new(ServiceInterface), new(*Service)),", titleName)
+	if cached {
+		service = fmt.Sprintf("\tNew%sService,\n\tNewCachedService,\n\twire.Bind(new(ServiceInterface), new(*CachedService)),", titleName)
+	}
+	return fmt.Sprintf(`package %[1]s
 
 import (
 	"github.com/google/wire"
 )
 
-// Module is the wire set for the %s module
+// Module is the wire set for the %[1]s module
 var Module = wire.NewSet(
-	New%sService,
-	New%sRepository,
-	New%sHandlers,
+%[3]s
+	New%[2]sRepository,
+	wire.Bind(new(RepositoryInterface), new(*Repository)),
+	New%[2]sHandlers,
 )
-`, name, name, titleName, titleName, titleName)
+`, name, titleName, service)
 }
 
-func ModuleHandlersGo(name string) string {
+// ModuleHandlersGo is the handlers of a resource. With cached, SetupRoutes
+// tells the CachedService where the routes are mounted.
+func ModuleHandlersGo(moduleName, name, pagination string, cached bool) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	mounted := ""
+	if cached {
+		mounted = fmt.Sprintf(`	// The tests of internal/handlers.go list the routes of nil Handlers
+	if h == nil {
+		return
+	}
+	if cached, ok := h.service.(*CachedService); ok {
+		cached.MountedAt(%sGroup.BasePath())
+	}
+`, name)
+	}
+	return fmt.Sprintf(`package %[1]s
 
 import (
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	apperrors "%[3]s/pkg/errors"
+	"%[3]s/pkg/query"
 )
 
-// Handlers handles HTTP requests for %s
+// errInvalidID is returned for an id path parameter that is not a number
+var errInvalidID = apperrors.Validation("Invalid ID", map[string]string{
+	"id": "must be a positive integer",
+})
+
+// Handlers handles HTTP requests for %[1]s. Errors are added with c.Error
+// and rendered by the Errors middleware.
 type Handlers struct {
-	service *Service
+	service ServiceInterface
 }
 
-// New%sHandlers creates a new handlers instance
-func New%sHandlers(service *Service) *Handlers {
+// New%[2]sHandlers creates a new handlers instance
+func New%[2]sHandlers(service ServiceInterface) *Handlers {
 	return &Handlers{
 		service: service,
 	}
 }
 
-// SetupRoutes configures routes for %s module
+// SetupRoutes configures routes for %[1]s module
 func (h *Handlers) SetupRoutes(r *gin.RouterGroup) {
-	%sGroup := r.Group("/%s")
+	%[1]sGroup := r.Group("/%[1]s")
 	{
-		%sGroup.GET("", h.GetAll)
-		%sGroup.GET("/:id", h.GetByID)
-		%sGroup.POST("", h.Create)
-		%sGroup.PUT("/:id", h.Update)
-		%sGroup.DELETE("/:id", h.Delete)
-	}
-}
-
-// GetAll godoc
-// @Summary Get all %s
-// @Description Get all %s with pagination
-// @Tags %s
-// @Accept json
-// @Produce json
-// @Param page query int false "Page number"
-// @Param page_size query int false "Page size"
-// @Success 200 {object} PaginationResponse
-// @Router /%s [get]
-func (h *Handlers) GetAll(c *gin.Context) {
-	var req PaginationRequest
-	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid query parameters",
-			"error":   err.Error(),
-		})
-		return
-	}
-
-	result, err := h.service.GetAll(req.Page, req.PageSize)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to get %s",
-			"error":   err.Error(),
-		})
-		return
+		%[1]sGroup.GET("", h.GetAll)
+		%[1]sGroup.GET("/:id", h.GetByID)
+		%[1]sGroup.POST("", h.Create)
+		%[1]sGroup.PUT("/:id", h.Update)
+		%[1]sGroup.DELETE("/:id", h.Delete)
 	}
+%[5]s}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    result,
-	})
-}
-
+%[4]s
 // GetByID godoc
-// @Summary Get %s by ID
-// @Description Get a single %s by ID
-// @Tags %s
+// @Summary Get %[1]s by ID
+// @Description Get a single %[1]s by ID
+// @Tags %[1]s
 // @Accept json
 // @Produce json
-// @Param id path int true "%s ID"
-// @Success 200 {object} %s
-// @Router /%s/{id} [get]
+// @Param id path int true "%[2]s ID"
+// @Success 200 {object} %[2]s
+// @Failure 400,404,500 {object} apperrors.Problem
+// @Router /%[1]s/{id} [get]
 func (h *Handlers) GetByID(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid ID",
-		})
+		_ = c.Error(errInvalidID.Wrap(err))
 		return
 	}
 
-	result, err := h.service.GetByID(uint(id))
+	result, err := h.service.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "%s not found",
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -129,32 +116,25 @@ func (h *Handlers) GetByID(c *gin.Context) {
 }
 
 // Create godoc
-// @Summary Create %s
-// @Description Create a new %s
-// @Tags %s
+// @Summary Create %[1]s
+// @Description Create a new %[1]s
+// @Tags %[1]s
 // @Accept json
 // @Produce json
-// @Param %s body Create%sRequest true "%s data"
-// @Success 201 {object} %s
-// @Router /%s [post]
+// @Param %[1]s body Create%[2]sRequest true "%[2]s data"
+// @Success 201 {object} %[2]s
+// @Failure 400,409,500 {object} apperrors.Problem
+// @Router /%[1]s [post]
 func (h *Handlers) Create(c *gin.Context) {
-	var req Create%sRequest
+	var req Create%[2]sRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		_ = c.Error(apperrors.Invalid(err))
 		return
 	}
 
-	result, err := h.service.Create(&req)
+	result, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to create %s",
-			"error":   err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -165,42 +145,32 @@ func (h *Handlers) Create(c *gin.Context) {
 }
 
 // Update godoc
-// @Summary Update %s
-// @Description Update an existing %s
-// @Tags %s
+// @Summary Update %[1]s
+// @Description Update an existing %[1]s
+// @Tags %[1]s
 // @Accept json
 // @Produce json
-// @Param id path int true "%s ID"
-// @Param %s body Update%sRequest true "%s data"
-// @Success 200 {object} %s
-// @Router /%s/{id} [put]
+// @Param id path int true "%[2]s ID"
+// @Param %[1]s body Update%[2]sRequest true "%[2]s data"
+// @Success 200 {object} %[2]s
+// @Failure 400,404,409,500 {object} apperrors.Problem
+// @Router /%[1]s/{id} [put]
 func (h *Handlers) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid ID",
-		})
+		_ = c.Error(errInvalidID.Wrap(err))
 		return
 	}
 
-	var req Update%sRequest
+	var req Update%[2]sRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		_ = c.Error(apperrors.Invalid(err))
 		return
 	}
 
-	result, err := h.service.Update(uint(id), &req)
+	result, err := h.service.Update(c.Request.Context(), uint(id), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to update %s",
-			"error":   err.Error(),
-		})
+		_ = c.Error(err)
 		return
 	}
 
@@ -211,39 +181,121 @@ func (h *Handlers) Update(c *gin.Context) {
 }
 
 // Delete godoc
-// @Summary Delete %s
-// @Description Delete a %s by ID
-// @Tags %s
+// @Summary Delete %[1]s
+// @Description Delete a %[1]s by ID
+// @Tags %[1]s
 // @Accept json
 // @Produce json
-// @Param id path int true "%s ID"
+// @Param id path int true "%[2]s ID"
 // @Success 200 {object} BaseResponse
-// @Router /%s/{id} [delete]
+// @Failure 400,404,500 {object} apperrors.Problem
+// @Router /%[1]s/{id} [delete]
 func (h *Handlers) Delete(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid ID",
-		})
+		_ = c.Error(errInvalidID.Wrap(err))
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to delete %s",
-			"error":   err.Error(),
-		})
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		_ = c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "%s deleted successfully",
+		"message": "%[2]s deleted successfully",
 	})
 }
-`, name, name, titleName, titleName, name, name, name, name, name, name, name, name, name, name, name, name, name, titleName, name, titleName, name, name, titleName, titleName, name, titleName, name, titleName, name, name, titleName, name, titleName, titleName, name, titleName, name, titleName, name, name, titleName, name, titleName, titleName, titleName)
+`, name, titleName, moduleName, handlersGetAllGo(name, pagination), mounted)
+}
+
+// handlersGetAllGo is the list handler of a resource, paginated by page
+// or by cursor
+func handlersGetAllGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`// GetAll godoc
+// @Summary Get all %[1]s
+// @Description Get all %[1]s with cursor pagination, filtering, sorting, field selection and search
+// @Tags %[1]s
+// @Accept json
+// @Produce json
+// @Param cursor query string false "The next_cursor or prev_cursor of another page; the first page when empty"
+// @Param limit query int false "Page size"
+// @Param filter[field] query string false "Filter on a field, e.g. filter[status]=active or filter[price][gte]=10; operators: eq, ne, gt, gte, lt, lte, like, in (comma-separated), null (true or false)"
+// @Param sort query string false "Field to sort by, prefixed with - for descending, e.g. -created_at; ties are sorted by id"
+// @Param fields query string false "Fields to return; repeated or comma-separated, e.g. id,name"
+// @Param search query string false "Case-insensitive text matched against the text fields"
+// @Success 200 {object} CursorResponse
+// @Failure 400,500 {object} apperrors.Problem
+// @Router /%[1]s [get]
+func (h *Handlers) GetAll(c *gin.Context) {
+	var req CursorRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(apperrors.Invalid(err))
+		return
+	}
+
+	q, err := query.Parse(c.Request.URL.Query(), queryFields)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	result, err := h.service.GetAll(c.Request.Context(), req.Cursor, req.Limit, q)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+`, name, titleName)
+	}
+	return fmt.Sprintf(`// GetAll godoc
+// @Summary Get all %[1]s
+// @Description Get all %[1]s with pagination, filtering, sorting, field selection and search
+// @Tags %[1]s
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param page_size query int false "Page size"
+// @Param filter[field] query string false "Filter on a field, e.g. filter[status]=active or filter[price][gte]=10; operators: eq, ne, gt, gte, lt, lte, like, in (comma-separated), null (true or false)"
+// @Param sort query string false "Fields to sort by, prefixed with - for descending; repeated or comma-separated, e.g. -created_at,id"
+// @Param fields query string false "Fields to return; repeated or comma-separated, e.g. id,name"
+// @Param search query string false "Case-insensitive text matched against the text fields"
+// @Success 200 {object} PaginationResponse
+// @Failure 400,500 {object} apperrors.Problem
+// @Router /%[1]s [get]
+func (h *Handlers) GetAll(c *gin.Context) {
+	var req PaginationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		_ = c.Error(apperrors.Invalid(err))
+		return
+	}
+
+	q, err := query.Parse(c.Request.URL.Query(), queryFields)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	result, err := h.service.GetAll(c.Request.Context(), req.Page, req.PageSize, q)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+`, name, titleName)
 }
 
 func ModuleServiceGo(name string) string {
@@ -341,7 +393,7 @@ func (s *Service) Delete(id uint) error {
 	}
 	return nil
 }
-`, name, name, titleName, titleName, name, name, name, name, titleName, name, name, name, titleName, titleName, name, titleName, name, name, name, name, name, titleName, titleName, name, name, name, name, name, name, name, name)
+`, name, name, titleName, titleName, name, name, name, name, name, titleName, name, name, name, name, titleName, titleName, name, titleName, name, name, name, name, titleName, titleName, name, name, name, name, name, name, name, name)
 }
 
 func ModuleRepositoryGo(name string) string {
@@ -405,16 +457,7 @@ func (r *Repository) Update(%s *%s) error {
 func (r *Repository) Delete(id uint) error {
 	return r.db.Delete(&%s{}, id).Error
 }
-
-// GetByField retrieves %s by a specific field (example)
-func (r *Repository) GetByField(field, value string) ([]*%s, error) {
-	var %ss []*%s
-	if err := r.db.Where(field+" = ?", value).Find(&%ss).Error; err != nil {
-		return nil, err
-	}
-	return %ss, nil
-}
-`, name, name, titleName, titleName, name, titleName, name, titleName, titleName, name, titleName, name, titleName, name, titleName, titleName, name, name, titleName, name, titleName, name, titleName, name, titleName, name, titleName, titleName, name, titleName, name)
+`, name, name, titleName, titleName, name, titleName, name, titleName, titleName, name, name, name, titleName, name, titleName, name, name, name, name, titleName, name, name, name, titleName, name, name, titleName)
 }
 
 func ModuleEntityGo(name string) string {
@@ -455,7 +498,7 @@ func (e *%s) BeforeUpdate(tx *gorm.DB) error {
 	// Add any pre-update logic here
 	return nil
 }
-`, name, titleName, name, titleName, name, titleName, name, name, titleName, name, titleName, name, titleName)
+`, name, titleName, name, titleName, name, titleName, titleName, name, titleName, titleName)
 }
 
 func ModuleDtoGo(name string) string {
@@ -498,8 +541,8 @@ type %sListResponse struct {
 
 // PaginationRequest provides common pagination parameters
 type PaginationRequest struct {
-	Page     int ` + "`json:\"page\" form:\"page\" validate:\"min=1\"`" + `
-	PageSize int ` + "`json:\"page_size\" form:\"page_size\" validate:\"min=1,max=100\"`" + `
+	Page     int ` + "`json:\"page\" form:\"page\" validate:\"omitempty,min=1\"`" + `
+	PageSize int ` + "`json:\"page_size\" form:\"page_size\" validate:\"omitempty,min=1,max=100\"`" + `
 }
 
 // PaginationResponse provides common pagination response
@@ -513,10 +556,11 @@ type PaginationResponse struct {
 
 // BaseResponse provides common response structure
 type BaseResponse struct {
-	Success bool        ` + "`json:\"success\"`" + `
-	Message string      ` + "`json:\"message\"`" + `
-	Data    interface{} ` + "`json:\"data,omitempty\"`" + `
-	Error   string      ` + "`json:\"error,omitempty\"`" + `
+	Success bool              ` + "`json:\"success\"`" + `
+	Message string            ` + "`json:\"message\"`" + `
+	Data    interface{}       ` + "`json:\"data,omitempty\"`" + `
+	Error   string            ` + "`json:\"error,omitempty\"`" + `
+	Errors  map[string]string ` + "`json:\"errors,omitempty\"`" + `
 }
 `, name, titleName, name, titleName, titleName, name, titleName, titleName, name, titleName, titleName, name, titleName, titleName, titleName, name)
 }
\ No newline at end of file
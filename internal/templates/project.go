@@ -12,7 +12,7 @@ require (
 	github.com/google/wire v0.5.0
 	github.com/spf13/viper v1.18.2
 	gorm.io/gorm v1.25.5
-	gorm.io/driver/sqlite v1.6.0
+	github.com/glebarez/sqlite v1.11.0
 	gorm.io/driver/postgres v1.5.4
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/casbin/casbin/v2 v2.81.0
@@ -25,6 +25,7 @@ require (
 	github.com/swaggo/files v1.0.1
 	github.com/stretchr/testify v1.8.4
 	github.com/prometheus/client_golang v1.17.0
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
 )
 `, projectName)
 }
@@ -37,7 +38,7 @@ A Gin API project inspired by NestJS architecture, generated with Meba CLI.
 ## Features
 
 - 🚀 **NestJS-like Architecture**: Modular structure with dependency injection
-- 🔥 **Hot Reload**: Development with Air
+- 🔥 **Hot Reload**: Built-in file watcher with ` + "`meba start --watch`" + `
 - 🏗️ **Dependency Injection**: Google Wire for clean DI
 - 📊 **Database**: GORM with PostgreSQL
 - 🔐 **Authentication**: JWT with Casbin RBAC
@@ -53,7 +54,7 @@ A Gin API project inspired by NestJS architecture, generated with Meba CLI.
 
 - Go 1.21+
 - PostgreSQL
-- Air (for hot reload): ` + "`go install github.com/cosmtrek/air@latest`" + `
+- Meba CLI (for hot reload and code generation)
 
 ### Installation
 
@@ -129,15 +130,28 @@ func MainGo(projectName string) string {
 	return fmt.Sprintf(`package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
-	"%s/internal"
-	_ "%s/docs"
+	"%[1]s/configs"
+	"%[1]s/internal"
+	"%[1]s/internal/version"
+	"%[1]s/pkg/logging"
+	"%[1]s/pkg/metrics"
+	"%[1]s/pkg/middleware"
+	"%[1]s/pkg/validator"
+	_ "%[1]s/docs"
 )
 
-// @title %s API
+// @title %[1]s API
 // @version 1.0
 // @description A Gin API project inspired by NestJS architecture
 // @termsOfService http://swagger.io/terms/
@@ -154,86 +168,254 @@ import (
 // @schemes http https
 
 func main() {
-	// Initialize logger
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
+	// Container images have no shell or curl, so the binary probes itself
+	// for the image healthcheck: server healthcheck <url>
+	if len(os.Args) == 3 && os.Args[1] == "healthcheck" {
+		os.Exit(healthcheck(os.Args[2]))
+	}
+
+	cfg, err := configs.LoadConfig("configs")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	// Initialize Gin
-	r := gin.Default()
+	// Initialize the logger from the logging section of the config
+	logger, err := logging.New(cfg.Logging)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to set up logging:", err)
+		os.Exit(1)
+	}
+	zap.ReplaceGlobals(logger)
 
+	if err := run(cfg, logger); err != nil {
+		logger.Error("Server stopped", zap.Error(err))
+		logger.Sync()
+		os.Exit(1)
+	}
+	logger.Sync()
+}
+
+// run serves the application until SIGINT or SIGTERM, then drains the
+// in-flight requests and runs the shutdown hooks
+func run(cfg *configs.Config, logger *zap.Logger) error {
 	// Initialize application
-	app, cleanup, err := internal.InitializeApp()
+	app, cleanup, err := internal.InitializeApp(cfg)
 	if err != nil {
-		log.Fatal("Failed to initialize app:", err)
+		return fmt.Errorf("failed to initialize app: %%w", err)
 	}
 	defer cleanup()
 
-	// Setup routes (includes Swagger)
+	// Validate bound requests against their validate tags
+	validator.Setup()
+
+	// Initialize Gin and setup routes (includes Swagger). Each request
+	// gets an X-Request-ID and a logger in its context, and errors handlers
+	// add with c.Error are rendered in the server.error_format.
+	r := gin.New()
+	r.Use(middleware.Logger(logger), middleware.Errors(cfg.Server.ErrorFormat), middleware.Recovery())
+	if cfg.Monitoring.Prometheus.Enabled {
+		r.Use(metrics.Middleware(app.Metrics()))
+		r.GET(cfg.Monitoring.Prometheus.Path, metrics.Handler(app.Metrics()))
+	}
 	app.SetupRoutes(r)
 
-	// Start server
-	logger.Info("Starting server on :8080")
-	logger.Info("Swagger docs available at: http://localhost:8080/swagger/index.html")
-	if err := r.Run(":8080"); err != nil {
-		logger.Fatal("Failed to start server", zap.Error(err))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := app.Init(ctx); err != nil {
+		return fmt.Errorf("module init failed: %%w", err)
 	}
-}`, projectName, projectName, projectName)
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%%d", cfg.App.Port),
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if cfg.Server.TLSEnabled() {
+			serveErr <- srv.ListenAndServeTLS(cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	scheme := "http"
+	if cfg.Server.TLSEnabled() {
+		scheme = "https"
+	}
+	logger.Info("Starting server", zap.String("addr", srv.Addr), zap.String("scheme", scheme), zap.String("version", version.String()))
+	logger.Info(fmt.Sprintf("Swagger docs available at: %%s://localhost:%%d/swagger/index.html", scheme, cfg.App.Port))
+
+	select {
+	case err := <-serveErr:
+		// The server failed to start, e.g. the port is taken
+		err = fmt.Errorf("server failed: %%w", err)
+		return errors.Join(err, shutdown(app, cfg.Server.ShutdownTimeout))
+	case <-ctx.Done():
+		stop()
+		logger.Info("Shutting down, draining requests", zap.Duration("timeout", cfg.Server.ShutdownTimeout))
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("drain: %%w", err))
+	}
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("shutdown hooks: %%w", err))
+	}
+	if len(errs) == 0 {
+		logger.Info("Server stopped")
+	}
+	return errors.Join(errs...)
 }
 
-func AppGo() string {
-	return `package internal
+// shutdown runs the shutdown hooks of the app within timeout
+func shutdown(app *internal.App, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return app.Shutdown(ctx)
+}
+
+// healthcheck returns the exit code for a probe of url: 0 when it answers
+// 2xx in time
+func healthcheck(url string) int {
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		fmt.Fprintln(os.Stderr, resp.Status)
+		return 1
+	}
+	return 0
+}`, projectName)
+}
+
+func AppGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"%s/pkg/lifecycle"
 )
 
 // App represents the main application
 type App struct {
 	handlers *Handlers
+	db       *gorm.DB
+	metrics  *prometheus.Registry
+	hooks    *lifecycle.Hooks
 }
 
-// NewApp creates a new application instance
-func NewApp(handlers *Handlers) *App {
+// NewApp creates a new application instance. Its modules' components
+// implementing lifecycle.OnModuleInit or lifecycle.OnApplicationShutdown
+// are registered, and the database is closed on shutdown.
+func NewApp(handlers *Handlers, db *gorm.DB, metrics *prometheus.Registry) *App {
+	hooks := lifecycle.New()
+	hooks.Discover(handlers)
+	if db != nil {
+		hooks.OnShutdown("database", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		})
+	}
+
 	return &App{
 		handlers: handlers,
+		db:       db,
+		metrics:  metrics,
+		hooks:    hooks,
 	}
 }
 
+// DB returns the database connection of the application
+func (a *App) DB() *gorm.DB {
+	return a.db
+}
+
+// Metrics returns the Prometheus registry of the application
+func (a *App) Metrics() *prometheus.Registry {
+	return a.metrics
+}
+
 // SetupRoutes configures all application routes
 func (a *App) SetupRoutes(r *gin.Engine) {
 	a.handlers.SetupRoutes(r)
 }
 
+// OnShutdown registers fn to run after the server has drained, e.g. to
+// flush a queue
+func (a *App) OnShutdown(name string, fn func(ctx context.Context) error) {
+	a.hooks.OnShutdown(name, fn)
+}
+
+// Init runs the OnModuleInit hooks
+func (a *App) Init(ctx context.Context) error {
+	return a.hooks.Init(ctx)
+}
+
+// Shutdown runs the OnApplicationShutdown hooks, then the OnShutdown
+// functions
+func (a *App) Shutdown(ctx context.Context) error {
+	return a.hooks.Shutdown(ctx)
+}
+
 // AppSet is the wire set for the main application
 var AppSet = wire.NewSet(
 	NewApp,
 	HandlersSet,
 	ServiceSet,
 	RepositorySet,
+	MetricsSet,
 )
-`
+`, moduleName)
 }
 
-func HandlersGo() string {
-	return `package internal
+func HandlersGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
 
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/wire"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	swaggerFiles "github.com/swaggo/files"
+	"%[1]s/configs"
+	"%[1]s/pkg/health"
 )
 
 // Handlers aggregates all handler modules
 type Handlers struct {
-	// Add your module handlers here
-	// users *users.Handlers
+	health      *health.Health
+	healthCheck configs.HealthCheckConfig
+	// Module handlers are added here by meba g resource
 }
 
 // NewHandlers creates a new handlers instance
-func NewHandlers() *Handlers {
-	return &Handlers{}
+func NewHandlers(cfg *configs.Config, health *health.Health) *Handlers {
+	return &Handlers{
+		health:      health,
+		healthCheck: cfg.Monitoring.HealthCheck,
+	}
 }
 
 // SetupRoutes configures all routes
@@ -241,29 +423,21 @@ func (h *Handlers) SetupRoutes(r *gin.Engine) {
 	// Setup Swagger UI
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	
-	api := r.Group("/api/v1")
-	
-	// Health check
-	// @Summary Health Check
-	// @Description Check if the API is running
-	// @Tags health
-	// @Accept json
-	// @Produce json
-	// @Success 200 {object} map[string]string
-	// @Router /health [get]
-	api.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "message": "Server is running"})
-	})
-
-	// Setup module routes here
-	// h.users.SetupRoutes(api)
+	// Health checks on monitoring.health_check.path, with /live and /ready
+	if h.healthCheck.Enabled {
+		h.health.Register(r.Group(h.healthCheck.Path))
+	}
+
+	// Setup module routes here; meba g resource mounts them on an
+	// /api/v1 group
 }
 
 // HandlersSet is the wire set for handlers
 var HandlersSet = wire.NewSet(
 	NewHandlers,
+	HealthSet,
 )
-`
+`, moduleName)
 }
 
 func ServiceGo() string {
@@ -314,16 +488,17 @@ func DtoGo() string {
 
 // BaseResponse provides common response structure
 type BaseResponse struct {
-	Success bool        ` + "`json:\"success\"`" + `
-	Message string      ` + "`json:\"message\"`" + `
-	Data    interface{} ` + "`json:\"data,omitempty\"`" + `
-	Error   string      ` + "`json:\"error,omitempty\"`" + `
+	Success bool              ` + "`json:\"success\"`" + `
+	Message string            ` + "`json:\"message\"`" + `
+	Data    interface{}       ` + "`json:\"data,omitempty\"`" + `
+	Error   string            ` + "`json:\"error,omitempty\"`" + `
+	Errors  map[string]string ` + "`json:\"errors,omitempty\"`" + `
 }
 
 // PaginationRequest provides common pagination parameters
 type PaginationRequest struct {
-	Page     int ` + "`json:\"page\" form:\"page\" validate:\"min=1\"`" + `
-	PageSize int ` + "`json:\"page_size\" form:\"page_size\" validate:\"min=1,max=100\"`" + `
+	Page     int ` + "`json:\"page\" form:\"page\" validate:\"omitempty,min=1\"`" + `
+	PageSize int ` + "`json:\"page_size\" form:\"page_size\" validate:\"omitempty,min=1,max=100\"`" + `
 }
 
 // PaginationResponse provides common pagination response
@@ -362,6 +537,11 @@ func NewRepositories(db *gorm.DB) *Repositories {
 // RepositorySet is the wire set for repositories
 var RepositorySet = wire.NewSet(
 	NewRepositories,
+)
+
+// DatabaseSet provides the database connection. It is not part of AppSet
+// so InitializeTestApp can inject a test database instead.
+var DatabaseSet = wire.NewSet(
 	NewDatabase,
 )
 
@@ -374,20 +554,30 @@ func NewDatabase() (*gorm.DB, error) {
 `
 }
 
-func WireGo() string {
-	return `//go:build wireinject
+func WireGo(moduleName string) string {
+	return fmt.Sprintf(`//go:build wireinject
 // +build wireinject
 
 package internal
 
 import (
 	"github.com/google/wire"
+	"gorm.io/gorm"
+	"%s/configs"
 )
 
-// InitializeApp initializes the application with dependency injection
-func InitializeApp() (*App, func(), error) {
+// InitializeApp initializes the application with dependency injection.
+// Providers that need settings depend on the loaded cfg.
+func InitializeApp(cfg *configs.Config) (*App, func(), error) {
+	wire.Build(AppSet, DatabaseSet)
+	return nil, nil, nil
+}
+
+// InitializeTestApp initializes the application on the given database,
+// used by the e2e tests in test/e2e
+func InitializeTestApp(cfg *configs.Config, db *gorm.DB) (*App, func(), error) {
 	wire.Build(AppSet)
 	return nil, nil, nil
 }
-`
+`, moduleName)
 }
\ No newline at end of file
@@ -0,0 +1,812 @@
+package templates
+
+import "fmt"
+
+// Pagination modes of generated list endpoints
+const (
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+// PaginationModes lists the values accepted by meba g resource --pagination
+var PaginationModes = []string{PaginationOffset, PaginationCursor}
+
+// QueryGo is pkg/query: the filter, sort, fields and search parameters of
+// list endpoints, checked against a whitelist of entity fields and applied
+// as GORM scopes
+func QueryGo(moduleName string) string {
+	return fmt.Sprintf(`// Package query parses the filter, sort, fields and search parameters of
+// list endpoints and applies them to GORM queries, e.g.
+//
+//	?filter[status]=active&filter[price][gte]=10&sort=-created_at&fields=id,name&search=foo
+//
+// Only the fields of the whitelist built by FieldsOf can be used, so
+// clients never name SQL columns themselves.
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	apperrors "%s/pkg/errors"
+)
+
+// Operators of filter[field][op]=value; eq applies when op is omitted
+const (
+	OpEq   = "eq"
+	OpNe   = "ne"
+	OpGt   = "gt"
+	OpGte  = "gte"
+	OpLt   = "lt"
+	OpLte  = "lte"
+	OpLike = "like"
+	OpIn   = "in"
+	OpNull = "null"
+)
+
+var operators = map[string]bool{
+	OpEq: true, OpNe: true, OpGt: true, OpGte: true, OpLt: true,
+	OpLte: true, OpLike: true, OpIn: true, OpNull: true,
+}
+
+var (
+	filterParam = regexp.MustCompile(`+"`^filter\\[([^\\]]+)\\](?:\\[([a-z]+)\\])?$`"+`)
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// Field is a column clients may filter, sort, select and search on
+type Field struct {
+	// Name is the JSON name clients use
+	Name   string
+	Column string
+	Type   reflect.Type
+	// Searchable fields are matched by the search parameter
+	Searchable bool
+
+	primary bool
+	schema  *schema.Field
+}
+
+// Fields is the whitelist of a list endpoint, keyed by JSON name
+type Fields map[string]Field
+
+// FieldsOf returns the whitelist of a GORM model: its columns, named by
+// their json tag. Fields tagged json:"-" or query:"-" are left out, as are
+// columns a filter value cannot be converted to, such as gorm.DeletedAt,
+// and string fields are searchable. It panics if model is not a GORM
+// model.
+func FieldsOf(model interface{}) Fields {
+	s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("query: %%v", err))
+	}
+	fields := Fields{}
+	for _, f := range s.Fields {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.DBName == "" || name == "-" || f.Tag.Get("query") == "-" || !filterable(f.FieldType) {
+			continue
+		}
+		if name == "" {
+			name = f.DBName
+		}
+		fields[name] = Field{
+			Name:       name,
+			Column:     f.DBName,
+			Type:       f.FieldType,
+			Searchable: f.FieldType.Kind() == reflect.String,
+			primary:    f.PrimaryKey,
+			schema:     f,
+		}
+	}
+	return fields
+}
+
+// Filter is a condition on a field, e.g. filter[price][gte]=10
+type Filter struct {
+	Field Field
+	Op    string
+	Value interface{}
+}
+
+// Sort orders results by a field
+type Sort struct {
+	Field Field
+	Desc  bool
+}
+
+// Query is a parsed list query. The zero Query matches everything.
+type Query struct {
+	Filters []Filter
+	Sort    []Sort
+	Fields  []Field
+	Search  string
+
+	searchable []Field
+}
+
+// Parse reads the filter[field], filter[field][op], sort, fields and
+// search parameters of values; sort and fields may be repeated or
+// comma-separated. Unknown fields, operators and values that
+// do not fit the field type are reported as a Validation error keyed by
+// parameter.
+func Parse(values url.Values, fields Fields) (Query, error) {
+	var q Query
+	invalid := map[string]string{}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m := filterParam.FindStringSubmatch(key)
+		if m == nil {
+			continue
+		}
+		field, ok := fields[m[1]]
+		if !ok {
+			invalid[key] = "unknown field " + m[1]
+			continue
+		}
+		op := m[2]
+		if op == "" {
+			op = OpEq
+		}
+		if !operators[op] {
+			invalid[key] = "unknown operator " + op
+			continue
+		}
+		value, err := field.value(op, values.Get(key))
+		if err != nil {
+			invalid[key] = err.Error()
+			continue
+		}
+		q.Filters = append(q.Filters, Filter{Field: field, Op: op, Value: value})
+	}
+
+	for _, name := range list(values["sort"]...) {
+		field, ok := fields[strings.TrimPrefix(name, "-")]
+		if !ok {
+			invalid["sort"] = "unknown field " + strings.TrimPrefix(name, "-")
+			continue
+		}
+		q.Sort = append(q.Sort, Sort{Field: field, Desc: strings.HasPrefix(name, "-")})
+	}
+
+	for _, name := range list(values["fields"]...) {
+		field, ok := fields[name]
+		if !ok {
+			invalid["fields"] = "unknown field " + name
+			continue
+		}
+		q.Fields = append(q.Fields, field)
+	}
+
+	if q.Search = strings.TrimSpace(values.Get("search")); q.Search != "" {
+		for _, field := range fields {
+			if field.Searchable {
+				q.searchable = append(q.searchable, field)
+			}
+		}
+		sort.Slice(q.searchable, func(i, j int) bool { return q.searchable[i].Name < q.searchable[j].Name })
+	}
+
+	if len(invalid) > 0 {
+		return Query{}, apperrors.Validation("Invalid query", invalid)
+	}
+	return q, nil
+}
+
+// Filter is a GORM scope applying the filters and the search of q
+func (q Query) Filter(db *gorm.DB) *gorm.DB {
+	for _, f := range q.Filters {
+		db = db.Where(f.expression())
+	}
+	if q.Search != "" && len(q.searchable) > 0 {
+		pattern := contains(strings.ToLower(q.Search))
+		conditions := make([]string, len(q.searchable))
+		vars := make([]interface{}, 0, 2*len(q.searchable))
+		for i, field := range q.searchable {
+			conditions[i] = "LOWER(?) LIKE ? ESCAPE '\\'"
+			vars = append(vars, field.column(), pattern)
+		}
+		db = db.Where(clause.Expr{SQL: "(" + strings.Join(conditions, " OR ") + ")", Vars: vars})
+	}
+	return db
+}
+
+// Select is a GORM scope loading only the fields q selects
+func (q Query) Select(db *gorm.DB) *gorm.DB {
+	if len(q.Fields) == 0 {
+		return db
+	}
+	columns := make([]string, len(q.Fields))
+	for i, field := range q.Fields {
+		columns[i] = field.Column
+	}
+	return db.Select(columns)
+}
+
+// Order is a GORM scope sorting by the fields of q
+func (q Query) Order(db *gorm.DB) *gorm.DB {
+	for _, s := range q.Sort {
+		db = db.Order(clause.OrderByColumn{Column: s.Field.column(), Desc: s.Desc})
+	}
+	return db
+}
+
+// Project returns the JSON form of v, an item or a slice of items, with
+// only the fields q selects, or v itself when q selects none
+func (q Query) Project(v interface{}) (interface{}, error) {
+	if len(q.Fields) == 0 {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	switch decoded := decoded.(type) {
+	case []interface{}:
+		for _, item := range decoded {
+			q.project(item)
+		}
+	default:
+		q.project(decoded)
+	}
+	return decoded, nil
+}
+
+func (q Query) project(item interface{}) {
+	object, ok := item.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for key := range object {
+		if !q.selects(key) {
+			delete(object, key)
+		}
+	}
+}
+
+func (q Query) selects(name string) bool {
+	for _, field := range q.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) expression() clause.Expression {
+	column := f.Field.column()
+	switch f.Op {
+	case OpNe:
+		return clause.Neq{Column: column, Value: f.Value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: f.Value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: f.Value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: f.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: f.Value}
+	case OpLike:
+		return clause.Expr{SQL: "? LIKE ? ESCAPE '\\'", Vars: []interface{}{column, contains(f.Value.(string))}}
+	case OpIn:
+		return clause.IN{Column: column, Values: f.Value.([]interface{})}
+	case OpNull:
+		if f.Value.(bool) {
+			return clause.Eq{Column: column, Value: nil}
+		}
+		return clause.Neq{Column: column, Value: nil}
+	}
+	return clause.Eq{Column: column, Value: f.Value}
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, with \ as the
+// ESCAPE character
+var likeEscaper = strings.NewReplacer(`+"`\\`, `\\\\`, `%%`, `\\%%`, `_`, `\\_`"+`)
+
+// contains is the LIKE pattern matching values containing s literally
+func contains(s string) string {
+	return "%%" + likeEscaper.Replace(s) + "%%"
+}
+
+func (f Field) column() clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: f.Column}
+}
+
+// value converts the raw value of a filter to the type op compares with
+func (f Field) value(op, raw string) (interface{}, error) {
+	switch op {
+	case OpNull:
+		null, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return null, nil
+	case OpLike:
+		if f.Type.Kind() != reflect.String {
+			return nil, errors.New("like only applies to text fields")
+		}
+		return raw, nil
+	case OpIn:
+		var values []interface{}
+		for _, item := range list(raw) {
+			value, err := convert(f.Type, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return nil, errors.New("must list at least one value")
+		}
+		return values, nil
+	}
+	return convert(f.Type, raw)
+}
+
+// filterable reports whether convert can parse a filter value for a
+// field of type t
+func filterable(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// convert parses raw as a value of a field of type t
+func convert(t reflect.Type, raw string) (interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		if value, err := time.Parse(time.RFC3339, raw); err == nil {
+			return value, nil
+		}
+		value, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return nil, errors.New("must be an RFC 3339 time or a date")
+		}
+		return value, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return value, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, errors.New("must be a positive integer")
+		}
+		return value, nil
+	case reflect.Float32, reflect.Float64:
+		value, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return value, nil
+	}
+	return nil, errors.New("cannot be filtered")
+}
+
+// list splits comma-separated parameter values, dropping empty items
+func list(values ...string) []string {
+	var items []string
+	for _, raw := range values {
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+`, moduleName)
+}
+
+// QueryTestGo is the table test of pkg/query: the whitelist FieldsOf
+// builds and the parameters Parse accepts and rejects
+func QueryTestGo(moduleName string) string {
+	return fmt.Sprintf(`package query_test
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	apperrors "%[1]s/pkg/errors"
+	"%[1]s/pkg/query"
+)
+
+type product struct {
+	ID        uint           ` + "`json:\"id\" gorm:\"primarykey\"`" + `
+	Name      string         ` + "`json:\"name\"`" + `
+	Price     float64        ` + "`json:\"price\"`" + `
+	CreatedAt time.Time      ` + "`json:\"created_at\"`" + `
+	DeletedAt gorm.DeletedAt ` + "`json:\"deleted_at\"`" + `
+	Secret    string         ` + "`json:\"secret\" query:\"-\"`" + `
+}
+
+var fields = query.FieldsOf(&product{})
+
+func TestFieldsOf(t *testing.T) {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	// deleted_at cannot be converted from a filter value and secret is
+	// tagged query:"-"
+	assert.Equal(t, []string{"created_at", "id", "name", "price"}, names)
+	assert.True(t, fields["name"].Searchable)
+	assert.False(t, fields["price"].Searchable)
+}
+
+type filter struct {
+	field string
+	op    string
+	value interface{}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		filters []filter
+		sort    []string
+		fields  []string
+		search  string
+	}{
+		{name: "empty"},
+		{name: "eq by default", query: "filter[name]=phone", filters: []filter{{"name", query.OpEq, "phone"}}},
+		{name: "operator", query: "filter[price][gte]=10", filters: []filter{{"price", query.OpGte, 10.0}}},
+		{name: "in", query: "filter[id][in]=1,2", filters: []filter{{"id", query.OpIn, []interface{}{uint64(1), uint64(2)}}}},
+		{name: "null", query: "filter[name][null]=true", filters: []filter{{"name", query.OpNull, true}}},
+		{name: "date", query: "filter[created_at][lt]=2024-01-02", filters: []filter{{"created_at", query.OpLt, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}}},
+		{name: "sort", query: "sort=-price,name", sort: []string{"-price", "name"}},
+		{name: "repeated fields", query: "fields=id&fields=name", fields: []string{"id", "name"}},
+		{name: "search", query: "search=+phone+", search: "phone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			q, err := query.Parse(values, fields)
+			require.NoError(t, err)
+
+			var filters []filter
+			for _, f := range q.Filters {
+				filters = append(filters, filter{f.Field.Name, f.Op, f.Value})
+			}
+			assert.Equal(t, tt.filters, filters)
+			var sorts []string
+			for _, s := range q.Sort {
+				if s.Desc {
+					sorts = append(sorts, "-"+s.Field.Name)
+				} else {
+					sorts = append(sorts, s.Field.Name)
+				}
+			}
+			assert.Equal(t, tt.sort, sorts)
+			var selected []string
+			for _, f := range q.Fields {
+				selected = append(selected, f.Name)
+			}
+			assert.Equal(t, tt.fields, selected)
+			assert.Equal(t, tt.search, q.Search)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		param   string
+		message string
+	}{
+		{"unknown field", "filter[secret]=x", "filter[secret]", "unknown field secret"},
+		{"unconvertible field", "filter[deleted_at]=x", "filter[deleted_at]", "unknown field deleted_at"},
+		{"unknown operator", "filter[price][between]=1", "filter[price][between]", "unknown operator between"},
+		{"value of another type", "filter[price]=cheap", "filter[price]", "must be a number"},
+		{"like on a number", "filter[price][like]=1", "filter[price][like]", "like only applies to text fields"},
+		{"empty in", "filter[id][in]=,", "filter[id][in]", "must list at least one value"},
+		{"unknown sort", "sort=-secret", "sort", "unknown field secret"},
+		{"unknown selected field", "fields=id,secret", "fields", "unknown field secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			_, err = query.Parse(values, fields)
+
+			var appErr *apperrors.Error
+			require.True(t, errors.As(err, &appErr), "not an application error: %%v", err)
+			assert.Equal(t, apperrors.KindValidation, appErr.Kind)
+			assert.Equal(t, map[string]string{tt.param: tt.message}, appErr.Fields)
+		})
+	}
+}
+`, moduleName)
+}
+
+// QueryCursorGo is the keyset pagination of pkg/query, used by resources
+// generated with --pagination cursor
+func QueryCursorGo(moduleName string) string {
+	return fmt.Sprintf(`package query
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	apperrors "%s/pkg/errors"
+)
+
+// errInvalidCursor is returned for a cursor that was not returned with a
+// page of the same list and sort
+var errInvalidCursor = apperrors.Validation("Invalid cursor", map[string]string{
+	"cursor": "is not a cursor of this list",
+})
+
+// cursor is the opaque position a page continues from, base64 encoded
+type cursor struct {
+	Sort string `+"`json:\"s\"`"+`
+	Key  string `+"`json:\"k\"`"+`
+	ID   string `+"`json:\"i\"`"+`
+	Prev bool   `+"`json:\"p,omitempty\"`"+`
+}
+
+// position is a decoded cursor: the sort key and id of the item a page
+// starts after, or ends before when prev is set
+type position struct {
+	key, id interface{}
+	prev    bool
+}
+
+// Keyset paginates a list with cursors over (sort key, id) instead of
+// offsets, so every page costs the same however deep it is and no total
+// is counted. The list is sorted by the one field of the query's sort, the
+// primary key by default, and then by the primary key.
+type Keyset struct {
+	query Query
+	key   Field
+	id    Field
+	desc  bool
+	limit int
+	from  *position
+}
+
+// NewKeyset returns the keyset pagination of q in pages of limit items,
+// starting after the cursor of a previous page, or at the start when it is
+// "". q may sort by one field at most.
+func NewKeyset(q Query, fields Fields, after string, limit int) (*Keyset, error) {
+	k := &Keyset{query: q, limit: limit}
+	for _, field := range fields {
+		if field.primary {
+			k.id = field
+		}
+	}
+	if k.id.schema == nil {
+		return nil, errors.New("query: cursor pagination needs a primary key")
+	}
+
+	k.key = k.id
+	switch len(q.Sort) {
+	case 0:
+	case 1:
+		k.key, k.desc = q.Sort[0].Field, q.Sort[0].Desc
+		if !keyable(k.key.Type) {
+			return nil, apperrors.Validation("Invalid query", map[string]string{
+				"sort": k.key.Name + " cannot be used with cursor pagination",
+			})
+		}
+	default:
+		return nil, apperrors.Validation("Invalid query", map[string]string{
+			"sort": "must be a single field with cursor pagination",
+		})
+	}
+
+	if after != "" {
+		from, err := k.decode(after)
+		if err != nil {
+			return nil, errInvalidCursor.Wrap(err)
+		}
+		k.from = from
+	}
+	return k, nil
+}
+
+// Scope is a GORM scope loading the page: the filters and search of the
+// query, the fields it selects plus the ones cursors need, sorted, and one
+// item more than the limit to tell whether another page follows
+func (k *Keyset) Scope(db *gorm.DB) *gorm.DB {
+	db = k.query.Filter(db)
+	if len(k.query.Fields) > 0 {
+		columns := []string{k.id.Column}
+		if k.key.Column != k.id.Column {
+			columns = append(columns, k.key.Column)
+		}
+		for _, field := range k.query.Fields {
+			if field.Column != k.id.Column && field.Column != k.key.Column {
+				columns = append(columns, field.Column)
+			}
+		}
+		db = db.Select(columns)
+	}
+
+	// A previous page is read backwards from the cursor, then reversed
+	desc := k.desc
+	if k.from != nil && k.from.prev {
+		desc = !desc
+	}
+	if k.from != nil {
+		db = db.Where(k.after(desc))
+	}
+	db = db.Order(clause.OrderByColumn{Column: k.key.column(), Desc: desc})
+	if k.key.Column != k.id.Column {
+		db = db.Order(clause.OrderByColumn{Column: k.id.column(), Desc: desc})
+	}
+	return db.Limit(k.limit + 1)
+}
+
+// after is the condition of the items past the cursor in the order of the
+// scope
+func (k *Keyset) after(desc bool) clause.Expression {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	id := k.id.column()
+	if k.key.Column == k.id.Column {
+		return clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{id, k.from.id}}
+	}
+	key := k.key.column()
+	return clause.Expr{
+		SQL:  "(? " + op + " ? OR (? = ? AND ? " + op + " ?))",
+		Vars: []interface{}{key, k.from.key, key, k.from.key, id, k.from.id},
+	}
+}
+
+// Paginate trims the rows loaded with the Scope of k to its page, and
+// returns the cursors of the next and previous pages, "" when there is
+// none
+func Paginate[T any](k *Keyset, rows []T) (page []T, next, prev string) {
+	more := len(rows) > k.limit
+	if more {
+		rows = rows[:k.limit]
+	}
+	backwards := k.from != nil && k.from.prev
+	if backwards {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, "", ""
+	}
+
+	if more || backwards {
+		next = k.encode(rows[len(rows)-1], false)
+	}
+	if k.from != nil && (more || !backwards) {
+		prev = k.encode(rows[0], true)
+	}
+	return rows, next, prev
+}
+
+// sort names the order of the list, so a cursor is only used with the
+// sort it was issued for
+func (k *Keyset) sort() string {
+	if k.desc {
+		return "-" + k.key.Name
+	}
+	return k.key.Name
+}
+
+func (k *Keyset) encode(row interface{}, prev bool) string {
+	value := reflect.Indirect(reflect.ValueOf(row))
+	key, _ := k.key.schema.ValueOf(context.Background(), value)
+	id, _ := k.id.schema.ValueOf(context.Background(), value)
+	data, _ := json.Marshal(cursor{Sort: k.sort(), Key: formatKey(key), ID: formatKey(id), Prev: prev})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k *Keyset) decode(raw string) (*position, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if c.Sort != k.sort() {
+		return nil, fmt.Errorf("cursor of sort %%q used with sort %%q", c.Sort, k.sort())
+	}
+	key, err := convert(k.key.Type, c.Key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := convert(k.id.Type, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &position{key: key, id: id, prev: c.Prev}, nil
+}
+
+// keyable reports whether cursors can hold a sort key of type t; nullable
+// keys cannot be compared
+func keyable(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func formatKey(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
+`, moduleName)
+}
@@ -5,66 +5,123 @@ import (
 	"strings"
 )
 
-func ModuleRepositoryGoSimple(name string) string {
+func ModuleRepositoryGoSimple(moduleName, name, pagination string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
+	"context"
+
+	"go.uber.org/zap"
 	"gorm.io/gorm"
+	apperrors "%[3]s/pkg/errors"
+	"%[3]s/pkg/logging"
+	"%[3]s/pkg/query"
 )
 
-// Repository handles data access for %s
+// RepositoryInterface is the data access the service depends on, bound to
+// *Repository in module.go
+type RepositoryInterface interface {
+%[4]s
+	GetByID(ctx context.Context, id uint) (*%[2]s, error)
+	Create(ctx context.Context, item *%[2]s) error
+	Update(ctx context.Context, item *%[2]s) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// Repository handles data access for %[1]s. Queries run with the request
+// context, so they are cancelled with the request, and their errors go
+// through apperrors.FromDB so missing records and duplicate keys become
+// NotFound and Conflict errors.
 type Repository struct {
 	db *gorm.DB
 }
 
-// New%sRepository creates a new repository instance
-func New%sRepository(db *gorm.DB) *Repository {
+// New%[2]sRepository creates a new repository instance
+func New%[2]sRepository(db *gorm.DB) *Repository {
 	return &Repository{
 		db: db,
 	}
 }
 
-// GetAll retrieves all %s with pagination
-func (r *Repository) GetAll(page, pageSize int) ([]*%s, int64, error) {
-	var items []*%s
-	var total int64
+%[5]s// GetByID retrieves a %[1]s by ID
+func (r *Repository) GetByID(ctx context.Context, id uint) (*%[2]s, error) {
+	var item %[2]s
+	if err := r.db.WithContext(ctx).First(&item, id).Error; err != nil {
+		return nil, apperrors.FromDB(err)
+	}
+	return &item, nil
+}
 
-	offset := (page - 1) * pageSize
+// Create creates a new %[1]s
+func (r *Repository) Create(ctx context.Context, item *%[2]s) error {
+	return apperrors.FromDB(r.db.WithContext(ctx).Create(item).Error)
+}
 
-	if err := r.db.Model(&%s{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+// Update updates an existing %[1]s
+func (r *Repository) Update(ctx context.Context, item *%[2]s) error {
+	return apperrors.FromDB(r.db.WithContext(ctx).Save(item).Error)
+}
 
-	if err := r.db.Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
-		return nil, 0, err
+// Delete deletes a %[1]s by ID, returning a NotFound error wrapping
+// gorm.ErrRecordNotFound when it does not exist
+func (r *Repository) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&%[2]s{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error)
 	}
-
-	return items, total, nil
+	if result.RowsAffected == 0 {
+		logging.LoggerFromContext(ctx).Debug("No %[1]s deleted", zap.Uint("id", id))
+		return apperrors.FromDB(gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+`, name, titleName, moduleName, repositoryGetAllSig(name, pagination), repositoryGetAllGo(name, pagination))
 }
 
-// GetByID retrieves a %s by ID
-func (r *Repository) GetByID(id uint) (*%s, error) {
-	var item %s
-	if err := r.db.First(&item, id).Error; err != nil {
-		return nil, err
+// repositoryGetAllSig is the GetAll method of RepositoryInterface
+func repositoryGetAllSig(name, pagination string) string {
+	if pagination == PaginationCursor {
+		return fmt.Sprintf("\tGetAll(ctx context.Context, keyset *query.Keyset) ([]*%s, error)", strings.Title(name))
 	}
-	return &item, nil
+	return fmt.Sprintf("\tGetAll(ctx context.Context, page, pageSize int, q query.Query) ([]*%s, int64, error)", strings.Title(name))
 }
 
-// Create creates a new %s
-func (r *Repository) Create(item *%s) error {
-	return r.db.Create(item).Error
+// repositoryGetAllGo is the list method of a resource repository, paginated
+// by offset or by keyset
+func repositoryGetAllGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`// GetAll retrieves the %[1]s of the page of keyset, and one more that
+// tells query.Paginate whether another page follows
+func (r *Repository) GetAll(ctx context.Context, keyset *query.Keyset) ([]*%[2]s, error) {
+	var items []*%[2]s
+	if err := r.db.WithContext(ctx).Scopes(keyset.Scope).Find(&items).Error; err != nil {
+		return nil, apperrors.FromDB(err)
+	}
+	return items, nil
 }
 
-// Update updates an existing %s
-func (r *Repository) Update(item *%s) error {
-	return r.db.Save(item).Error
+`, name, titleName)
+	}
+	return fmt.Sprintf(`// GetAll retrieves a page of the %[1]s matching q, and how many match
+func (r *Repository) GetAll(ctx context.Context, page, pageSize int, q query.Query) ([]*%[2]s, int64, error) {
+	var items []*%[2]s
+	var total int64
+
+	offset := (page - 1) * pageSize
+	db := r.db.WithContext(ctx)
+
+	if err := db.Model(&%[2]s{}).Scopes(q.Filter).Count(&total).Error; err != nil {
+		return nil, 0, apperrors.FromDB(err)
+	}
+
+	if err := db.Scopes(q.Filter, q.Select, q.Order).Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, apperrors.FromDB(err)
+	}
+
+	return items, total, nil
 }
 
-// Delete deletes a %s by ID
-func (r *Repository) Delete(id uint) error {
-	return r.db.Delete(&%s{}, id).Error
+`, name, titleName)
 }
-`, name, name, titleName, titleName, name, titleName, titleName, titleName, name, titleName, titleName, name, titleName, name, titleName, name, titleName)
-}
\ No newline at end of file
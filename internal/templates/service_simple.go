@@ -5,100 +5,189 @@ import (
 	"strings"
 )
 
-func ModuleServiceGoSimple(name string) string {
+func ModuleServiceGoSimple(moduleName, name, pagination string) string {
 	titleName := strings.Title(name)
-	return fmt.Sprintf(`package %s
+	return fmt.Sprintf(`package %[1]s
 
 import (
+	"context"
+	"errors"
 	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	apperrors "%[3]s/pkg/errors"
+	"%[3]s/pkg/logging"
+	"%[3]s/pkg/query"
 )
 
-// Service handles business logic for %s
+// ErrNotFound is returned when a %[1]s does not exist. Other errors are
+// wrapped as they are: the Errors middleware sends application errors from
+// pkg/errors to clients and anything else as a 500.
+var ErrNotFound = apperrors.NotFound("%[2]s not found")
+
+// ServiceInterface is the business logic handlers depend on, bound to
+// *Service in module.go. Methods take the request context, which carries
+// the request logger.
+type ServiceInterface interface {
+%[4]s
+	GetByID(ctx context.Context, id uint) (*%[2]s, error)
+	Create(ctx context.Context, req *Create%[2]sRequest) (*%[2]s, error)
+	Update(ctx context.Context, id uint, req *Update%[2]sRequest) (*%[2]s, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+// Service handles business logic for %[1]s
 type Service struct {
-	repo *Repository
+	repo RepositoryInterface
 }
 
-// New%sService creates a new service instance
-func New%sService(repo *Repository) *Service {
+// New%[2]sService creates a new service instance
+func New%[2]sService(repo RepositoryInterface) *Service {
 	return &Service{
 		repo: repo,
 	}
 }
 
-// GetAll retrieves all %s with pagination
-func (s *Service) GetAll(page, pageSize int) (*PaginationResponse, error) {
-	if page <= 0 {
-		page = 1
+%[5]s// GetByID retrieves a %[1]s by ID
+func (s *Service) GetByID(ctx context.Context, id uint) (*%[2]s, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
 	}
-	if pageSize <= 0 {
-		pageSize = 10
-	}
-
-	items, total, err := s.repo.GetAll(page, pageSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get %s: %%w", err)
-	}
-
-	totalPages := int(total) / pageSize
-	if int(total)%%pageSize != 0 {
-		totalPages++
-	}
-
-	return &PaginationResponse{
-		Page:       page,
-		PageSize:   pageSize,
-		Total:      total,
-		TotalPages: totalPages,
-		Data:       items,
-	}, nil
-}
-
-// GetByID retrieves a %s by ID
-func (s *Service) GetByID(id uint) (*%s, error) {
-	item, err := s.repo.GetByID(id)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get %s: %%w", err)
+		return nil, fmt.Errorf("failed to get %[1]s: %%w", err)
 	}
 	return item, nil
 }
 
-// Create creates a new %s
-func (s *Service) Create(req *Create%sRequest) (*%s, error) {
-	item := &%s{
+// Create creates a new %[1]s
+func (s *Service) Create(ctx context.Context, req *Create%[2]sRequest) (*%[2]s, error) {
+	item := &%[2]s{
 		// Map request fields to entity
 		// Name: req.Name,
 	}
 
-	if err := s.repo.Create(item); err != nil {
-		return nil, fmt.Errorf("failed to create %s: %%w", err)
+	if err := s.repo.Create(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to create %[1]s: %%w", err)
 	}
 
+	logging.LoggerFromContext(ctx).Info("%[2]s created", zap.Uint("id", item.ID))
 	return item, nil
 }
 
-// Update updates an existing %s
-func (s *Service) Update(id uint, req *Update%sRequest) (*%s, error) {
-	item, err := s.repo.GetByID(id)
+// Update updates an existing %[1]s
+func (s *Service) Update(ctx context.Context, id uint, req *Update%[2]sRequest) (*%[2]s, error) {
+	item, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get %s: %%w", err)
+		return nil, fmt.Errorf("failed to get %[1]s: %%w", err)
 	}
 
 	// Update fields from request
 	// item.Name = req.Name
 
-	if err := s.repo.Update(item); err != nil {
-		return nil, fmt.Errorf("failed to update %s: %%w", err)
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update %[1]s: %%w", err)
 	}
 
+	logging.LoggerFromContext(ctx).Info("%[2]s updated", zap.Uint("id", item.ID))
 	return item, nil
 }
 
-// Delete deletes a %s by ID
-func (s *Service) Delete(id uint) error {
-	if err := s.repo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete %s: %%w", err)
+// Delete deletes a %[1]s by ID
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	err := s.repo.Delete(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %[1]s: %%w", err)
 	}
+	logging.LoggerFromContext(ctx).Info("%[2]s deleted", zap.Uint("id", id))
 	return nil
 }
-`, name, name, titleName, titleName, name, name, name, titleName, name, name, titleName, titleName, titleName, name, name, titleName, titleName, name, name, name, name)
-}
\ No newline at end of file
+`, name, titleName, moduleName, serviceGetAllSig(pagination), serviceGetAllGo(name, pagination))
+}
+
+// serviceGetAllSig is the GetAll method of ServiceInterface
+func serviceGetAllSig(pagination string) string {
+	if pagination == PaginationCursor {
+		return "\tGetAll(ctx context.Context, cursor string, limit int, q query.Query) (*CursorResponse, error)"
+	}
+	return "\tGetAll(ctx context.Context, page, pageSize int, q query.Query) (*PaginationResponse, error)"
+}
+
+// serviceGetAllGo is the list method of a resource service, paginated by
+// page or by cursor
+func serviceGetAllGo(name, pagination string) string {
+	titleName := strings.Title(name)
+	if pagination == PaginationCursor {
+		return fmt.Sprintf(`// GetAll retrieves the page of the %[1]s matching q after cursor, or
+// before it for a prev_cursor, with only the fields q selects
+func (s *Service) GetAll(ctx context.Context, cursor string, limit int, q query.Query) (*CursorResponse, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	keyset, err := query.NewKeyset(q, queryFields, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.GetAll(ctx, keyset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %[1]s: %%w", err)
+	}
+	items, next, prev := query.Paginate(keyset, rows)
+	data, err := q.Project(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select %[1]s fields: %%w", err)
+	}
+
+	return &CursorResponse{
+		Limit:      limit,
+		NextCursor: next,
+		PrevCursor: prev,
+		Data:       data,
+	}, nil
+}
+
+`, name, titleName)
+	}
+	return fmt.Sprintf(`// GetAll retrieves a page of the %[1]s matching q, with only the fields q
+// selects
+func (s *Service) GetAll(ctx context.Context, page, pageSize int, q query.Query) (*PaginationResponse, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+
+	items, total, err := s.repo.GetAll(ctx, page, pageSize, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %[1]s: %%w", err)
+	}
+	data, err := q.Project(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select %[1]s fields: %%w", err)
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%%pageSize != 0 {
+		totalPages++
+	}
+
+	return &PaginationResponse{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: totalPages,
+		Data:       data,
+	}, nil
+}
+
+`, name, titleName)
+}
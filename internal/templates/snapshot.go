@@ -0,0 +1,254 @@
+package templates
+
+func SnapshotGo() string {
+	return `// Package snapshot compares JSON responses with snapshots recorded in
+// __snapshots__ next to the test, so changes to the response envelope
+// show up as test failures:
+//
+//	snapshot.MatchJSON(t, w.Body.Bytes())
+//
+// Volatile values are masked before comparing: ids, *_id and *_at keys,
+// RFC 3339 timestamps and UUIDs. A missing snapshot is recorded on the
+// first run, except in CI. Set UPDATE_SNAPSHOTS=1, or run
+// meba test --update-snapshots, to accept changed responses.
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that rewrites snapshots instead
+// of comparing them
+const UpdateEnv = "UPDATE_SNAPSHOTS"
+
+// Dir is the directory snapshots are stored in, relative to the package
+const Dir = "__snapshots__"
+
+var (
+	timestampPattern = regexp.MustCompile(` + "`" + `^\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}` + "`" + `)
+	uuidPattern      = regexp.MustCompile(` + "`" + `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$` + "`" + `)
+	unsafeChars      = regexp.MustCompile(` + "`" + `[^A-Za-z0-9_.-]+` + "`" + `)
+
+	mu       sync.Mutex
+	counters = map[string]int{}
+)
+
+type config struct {
+	name string
+	mask map[string]bool
+}
+
+// Option customizes a snapshot
+type Option func(*config)
+
+// Name stores the snapshot as <test>_<name>.json instead of numbering the
+// snapshots of a test in call order
+func Name(name string) Option {
+	return func(c *config) {
+		c.name = name
+	}
+}
+
+// Mask masks the values of these keys in addition to the defaults
+func Mask(keys ...string) Option {
+	return func(c *config) {
+		for _, key := range keys {
+			c.mask[key] = true
+		}
+	}
+}
+
+// MatchJSON compares body with the snapshot of the test and fails the
+// test with a diff when they differ
+func MatchJSON(t testing.TB, body []byte, opts ...Option) {
+	t.Helper()
+
+	cfg := &config{mask: map[string]bool{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		t.Fatalf("snapshot: body is not JSON: %v\nbody: %s", err, body)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(mask(value, "", cfg)); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	got := buf.String()
+
+	path := filepath.Join(Dir, fileName(t, cfg.name))
+	if os.Getenv(UpdateEnv) != "" {
+		write(t, path, got)
+		t.Logf("snapshot updated: %s", path)
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if os.Getenv("CI") != "" {
+			t.Errorf("snapshot missing in %s; record it with %s=1 or meba test --update-snapshots", path, UpdateEnv)
+			return
+		}
+		write(t, path, got)
+		t.Logf("snapshot written: %s", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if string(want) != got {
+		t.Errorf("snapshot drift in %s:\n%s\naccept the change with %s=1 or meba test --update-snapshots", path, diff(string(want), got), UpdateEnv)
+	}
+}
+
+// fileName names the snapshot after the test, numbering the snapshots of
+// a test unless it is named
+func fileName(t testing.TB, name string) string {
+	base := unsafeChars.ReplaceAllString(t.Name(), "_")
+	if name != "" {
+		return base + "_" + unsafeChars.ReplaceAllString(name, "_") + ".json"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counters[t.Name()] == 0 {
+		t.Cleanup(func() {
+			mu.Lock()
+			delete(counters, t.Name())
+			mu.Unlock()
+		})
+	}
+	counters[t.Name()]++
+	return fmt.Sprintf("%s_%d.json", base, counters[t.Name()])
+}
+
+func write(t testing.TB, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+}
+
+// mask replaces volatile values with placeholders. Nulls are kept so a
+// value that appears or disappears still shows up in the diff.
+func mask(value interface{}, key string, cfg *config) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = mask(item, k, cfg)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = mask(item, key, cfg)
+		}
+		return out
+	case nil:
+		return nil
+	}
+
+	switch {
+	case cfg.mask[key]:
+		return "<masked>"
+	case key == "id" || strings.HasSuffix(key, "_id"):
+		return "<id>"
+	case strings.HasSuffix(key, "_at"):
+		return "<timestamp>"
+	}
+	if s, ok := value.(string); ok {
+		switch {
+		case timestampPattern.MatchString(s):
+			return "<timestamp>"
+		case uuidPattern.MatchString(s):
+			return "<uuid>"
+		}
+	}
+	return value
+}
+
+// diff returns the changed lines between want and got with two lines of
+// context
+func diff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	for i, j := 0, 0; i < len(a) || j < len(b); {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, "+ "+b[j])
+			j++
+		default:
+			lines = append(lines, "- "+a[i])
+			i++
+		}
+	}
+
+	const context = 2
+	show := make([]bool, len(lines))
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "  ") {
+			for k := i - context; k <= i+context; k++ {
+				if k >= 0 && k < len(lines) {
+					show[k] = true
+				}
+			}
+		}
+	}
+	var out []string
+	skipped := false
+	for i, line := range lines {
+		if !show[i] {
+			skipped = true
+			continue
+		}
+		if skipped && len(out) > 0 {
+			out = append(out, "  ...")
+		}
+		skipped = false
+		out = append(out, line)
+	}
+	return "--- snapshot\n+++ response\n" + strings.Join(out, "\n")
+}
+`
+}
@@ -0,0 +1,236 @@
+package templates
+
+import "fmt"
+
+// TracingGo is pkg/tracing: the OpenTelemetry tracer provider and the
+// instrumentation of Gin, GORM and outbound HTTP calls
+func TracingGo() string {
+	return `// Package tracing sets up OpenTelemetry tracing for the application.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Config is the tracing section of configs/config.yaml
+type Config struct {
+	Enabled bool ` + "`mapstructure:\"enabled\"`" + `
+	// Exporter is otlp, stdout to print spans without a collector, or none
+	Exporter string ` + "`mapstructure:\"exporter\"`" + `
+	// Endpoint is the host:port of the OTLP collector; empty uses
+	// OTEL_EXPORTER_OTLP_ENDPOINT or the exporter's default
+	Endpoint string ` + "`mapstructure:\"endpoint\"`" + `
+	// Protocol is http or grpc
+	Protocol string ` + "`mapstructure:\"protocol\"`" + `
+	Insecure bool   ` + "`mapstructure:\"insecure\"`" + `
+	// SampleRatio is the fraction of new traces recorded; requests that
+	// carry a sampled parent are always recorded
+	SampleRatio float64 ` + "`mapstructure:\"sample_ratio\"`" + `
+}
+
+// Service identifies the application in the exported spans
+type Service struct {
+	Name        string
+	Version     string
+	Environment string
+}
+
+// Setup installs the global tracer provider and the W3C trace context
+// propagator. The returned function flushes the pending spans and must be
+// called on shutdown.
+func Setup(ctx context.Context, cfg Config, service Service) (func(ctx context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.Enabled || cfg.Exporter == "none" {
+		return noop, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return noop, err
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			attribute.String("service.name", service.Name),
+			attribute.String("service.version", service.Version),
+			attribute.String("deployment.environment", service.Environment),
+		),
+	)
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	case "otlp", "":
+		switch cfg.Protocol {
+		case "grpc":
+			var opts []otlptracegrpc.Option
+			if cfg.Endpoint != "" {
+				opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+			}
+			if cfg.Insecure {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+			return otlptracegrpc.New(ctx, opts...)
+		case "http", "":
+			var opts []otlptracehttp.Option
+			if cfg.Endpoint != "" {
+				opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+			}
+			if cfg.Insecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+			return otlptracehttp.New(ctx, opts...)
+		}
+		return nil, fmt.Errorf("unknown tracing protocol %q, expected http or grpc", cfg.Protocol)
+	}
+	return nil, fmt.Errorf("unknown tracing exporter %q, expected otlp, stdout or none", cfg.Exporter)
+}
+
+// Middleware starts a span for each request, continuing the trace of the
+// caller when it sends a traceparent header
+func Middleware(service string) gin.HandlerFunc {
+	return otelgin.Middleware(service)
+}
+
+// NewHTTPClient creates an http.Client that traces its requests and
+// propagates the trace to the services it calls. Requests must carry the
+// request context, e.g. http.NewRequestWithContext(c.Request.Context(), ...).
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: otelhttp.NewTransport(http.DefaultTransport),
+		Timeout:   30 * time.Second,
+	}
+}
+
+// Fields returns the trace_id and span_id of the span in ctx as zap
+// fields, or none when ctx has no span
+func Fields(ctx context.Context) []zap.Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+	return []zap.Field{
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	}
+}
+
+// Logger returns logger with the trace and span IDs of ctx, so log lines
+// can be found from a trace and the other way round
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if fields := Fields(ctx); fields != nil {
+		return logger.With(fields...)
+	}
+	return logger
+}
+`
+}
+
+// TracingGormGo is pkg/tracing/gorm.go with the GORM plugin
+func TracingGormGo() string {
+	return `package tracing
+
+import (
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
+	"gorm.io/gorm"
+)
+
+// GormPlugin records a span for each query. Queries must carry the request
+// context to join its trace, e.g. db.WithContext(ctx).Find(&users).
+// Pool metrics are left to pkg/metrics.
+func GormPlugin() gorm.Plugin {
+	return gormtracing.NewPlugin(gormtracing.WithoutMetrics())
+}
+`
+}
+
+// AppTracingGo is internal/tracing.go, which provides the instrumented
+// http.Client to wire
+func AppTracingGo(moduleName string) string {
+	return fmt.Sprintf(`package internal
+
+import (
+	"github.com/google/wire"
+	"%s/pkg/tracing"
+)
+
+// TracingSet is the wire set for tracing. Services that call other
+// services inject the *http.Client so the trace propagates.
+var TracingSet = wire.NewSet(
+	tracing.NewHTTPClient,
+)
+`, moduleName)
+}
+
+// TracingConfigSection is the tracing section appended to
+// configs/config.yaml by meba add tracing
+func TracingConfigSection() string {
+	return `
+# Tracing (meba add tracing)
+tracing:
+  enabled: true
+  exporter: "otlp"      # otlp, stdout to print spans without a collector, or none
+  endpoint: ""          # collector host:port, e.g. localhost:4318 (http) or localhost:4317 (grpc); empty uses OTEL_EXPORTER_OTLP_ENDPOINT
+  protocol: "http"      # http or grpc
+  insecure: true        # plain text to the collector
+  sample_ratio: 1.0     # fraction of new traces recorded
+`
+}
+
+// TracingMainSetup is inserted into run in cmd/server/main.go after the
+// app is initialized
+func TracingMainSetup() string {
+	return `
+	// Tracing: export spans of requests and queries, flushed on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, tracing.Service{
+		Name:        cfg.App.Name,
+		Version:     version.String(),
+		Environment: cfg.App.Env,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	app.OnShutdown("tracing", shutdownTracing)
+	if db := app.DB(); db != nil {
+		if err := db.Use(tracing.GormPlugin()); err != nil {
+			return fmt.Errorf("failed to trace database: %w", err)
+		}
+	}
+`
+}
@@ -0,0 +1,368 @@
+package templates
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidatorGo is pkg/validator: the validator Gin binds requests with,
+// naming fields by their JSON name, with translated messages and custom
+// rules
+func ValidatorGo() string {
+	return `// Package validator validates the requests Gin binds against their
+// validate tags, and the binding tags Gin's default validator reads.
+// Errors name fields as clients send them and are
+// translated to the locales added with RegisterLocale. Custom rules added
+// with Register, such as the ones from meba g validator, apply to every
+// request.
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+)
+
+var (
+	// validates check the validate and the binding tags; a validator reads
+	// a single tag name
+	validates   = []*validator.Validate{newValidate("validate"), newValidate("binding")}
+	uni         = ut.New(en.New())
+	translators = map[string]ut.Translator{}
+
+	// messages are the English messages of the custom rules, by tag
+	messages = map[string]string{}
+)
+
+func init() {
+	if err := RegisterLocale(en.New(), entranslations.RegisterDefaultTranslations); err != nil {
+		panic(err)
+	}
+}
+
+// Setup makes Gin validate the requests it binds with this validator.
+// Call it before serving.
+func Setup() {
+	binding.Validator = &structValidator{validates: validates}
+}
+
+// Register adds a custom rule for the validate tag with its English
+// message, where {0} is the field and {1} the rule parameter. Call it from
+// an init function, like the rules generated by meba g validator.
+func Register(tag string, fn validator.Func, message string) {
+	for _, validate := range validates {
+		if err := validate.RegisterValidation(tag, fn); err != nil {
+			panic(fmt.Sprintf("validator: rule %s: %v", tag, err))
+		}
+	}
+	messages[tag] = message
+	for locale := range translators {
+		if err := RegisterMessage(locale, tag, message); err != nil {
+			panic(fmt.Sprintf("validator: rule %s: %v", tag, err))
+		}
+	}
+}
+
+// RegisterLocale adds a language for the messages, picked from the
+// Accept-Language header of the request, e.g.
+//
+//	validator.RegisterLocale(fr.New(), frtranslations.RegisterDefaultTranslations)
+//
+// Custom rules keep their English message until RegisterMessage translates
+// them.
+func RegisterLocale(locale locales.Translator, register func(*validator.Validate, ut.Translator) error) error {
+	if err := uni.AddTranslator(locale, true); err != nil {
+		return err
+	}
+	found, _ := uni.GetTranslator(locale.Locale())
+	trans := sharedTranslator{found}
+	for _, validate := range validates {
+		if err := register(validate, trans); err != nil {
+			return fmt.Errorf("failed to register the %s messages: %w", locale.Locale(), err)
+		}
+	}
+	translators[locale.Locale()] = trans
+	for tag, message := range messages {
+		if err := RegisterMessage(locale.Locale(), tag, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterMessage sets the message of the validate tag in a registered
+// locale, where {0} is the field and {1} the rule parameter
+func RegisterMessage(locale, tag, message string) error {
+	trans, ok := translators[locale]
+	if !ok {
+		return fmt.Errorf("locale %s is not registered", locale)
+	}
+	for _, validate := range validates {
+		err := validate.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+			return t.Add(tag, message, true)
+		}, func(t ut.Translator, fe validator.FieldError) string {
+			msg, err := t.T(tag, fe.Field(), fe.Param())
+			if err != nil {
+				return fe.Error()
+			}
+			return msg
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Translate returns the message of each field err rejected, keyed by its
+// path, in the language acceptLanguage prefers. ok is false when err does
+// not come from the validator.
+func Translate(err error, acceptLanguage string) (fields map[string]string, ok bool) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	trans := translator(acceptLanguage)
+	fields = make(map[string]string, len(errs))
+	for _, fe := range errs {
+		fields[fieldPath(fe)] = fe.Translate(trans)
+	}
+	return fields, true
+}
+
+// translator returns the translator of the first locale of an
+// Accept-Language header that is registered, or English
+func translator(acceptLanguage string) ut.Translator {
+	var preferred []string
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		tag = strings.ReplaceAll(tag, "-", "_")
+		preferred = append(preferred, tag)
+		if base, _, found := strings.Cut(tag, "_"); found {
+			preferred = append(preferred, base)
+		}
+	}
+	if trans, found := uni.FindTranslator(preferred...); found {
+		return sharedTranslator{trans}
+	}
+	return translators["en"]
+}
+
+// sharedTranslator is the translator of a locale both validators register
+// their messages with, so it keys their translations alike. A message the
+// other validator already added is not a conflict.
+type sharedTranslator struct {
+	ut.Translator
+}
+
+func (t sharedTranslator) Add(key interface{}, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+func (t sharedTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+func (t sharedTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+func (t sharedTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+func ignoreConflict(err error) error {
+	var conflict *ut.ErrConflictingTranslation
+	if errors.As(err, &conflict) {
+		return nil
+	}
+	return err
+}
+
+// fieldPath is the namespace of a field without the request struct, e.g.
+// address.city
+func fieldPath(fe validator.FieldError) string {
+	if _, path, found := strings.Cut(fe.Namespace(), "."); found {
+		return path
+	}
+	return fe.Field()
+}
+
+func newValidate(tagName string) *validator.Validate {
+	v := validator.New()
+	v.SetTagName(tagName)
+	v.RegisterTagNameFunc(fieldName)
+	return v
+}
+
+// fieldName names a field by its json tag, or its form tag for query
+// parameters
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// structValidator is the binding.StructValidator Gin validates the
+// structs, pointers and slices it binds with
+type structValidator struct {
+	validates []*validator.Validate
+}
+
+func (v *structValidator) ValidateStruct(obj interface{}) error {
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		// Report the fields both tags reject together
+		var errs validator.ValidationErrors
+		for _, validate := range v.validates {
+			var fieldErrs validator.ValidationErrors
+			if err := validate.Struct(value.Interface()); errors.As(err, &fieldErrs) {
+				errs = append(errs, fieldErrs...)
+			} else if err != nil {
+				return err
+			}
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := v.ValidateStruct(value.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *structValidator) Engine() interface{} {
+	return v.validates[0]
+}
+`
+}
+
+// ValidatorTestGo tests that requests bound by Gin are checked against
+// both their validate and binding tags
+func ValidatorTestGo(moduleName string) string {
+	return fmt.Sprintf(`package validator_test
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"%s/pkg/validator"
+)
+
+type signupRequest struct {
+	Email    string ` + "`json:\"email\" binding:\"required,email\"`" + `
+	Password string ` + "`json:\"password\" validate:\"required,password\"`" + `
+}
+
+func TestValidateStruct(t *testing.T) {
+	validator.Setup()
+
+	tests := []struct {
+		name    string
+		req     signupRequest
+		invalid []string
+	}{
+		{name: "valid", req: signupRequest{Email: "a@example.com", Password: "secret123"}},
+		{name: "binding required", req: signupRequest{Password: "secret123"}, invalid: []string{"email"}},
+		{name: "binding rule", req: signupRequest{Email: "a", Password: "secret123"}, invalid: []string{"email"}},
+		{name: "validate rule", req: signupRequest{Email: "a@example.com", Password: "short"}, invalid: []string{"password"}},
+		{name: "both tags", req: signupRequest{}, invalid: []string{"email", "password"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(&tt.req)
+			if tt.invalid == nil {
+				require.NoError(t, err)
+				return
+			}
+			fields, ok := validator.Translate(err, "")
+			require.True(t, ok, "not a validation error: %%v", err)
+			for _, field := range tt.invalid {
+				require.Contains(t, fields, field)
+				// Translated, not the raw message of the validator
+				assert.NotContains(t, fields[field], "Key: ")
+			}
+			assert.Len(t, fields, len(tt.invalid))
+		})
+	}
+}
+`, moduleName)
+}
+
+// ValidatorPasswordGo is the password rule of pkg/validator, registered
+// like the rules from meba g validator
+func ValidatorPasswordGo() string {
+	return `package validator
+
+import "github.com/go-playground/validator/v10"
+
+func init() {
+	Register("password", validatePassword, "{0} must be at least 8 characters long")
+}
+
+// validatePassword validates password strength, used as validate:"password"
+func validatePassword(fl validator.FieldLevel) bool {
+	password := fl.Field().String()
+
+	// Minimum 8 characters
+	if len(password) < 8 {
+		return false
+	}
+
+	// Add more password validation rules as needed
+	return true
+}
+`
+}
+
+// ValidatorRuleGo is a custom rule generated by meba g validator, named by
+// its validate tag
+func ValidatorRuleGo(tag string) string {
+	funcName := "validate"
+	for _, part := range strings.Split(tag, "_") {
+		funcName += strings.Title(part)
+	}
+	return fmt.Sprintf(`package validator
+
+import "github.com/go-playground/validator/v10"
+
+func init() {
+	Register("%[1]s", %[2]s, "{0} is not a valid %[1]s")
+}
+
+// %[2]s implements the %[1]s rule, used as validate:"%[1]s"
+func %[2]s(fl validator.FieldLevel) bool {
+	// Implement the %[1]s rule here, e.g. check fl.Field().String()
+	return true
+}
+`, tag, funcName)
+}
@@ -0,0 +1,37 @@
+package templates
+
+func VersionGo() string {
+	return `// Package version holds the build information that meba build injects
+// with -ldflags -X. Plain go build and go run leave the defaults.
+package version
+
+import "fmt"
+
+var (
+	// Version is the git tag the binary was built from, e.g. v1.2.0
+	Version = "dev"
+	// Commit is the full git commit hash
+	Commit = "none"
+	// Date is the build time in RFC 3339. meba build takes it from
+	// SOURCE_DATE_EPOCH or the commit time so builds are reproducible.
+	Date = "unknown"
+)
+
+// Info is the build information as reported by the API
+type Info struct {
+	Version string ` + "`json:\"version\"`" + `
+	Commit  string ` + "`json:\"commit\"`" + `
+	Date    string ` + "`json:\"date\"`" + `
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	return Info{Version: Version, Commit: Commit, Date: Date}
+}
+
+// String formats the build information for logs
+func String() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", Version, Commit, Date)
+}
+`
+}
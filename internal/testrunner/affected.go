@@ -0,0 +1,128 @@
+package testrunner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package is a package of the main module as reported by go list
+type Package struct {
+	ImportPath   string
+	Dir          string
+	Deps         []string
+	TestImports  []string
+	XTestImports []string
+	Module       *struct{ Main bool }
+}
+
+// Packages is the import graph of the main module
+type Packages struct {
+	dir    string
+	byPath map[string]*Package
+	byDir  map[string]*Package
+}
+
+// LoadPackages lists the packages of the module in dir with
+// go list -deps -json
+func LoadPackages(dir string) (*Packages, error) {
+	cmd := exec.Command("go", "list", "-deps", "-json", "./...")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkgs := &Packages{dir: abs, byPath: map[string]*Package{}, byDir: map[string]*Package{}}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p Package
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if p.Module == nil || !p.Module.Main {
+			continue
+		}
+		pkgs.byPath[p.ImportPath] = &p
+		pkgs.byDir[p.Dir] = &p
+	}
+	return pkgs, nil
+}
+
+// All returns the import paths of every package of the module
+func (p *Packages) All() []string {
+	paths := make([]string, 0, len(p.byPath))
+	for path := range p.byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Affected returns the packages whose tests can observe a change to the
+// given files: the packages containing them and every package that
+// imports those, directly or through its tests. all is true when a
+// changed file is not Go code in a known package, e.g. a config file,
+// since any test may read it.
+func (p *Packages) Affected(files []string) (pkgs []string, all bool) {
+	changed := map[string]bool{}
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			return p.All(), true
+		}
+		dir := filepath.Dir(file)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(p.dir, dir)
+		}
+		pkg, ok := p.byDir[dir]
+		if !ok {
+			return p.All(), true
+		}
+		changed[pkg.ImportPath] = true
+	}
+
+	for _, pkg := range p.byPath {
+		if changed[pkg.ImportPath] || p.dependsOn(pkg, changed) {
+			pkgs = append(pkgs, pkg.ImportPath)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs, false
+}
+
+// dependsOn reports whether pkg or its tests import any changed package
+func (p *Packages) dependsOn(pkg *Package, changed map[string]bool) bool {
+	for _, dep := range pkg.Deps {
+		if changed[dep] {
+			return true
+		}
+	}
+	for _, imports := range [][]string{pkg.TestImports, pkg.XTestImports} {
+		for _, imp := range imports {
+			if changed[imp] {
+				return true
+			}
+			if dep, ok := p.byPath[imp]; ok {
+				for _, d := range dep.Deps {
+					if changed[d] {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
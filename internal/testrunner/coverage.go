@@ -0,0 +1,267 @@
+package testrunner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/cover"
+)
+
+// Coverage is a parsed coverage profile
+type Coverage struct {
+	Files []*FileCoverage
+}
+
+// FileCoverage is the coverage of one source file
+type FileCoverage struct {
+	// Path is relative to the module root
+	Path       string
+	Package    string
+	Statements int
+	Covered    int
+	// Lines maps line numbers to hit counts
+	Lines map[int]int
+}
+
+// PackageCoverage is the statement coverage of one package
+type PackageCoverage struct {
+	Package    string
+	Statements int
+	Covered    int
+}
+
+// Percent returns the covered share of statements, 100 for a package
+// without statements
+func (p PackageCoverage) Percent() float64 {
+	return percent(p.Covered, p.Statements)
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(covered) * 100 / float64(total)
+}
+
+// LoadCoverage parses a profile written by go test -coverprofile in the
+// module in dir
+func LoadCoverage(dir, profile string) (*Coverage, error) {
+	profiles, err := cover.ParseProfiles(profile)
+	if err != nil {
+		return nil, err
+	}
+	module, err := modulePath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Coverage{}
+	for _, p := range profiles {
+		f := &FileCoverage{
+			Path:    strings.TrimPrefix(p.FileName, module+"/"),
+			Package: path.Dir(p.FileName),
+			Lines:   map[int]int{},
+		}
+		for _, b := range p.Blocks {
+			f.Statements += b.NumStmt
+			if b.Count > 0 {
+				f.Covered += b.NumStmt
+			}
+			for line := b.StartLine; line <= b.EndLine; line++ {
+				if hits, ok := f.Lines[line]; !ok || b.Count > hits {
+					f.Lines[line] = b.Count
+				}
+			}
+		}
+		c.Files = append(c.Files, f)
+	}
+	return c, nil
+}
+
+func modulePath(dir string) (string, error) {
+	cmd := exec.Command("go", "list", "-m")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read module path: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Packages returns the coverage of each package in order
+func (c *Coverage) Packages() []PackageCoverage {
+	byPkg := map[string]*PackageCoverage{}
+	var names []string
+	for _, f := range c.Files {
+		p, ok := byPkg[f.Package]
+		if !ok {
+			p = &PackageCoverage{Package: f.Package}
+			byPkg[f.Package] = p
+			names = append(names, f.Package)
+		}
+		p.Statements += f.Statements
+		p.Covered += f.Covered
+	}
+	sort.Strings(names)
+	out := make([]PackageCoverage, 0, len(names))
+	for _, name := range names {
+		out = append(out, *byPkg[name])
+	}
+	return out
+}
+
+// Percent returns the statement coverage of the whole profile
+func (c *Coverage) Percent() float64 {
+	covered, total := 0, 0
+	for _, f := range c.Files {
+		covered += f.Covered
+		total += f.Statements
+	}
+	return percent(covered, total)
+}
+
+// sortedLines returns the line numbers of a file in order
+func (f *FileCoverage) sortedLines() []int {
+	lines := make([]int, 0, len(f.Lines))
+	for line := range f.Lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
+
+// linesHit returns the number of lines hit at least once
+func (f *FileCoverage) linesHit() int {
+	hit := 0
+	for _, hits := range f.Lines {
+		if hits > 0 {
+			hit++
+		}
+	}
+	return hit
+}
+
+// WriteLCOV writes the coverage in LCOV tracefile format
+func (c *Coverage) WriteLCOV(file string) error {
+	var b bytes.Buffer
+	for _, f := range c.Files {
+		b.WriteString("TN:\n")
+		fmt.Fprintf(&b, "SF:%s\n", f.Path)
+		for _, line := range f.sortedLines() {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, f.Lines[line])
+		}
+		fmt.Fprintf(&b, "LF:%d\n", len(f.Lines))
+		fmt.Fprintf(&b, "LH:%d\n", f.linesHit())
+		b.WriteString("end_of_record\n")
+	}
+	return writeFile(file, b.Bytes())
+}
+
+type coberturaCoverage struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        string             `xml:"line-rate,attr"`
+	BranchRate      string             `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      int                `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Complexity int             `xml:"complexity,attr"`
+	Methods    struct{}        `xml:"methods"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// WriteCobertura writes the coverage as Cobertura XML with one class per
+// file
+func (c *Coverage) WriteCobertura(file string) error {
+	doc := coberturaCoverage{
+		BranchRate: "0",
+		Version:    "meba",
+		Timestamp:  time.Now().UnixMilli(),
+		Sources:    []string{"."},
+	}
+	byPkg := map[string]*coberturaPackage{}
+	pkgHit, pkgValid := map[string]int{}, map[string]int{}
+	var names []string
+	for _, f := range c.Files {
+		p, ok := byPkg[f.Package]
+		if !ok {
+			p = &coberturaPackage{Name: f.Package, BranchRate: "0"}
+			byPkg[f.Package] = p
+			names = append(names, f.Package)
+		}
+		class := coberturaClass{
+			Name:       strings.TrimSuffix(filepath.Base(f.Path), ".go"),
+			Filename:   f.Path,
+			LineRate:   rate(f.linesHit(), len(f.Lines)),
+			BranchRate: "0",
+		}
+		for _, line := range f.sortedLines() {
+			class.Lines = append(class.Lines, coberturaLine{Number: line, Hits: f.Lines[line]})
+		}
+		p.Classes = append(p.Classes, class)
+		pkgHit[f.Package] += f.linesHit()
+		pkgValid[f.Package] += len(f.Lines)
+		doc.LinesCovered += f.linesHit()
+		doc.LinesValid += len(f.Lines)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := byPkg[name]
+		p.LineRate = rate(pkgHit[name], pkgValid[name])
+		doc.Packages = append(doc.Packages, *p)
+	}
+	doc.LineRate = rate(doc.LinesCovered, doc.LinesValid)
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(file, append([]byte(xml.Header), append(data, '\n')...))
+}
+
+func rate(hit, total int) string {
+	if total == 0 {
+		return "1"
+	}
+	return fmt.Sprintf("%.4f", float64(hit)/float64(total))
+}
+
+func writeFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,91 @@
+package testrunner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Time      string      `xml:"time,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+	SystemOut string      `xml:"system-out,omitempty"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the result as JUnit XML, one suite per package. A
+// package that failed outside its tests, e.g. a build error, is reported
+// as an error case.
+func WriteJUnit(path string, r *Result) error {
+	suites := junitSuites{Time: junitTime(r.Elapsed)}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	for _, p := range r.Packages {
+		suite := junitSuite{Name: p.Name, Time: junitTime(p.Elapsed), Timestamp: timestamp}
+		for _, t := range p.Tests {
+			c := junitCase{Name: t.Name, Classname: p.Name, Time: junitTime(t.Elapsed)}
+			body := strings.Join(t.Output, "")
+			switch t.Status {
+			case StatusFail:
+				c.Failure = &junitMessage{Message: "Failed", Body: body}
+				suite.Failures++
+			case StatusSkip:
+				c.Skipped = &junitMessage{Message: "Skipped", Body: body}
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		if p.Status == StatusFail && suite.Failures == 0 {
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      "Package",
+				Classname: p.Name,
+				Time:      junitTime(p.Elapsed),
+				Error:     &junitMessage{Message: "Package failed", Body: strings.Join(p.Output, "")},
+			})
+			suite.Errors++
+		}
+		suite.Tests = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures + suite.Errors
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(path, append([]byte(xml.Header), append(data, '\n')...))
+}
+
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,126 @@
+package testrunner
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// printPackage reports a finished package on one line, followed by the
+// output of its failed tests
+func printPackage(out io.Writer, p *PackageResult) {
+	passed, failed, skipped := p.Counts()
+	var counts []string
+	if passed > 0 {
+		counts = append(counts, fmt.Sprintf("%d passed", passed))
+	}
+	if failed > 0 {
+		counts = append(counts, fmt.Sprintf("%d failed", failed))
+	}
+	if skipped > 0 {
+		counts = append(counts, fmt.Sprintf("%d skipped", skipped))
+	}
+	detail := strings.Join(counts, ", ")
+	if p.Coverage >= 0 && p.HasTests() {
+		detail += fmt.Sprintf(" · %.1f%% coverage", p.Coverage)
+	}
+
+	switch {
+	case p.Status == StatusFail:
+		fmt.Fprintf(out, "%s %s %s %s\n", color.RedString("❌ FAIL"), p.Name, duration(p.Elapsed), detail)
+		printFailure(out, p)
+	case !p.HasTests():
+		fmt.Fprintf(out, "%s\n", color.HiBlackString("⚪ %s (no tests)", p.Name))
+	case p.Status == StatusSkip || passed == 0:
+		fmt.Fprintf(out, "%s %s %s %s\n", color.YellowString("⏭️  SKIP"), p.Name, duration(p.Elapsed), detail)
+	default:
+		fmt.Fprintf(out, "%s %s %s %s\n", color.GreenString("✅ PASS"), p.Name, duration(p.Elapsed), detail)
+	}
+}
+
+// printFailure prints the output of failed tests, or the package output
+// when no test failed, e.g. for build errors and panics
+func printFailure(out io.Writer, p *PackageResult) {
+	printed := false
+	for _, t := range p.Tests {
+		if t.Status != StatusFail || hasFailedSubtest(p, t) {
+			continue
+		}
+		printed = true
+		fmt.Fprintf(out, "   %s %s\n", color.RedString("✗"), t.Name)
+		for _, line := range t.Output {
+			if isFrameworkLine(line) {
+				continue
+			}
+			// go test indents test output by four spaces; keep the
+			// rest, e.g. of multi-line messages and diffs
+			fmt.Fprintf(out, "      %s", strings.TrimPrefix(line, "    "))
+		}
+	}
+	if printed {
+		return
+	}
+	for _, line := range p.Output {
+		if coverageLine.MatchString(line) || strings.HasPrefix(line, "FAIL") {
+			continue
+		}
+		fmt.Fprintf(out, "   %s", line)
+	}
+}
+
+// hasFailedSubtest reports whether a failed subtest of t explains its
+// failure, so t itself need not be printed
+func hasFailedSubtest(p *PackageResult, t *TestResult) bool {
+	for _, sub := range p.Tests {
+		if sub.Status == StatusFail && strings.HasPrefix(sub.Name, t.Name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isFrameworkLine reports lines go test adds around the test output
+func isFrameworkLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "--- FAIL", "--- PASS", "--- SKIP"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintSummary prints the totals of a run and its slowest tests
+func PrintSummary(out io.Writer, r *Result, slowest int) {
+	if tests := r.Slowest(slowest); len(tests) > 0 && tests[0].Elapsed >= 10*time.Millisecond {
+		fmt.Fprintln(out, "\n🐢 Slowest tests:")
+		for _, t := range tests {
+			if t.Elapsed < 10*time.Millisecond {
+				break
+			}
+			fmt.Fprintf(out, "   %8s  %s.%s\n", duration(t.Elapsed), t.Package, t.Name)
+		}
+	}
+
+	fmt.Fprintln(out)
+	line := fmt.Sprintf("Tests: %d passed, %d failed, %d skipped · Packages: %d", r.Passed, r.Failed, r.Skipped, len(r.Packages))
+	if len(r.Failures) > 0 {
+		line += fmt.Sprintf(", %d failed", len(r.Failures))
+	}
+	line += " · Time: " + duration(r.Elapsed)
+	if r.OK() {
+		fmt.Fprintln(out, color.GreenString("%s", line))
+	} else {
+		fmt.Fprintln(out, color.RedString("%s", line))
+	}
+}
+
+func duration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
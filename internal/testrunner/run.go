@@ -0,0 +1,346 @@
+package testrunner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Test and package statuses
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// Options configures a go test run
+type Options struct {
+	Dir string
+	// Packages to test; defaults to ./...
+	Packages []string
+	// Run is passed to go test -run
+	Run   string
+	Race  bool
+	Short bool
+	// Count is passed to go test -count when positive
+	Count int
+	// CoverProfile enables coverage and writes the profile to this path
+	CoverProfile string
+	// Verbose streams the whole test output instead of the summary
+	Verbose bool
+	// Env is added to the environment of go test, e.g. UPDATE_SNAPSHOTS=1
+	Env []string
+	// Output receives the report; defaults to os.Stdout
+	Output io.Writer
+}
+
+// Event is one line of go test -json output
+type Event struct {
+	Time        time.Time
+	Action      string
+	Package     string
+	ImportPath  string
+	Test        string
+	Output      string
+	Elapsed     float64
+	FailedBuild string
+}
+
+// TestResult is the outcome of one test or subtest
+type TestResult struct {
+	Package string
+	Name    string
+	Status  string
+	Elapsed time.Duration
+	Output  []string
+}
+
+// PackageResult is the outcome of one package
+type PackageResult struct {
+	Name    string
+	Status  string
+	Elapsed time.Duration
+	// Coverage is the statement coverage in percent, or -1 when not
+	// measured
+	Coverage float64
+	Tests    []*TestResult
+	// Output is the package output not attributed to a test, e.g. build
+	// errors and panics
+	Output []string
+}
+
+// Counts returns the number of passed, failed and skipped top-level tests
+func (p *PackageResult) Counts() (passed, failed, skipped int) {
+	for _, t := range p.Tests {
+		if strings.Contains(t.Name, "/") {
+			continue
+		}
+		switch t.Status {
+		case StatusPass:
+			passed++
+		case StatusFail:
+			failed++
+		case StatusSkip:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// HasTests reports whether the package has any test
+func (p *PackageResult) HasTests() bool {
+	return len(p.Tests) > 0
+}
+
+// Result summarises a go test run
+type Result struct {
+	Packages []*PackageResult
+	Passed   int
+	Failed   int
+	Skipped  int
+	Elapsed  time.Duration
+	// Failures maps each failed package to its failed top-level tests. A
+	// package that failed to build or outside a test has no test names.
+	Failures map[string][]string
+}
+
+// OK reports whether every package passed
+func (r *Result) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// FailedPackages returns the failed packages in order
+func (r *Result) FailedPackages() []string {
+	pkgs := make([]string, 0, len(r.Failures))
+	for pkg := range r.Failures {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// Slowest returns the n slowest top-level tests
+func (r *Result) Slowest(n int) []*TestResult {
+	var tests []*TestResult
+	for _, p := range r.Packages {
+		for _, t := range p.Tests {
+			if !strings.Contains(t.Name, "/") && t.Status != StatusSkip {
+				tests = append(tests, t)
+			}
+		}
+	}
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].Elapsed > tests[j].Elapsed })
+	if len(tests) > n {
+		tests = tests[:n]
+	}
+	return tests
+}
+
+// Args returns the go test arguments for the options
+func (o Options) Args() []string {
+	args := []string{"test", "-json"}
+	if o.Run != "" {
+		args = append(args, "-run", o.Run)
+	}
+	if o.Race {
+		args = append(args, "-race")
+	}
+	if o.Short {
+		args = append(args, "-short")
+	}
+	if o.Count > 0 {
+		args = append(args, "-count", strconv.Itoa(o.Count))
+	}
+	if o.CoverProfile != "" {
+		args = append(args, "-coverprofile", o.CoverProfile)
+	}
+	if len(o.Packages) == 0 {
+		return append(args, "./...")
+	}
+	return append(args, o.Packages...)
+}
+
+// Run runs go test -json, reports each package as it finishes and returns
+// the collected results
+func Run(opts Options) (*Result, error) {
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	cmd := exec.Command("go", opts.Args()...)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Stderr = out
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	c := newCollector(out, opts.Verbose)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// Not an event, e.g. output of a test binary that wrote to
+			// stdout before the test framework started
+			fmt.Fprintln(out, scanner.Text())
+			continue
+		}
+		c.record(e)
+	}
+	err = cmd.Wait()
+
+	result := c.result()
+	result.Elapsed = time.Since(start)
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || result.OK()) {
+		return result, fmt.Errorf("go test failed: %w", err)
+	}
+	return result, nil
+}
+
+var coverageLine = regexp.MustCompile(`coverage: ([\d.]+)% of statements`)
+
+// collector builds the result from the event stream
+type collector struct {
+	out      io.Writer
+	verbose  bool
+	packages map[string]*PackageResult
+	order    []string
+	tests    map[string]*TestResult
+	// builds holds build output by import path until the build fails
+	builds map[string][]string
+}
+
+func newCollector(out io.Writer, verbose bool) *collector {
+	return &collector{
+		out:      out,
+		verbose:  verbose,
+		packages: map[string]*PackageResult{},
+		tests:    map[string]*TestResult{},
+		builds:   map[string][]string{},
+	}
+}
+
+func (c *collector) pkg(name string) *PackageResult {
+	p, ok := c.packages[name]
+	if !ok {
+		p = &PackageResult{Name: name, Coverage: -1}
+		c.packages[name] = p
+		c.order = append(c.order, name)
+	}
+	return p
+}
+
+func (c *collector) record(e Event) {
+	if c.verbose && (e.Action == "output" || e.Action == "build-output") {
+		fmt.Fprint(c.out, e.Output)
+	}
+
+	switch e.Action {
+	case "build-output":
+		c.builds[e.ImportPath] = append(c.builds[e.ImportPath], e.Output)
+		return
+	case "build-fail":
+		// The import path of a test build looks like "pkg [pkg.test]"
+		name, _, _ := strings.Cut(e.ImportPath, " ")
+		p := c.pkg(name)
+		p.Output = append(p.Output, c.builds[e.ImportPath]...)
+		return
+	}
+	if e.Package == "" {
+		return
+	}
+	p := c.pkg(e.Package)
+
+	if e.Test == "" {
+		switch e.Action {
+		case "output":
+			if m := coverageLine.FindStringSubmatch(e.Output); m != nil {
+				p.Coverage, _ = strconv.ParseFloat(m[1], 64)
+			}
+			p.Output = append(p.Output, e.Output)
+		case StatusPass, StatusFail, StatusSkip:
+			p.Status = e.Action
+			p.Elapsed = seconds(e.Elapsed)
+			if !c.verbose {
+				printPackage(c.out, p)
+			}
+		}
+		return
+	}
+
+	key := e.Package + "\x00" + e.Test
+	t, ok := c.tests[key]
+	if !ok {
+		t = &TestResult{Package: e.Package, Name: e.Test}
+		c.tests[key] = t
+		p.Tests = append(p.Tests, t)
+	}
+	switch e.Action {
+	case "output":
+		t.Output = append(t.Output, e.Output)
+	case StatusPass, StatusFail, StatusSkip:
+		t.Status = e.Action
+		t.Elapsed = seconds(e.Elapsed)
+	}
+}
+
+func (c *collector) result() *Result {
+	r := &Result{Failures: map[string][]string{}}
+	for _, name := range c.order {
+		p := c.packages[name]
+		if p.Status == "" {
+			// A package without a final event failed to build
+			p.Status = StatusFail
+			if !c.verbose {
+				printPackage(c.out, p)
+			}
+		}
+		r.Packages = append(r.Packages, p)
+
+		passed, failed, skipped := p.Counts()
+		r.Passed += passed
+		r.Failed += failed
+		r.Skipped += skipped
+		if p.Status != StatusFail {
+			continue
+		}
+		r.Failures[p.Name] = nil
+		for _, t := range p.Tests {
+			if t.Status == StatusFail && !strings.Contains(t.Name, "/") {
+				r.Failures[p.Name] = append(r.Failures[p.Name], t.Name)
+			}
+		}
+	}
+	return r
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RunPattern returns a go test -run pattern matching exactly the given
+// top-level tests
+func RunPattern(tests []string) string {
+	quoted := make([]string, 0, len(tests))
+	for _, t := range tests {
+		quoted = append(quoted, regexp.QuoteMeta(t))
+	}
+	return "^(" + strings.Join(quoted, "|") + ")$"
+}
@@ -0,0 +1,40 @@
+package testrunner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SnapshotDrift is a snapshot that no longer matches its test, as reported
+// by the snapshot package of generated projects
+type SnapshotDrift struct {
+	Package string
+	Test    string
+	// File is relative to the package directory
+	File    string
+	Missing bool
+}
+
+var snapshotLine = regexp.MustCompile(`snapshot (drift|missing) in ([^\s:;]+)`)
+
+// SnapshotDrifts returns the snapshots reported by failed tests
+func (r *Result) SnapshotDrifts() []SnapshotDrift {
+	var drifts []SnapshotDrift
+	seen := map[string]bool{}
+	for _, p := range r.Packages {
+		for _, t := range p.Tests {
+			if t.Status != StatusFail {
+				continue
+			}
+			for _, m := range snapshotLine.FindAllStringSubmatch(strings.Join(t.Output, ""), -1) {
+				key := p.Name + "/" + m[2]
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+				drifts = append(drifts, SnapshotDrift{Package: p.Name, Test: t.Name, File: m[2], Missing: m[1] == "missing"})
+			}
+		}
+	}
+	return drifts
+}
@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Process is a restartable child process, such as the dev server
+type Process struct {
+	Path string
+	Args []string
+	// KillTimeout is how long Stop waits after SIGTERM before SIGKILL
+	KillTimeout time.Duration
+	// OnExit is called when the process exits without being stopped
+	OnExit func(err error)
+
+	cmd  *exec.Cmd
+	done chan struct{}
+	// stopping is set while Stop waits so the exit is not reported
+	stopping atomic.Bool
+}
+
+// Start runs the process with the standard streams of meba
+func (p *Process) Start() error {
+	cmd := exec.Command(p.Path, p.Args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	p.cmd, p.done = cmd, done
+	p.stopping.Store(false)
+	go func() {
+		err := cmd.Wait()
+		stopped := p.stopping.Load()
+		close(done)
+		if !stopped && p.OnExit != nil {
+			p.OnExit(err)
+		}
+	}()
+	return nil
+}
+
+// Running reports whether the process has been started and not exited
+func (p *Process) Running() bool {
+	if p.done == nil {
+		return false
+	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop sends SIGTERM and kills the process if it has not exited after
+// KillTimeout. Platforms without SIGTERM kill it right away.
+func (p *Process) Stop() error {
+	if !p.Running() {
+		return nil
+	}
+	p.stopping.Store(true)
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		p.cmd.Process.Kill()
+	}
+
+	timeout := p.KillTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(timeout):
+	}
+	if err := p.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill %s: %w", p.Path, err)
+	}
+	<-p.done
+	return fmt.Errorf("%s did not exit within %s and was killed", p.Path, timeout)
+}
+
+// Restart stops the process if it is running and starts it again. A
+// process that had to be killed is still restarted; the returned error
+// reports the kill.
+func (p *Process) Restart() error {
+	stopErr := p.Stop()
+	if err := p.Start(); err != nil {
+		return err
+	}
+	return stopErr
+}
@@ -0,0 +1,244 @@
+package watcher
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Config controls which files are watched and how changes are batched
+type Config struct {
+	// Root is the directory to watch recursively
+	Root string
+	// Include lists the globs of files that trigger a change. Globs are
+	// slash-separated, relative to Root, and support ** for any number of
+	// directories.
+	Include []string
+	// Exclude lists globs of files and directories to ignore; it wins over
+	// Include
+	Exclude []string
+	// Delay is how long to wait for more events before reporting a change
+	Delay time.Duration
+	// KillTimeout is how long a restarted process gets to exit after
+	// SIGTERM before it is killed
+	KillTimeout time.Duration
+}
+
+// DefaultConfig returns the configuration used when the project sets none
+func DefaultConfig() Config {
+	return Config{
+		Root:    ".",
+		Include: []string{"**/*.go", "configs/**/*.yaml", "configs/**/*.yml", "**/*.tmpl", "**/*.html"},
+		Exclude: []string{
+			".git/**", "dist/**", "tmp/**", "vendor/**", "node_modules/**", "coverage/**", "clients/**",
+			"**/wire_gen.go", "docs/**",
+		},
+		Delay:       300 * time.Millisecond,
+		KillTimeout: 5 * time.Second,
+	}
+}
+
+// Watcher reports batches of changed files under a directory tree
+type Watcher struct {
+	config  Config
+	fs      *fsnotify.Watcher
+	watched map[string]bool
+}
+
+// New creates a watcher and registers every directory under cfg.Root that
+// is not excluded
+func New(cfg Config) (*Watcher, error) {
+	if cfg.Root == "" {
+		cfg.Root = "."
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{config: cfg, fs: fsw, watched: map[string]bool{}}
+	if err := w.addTree(cfg.Root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Watch calls onChange with the sorted, slash-separated paths changed in
+// each batch of events until ctx is done. Events are batched until no new
+// event arrives for Config.Delay.
+func (w *Watcher) Watch(ctx context.Context, onChange func(files []string)) error {
+	pending := map[string]bool{}
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case _, ok := <-w.fs.Errors:
+			// Errors such as a full event queue only mean events were
+			// lost; keep watching
+			if !ok {
+				return nil
+			}
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			rel := w.rel(event.Name)
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// New directories are not watched by fsnotify; add them
+					// and report the files they already contain
+					w.addTree(event.Name)
+					for _, f := range w.files(event.Name) {
+						pending[f] = true
+					}
+					timer.Reset(w.config.Delay)
+					continue
+				}
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			if !w.Matches(rel) {
+				continue
+			}
+			pending[rel] = true
+			timer.Reset(w.config.Delay)
+		case <-timer.C:
+			if len(pending) == 0 {
+				continue
+			}
+			files := make([]string, 0, len(pending))
+			for f := range pending {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+			pending = map[string]bool{}
+			onChange(files)
+		}
+	}
+}
+
+// Matches reports whether a slash-separated path relative to Root is
+// included and not excluded
+func (w *Watcher) Matches(rel string) bool {
+	if matchAny(w.config.Exclude, rel) {
+		return false
+	}
+	return matchAny(w.config.Include, rel)
+}
+
+func (w *Watcher) addTree(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel := w.rel(p)
+		if rel != "." && (excludesDir(w.config.Exclude, rel) || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if w.watched[p] {
+			return nil
+		}
+		if err := w.fs.Add(p); err != nil {
+			return err
+		}
+		w.watched[p] = true
+		return nil
+	})
+}
+
+// files lists the matching files below dir
+func (w *Watcher) files(dir string) []string {
+	var out []string
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if rel := w.rel(p); w.Matches(rel) {
+				out = append(out, rel)
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+func (w *Watcher) rel(p string) string {
+	rel, err := filepath.Rel(w.config.Root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludesDir reports whether a directory is excluded as a whole, i.e.
+// an exclude pattern ending in /** matches it. The rest of the pattern is
+// a path even without a slash, so docs/** leaves internal/docs watched.
+func excludesDir(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		dir, ok := strings.CutSuffix(pattern, "/**")
+		if ok && matchSegments(strings.Split(dir, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether the slash-separated path matches the glob.
+// Besides the path.Match syntax, a ** segment matches any number of
+// directories, and a pattern without a slash matches the base name.
+func Match(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
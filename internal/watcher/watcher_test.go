@@ -0,0 +1,70 @@
+package watcher
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/users/handlers.go", true},
+		{"*.go", "README.md", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "internal/users/handlers.go", true},
+		{"configs/**/*.yaml", "configs/config.yaml", true},
+		{"configs/**/*.yaml", "configs/env/prod.yaml", true},
+		{"configs/**/*.yaml", "deploy/config.yaml", false},
+		{"docs/**", "docs/swagger.json", true},
+		{"docs/**", "internal/docs/docs.go", false},
+		{"internal/*/handlers.go", "internal/users/handlers.go", true},
+		{"internal/*/handlers.go", "internal/users/v2/handlers.go", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultConfigMatches(t *testing.T) {
+	w := &Watcher{config: DefaultConfig()}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"cmd/server/main.go", true},
+		{"internal/users/handlers.go", true},
+		{"configs/config.yaml", true},
+		{"templates/email.tmpl", true},
+		{"internal/wire_gen.go", false},
+		{"docs/docs.go", false},
+		{"vendor/github.com/gin-gonic/gin/gin.go", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		if got := w.Matches(tt.name); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExcludesDir(t *testing.T) {
+	exclude := DefaultConfig().Exclude
+	tests := []struct {
+		dir  string
+		want bool
+	}{
+		{".git", true},
+		{"vendor", true},
+		{"docs", true},
+		{"internal", false},
+		{"internal/docs", false},
+	}
+	for _, tt := range tests {
+		if got := excludesDir(exclude, tt.dir); got != tt.want {
+			t.Errorf("excludesDir(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+}
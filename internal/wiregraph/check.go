@@ -0,0 +1,369 @@
+package wiregraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Problem kinds reported by Check
+const (
+	ProblemMissing   = "missing"
+	ProblemDuplicate = "duplicate"
+	ProblemCycle     = "cycle"
+	ProblemUnused    = "unused"
+)
+
+// Problem is an issue in the provider graph. Unused providers are
+// warnings; everything else makes wire fail.
+type Problem struct {
+	Kind     string
+	Injector string
+	Message  string
+	Hint     string
+	// Type is the unresolved type of a missing provider
+	Type string
+	// Providers lists the providers involved, e.g. the members of a cycle
+	Providers []*Provider
+}
+
+// IsError reports whether the problem makes wire fail
+func (p *Problem) IsError() bool {
+	return p.Kind != ProblemUnused
+}
+
+// Edge is a dependency of one provider on another
+type Edge struct {
+	From *Provider
+	To   *Provider
+	Type string
+}
+
+// Flatten returns the providers of s and every set it includes
+func (s *Set) Flatten() []*Provider {
+	var out []*Provider
+	seen := map[*Set]bool{}
+	var walk func(*Set)
+	walk = func(s *Set) {
+		if seen[s] {
+			return
+		}
+		seen[s] = true
+		out = append(out, s.Providers...)
+		for _, inc := range s.Includes {
+			walk(inc)
+		}
+	}
+	walk(s)
+	return out
+}
+
+// Edges resolves the dependencies between providers of the same injector,
+// or of all sets when the project has no injector
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	seen := map[[2]*Provider]bool{}
+	for _, scope := range g.scopes() {
+		byType := providersByType(scope.providers)
+		for _, p := range scope.providers {
+			for _, t := range p.Requires {
+				for _, dep := range byType[t] {
+					if key := [2]*Provider{p, dep}; !seen[key] {
+						seen[key] = true
+						edges = append(edges, Edge{From: p, To: dep, Type: t})
+					}
+				}
+			}
+		}
+	}
+	return edges
+}
+
+// scope is the set of providers one injector can draw from
+type scope struct {
+	injector  *Injector
+	providers []*Provider
+}
+
+func (g *Graph) scopes() []scope {
+	if len(g.Injectors) > 0 {
+		scopes := make([]scope, 0, len(g.Injectors))
+		for _, inj := range g.Injectors {
+			scopes = append(scopes, scope{injector: inj, providers: inj.Set.Flatten()})
+		}
+		return scopes
+	}
+	// Without an injector every set not included elsewhere is a root
+	included := map[*Set]bool{}
+	for _, s := range g.Sets {
+		for _, inc := range s.Includes {
+			included[inc] = true
+		}
+	}
+	var scopes []scope
+	for _, s := range g.Sets {
+		if !included[s] {
+			scopes = append(scopes, scope{providers: s.Flatten()})
+		}
+	}
+	return scopes
+}
+
+func providersByType(providers []*Provider) map[string][]*Provider {
+	byType := map[string][]*Provider{}
+	for _, p := range providers {
+		for _, t := range p.Provides {
+			byType[t] = append(byType[t], p)
+		}
+	}
+	return byType
+}
+
+// Check reports missing providers, duplicate providers, cycles and
+// providers no injector needs
+func (g *Graph) Check() []*Problem {
+	var problems []*Problem
+	reported := map[string]bool{}
+	add := func(p *Problem) {
+		key := p.Kind + "|" + p.Message
+		if !reported[key] {
+			reported[key] = true
+			problems = append(problems, p)
+		}
+	}
+
+	// A provider is unused only when no injector needs it
+	usedBy := map[*Provider]bool{}
+	var candidates []*Provider
+	candidate := map[*Provider]bool{}
+
+	for _, sc := range g.scopes() {
+		injName := ""
+		if sc.injector != nil {
+			injName = sc.injector.Name
+		}
+		byType := providersByType(sc.providers)
+
+		types := make([]string, 0, len(byType))
+		for t := range byType {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			if ps := byType[t]; len(ps) > 1 {
+				var names []string
+				for _, p := range ps {
+					names = append(names, describe(p))
+				}
+				add(&Problem{
+					Kind:      ProblemDuplicate,
+					Injector:  injName,
+					Message:   fmt.Sprintf("%s is provided more than once: %s", t, strings.Join(names, ", ")),
+					Hint:      "keep one provider and remove the others from their sets",
+					Providers: ps,
+				})
+			}
+		}
+
+		inputs := map[string]bool{}
+		if sc.injector != nil {
+			for _, in := range sc.injector.Inputs {
+				inputs[in] = true
+			}
+		}
+
+		// Resolve from the injector output, or from every provider when
+		// there is no injector
+		used := map[*Provider]bool{}
+		var resolve func(t, neededBy string, from *Provider)
+		resolve = func(t, neededBy string, from *Provider) {
+			if inputs[t] {
+				return
+			}
+			ps := byType[t]
+			if len(ps) == 0 {
+				msg := fmt.Sprintf("no provider for %s, needed by %s", t, neededBy)
+				if injName != "" {
+					msg = fmt.Sprintf("%s: %s", injName, msg)
+				}
+				add(&Problem{
+					Kind:      ProblemMissing,
+					Injector:  injName,
+					Message:   msg,
+					Hint:      g.missingHint(t, from),
+					Type:      t,
+					Providers: providerList(from),
+				})
+				return
+			}
+			for _, p := range ps {
+				if used[p] {
+					continue
+				}
+				used[p] = true
+				for _, req := range p.Requires {
+					resolve(req, describe(p), p)
+				}
+			}
+		}
+		if sc.injector != nil {
+			if sc.injector.Output != "" {
+				resolve(sc.injector.Output, sc.injector.Name, nil)
+			}
+		} else {
+			for _, p := range sc.providers {
+				used[p] = true
+				for _, req := range p.Requires {
+					resolve(req, describe(p), p)
+				}
+			}
+		}
+
+		for _, cycle := range findCycles(sc.providers, byType) {
+			var names []string
+			for _, p := range cycle {
+				names = append(names, p.Name)
+			}
+			names = append(names, cycle[0].Name)
+			add(&Problem{
+				Kind:      ProblemCycle,
+				Injector:  injName,
+				Message:   "dependency cycle: " + strings.Join(names, " -> "),
+				Hint:      "depend on an interface bound with wire.Bind, or move the shared logic into a provider both can use",
+				Providers: cycle,
+			})
+		}
+
+		if sc.injector == nil {
+			continue
+		}
+		for _, p := range sc.providers {
+			if used[p] {
+				usedBy[p] = true
+			} else if !candidate[p] && !sharedProvider(p, sc.injector) {
+				candidate[p] = true
+				candidates = append(candidates, p)
+			}
+		}
+	}
+
+	for _, p := range candidates {
+		if usedBy[p] {
+			continue
+		}
+		add(&Problem{
+			Kind:      ProblemUnused,
+			Message:   fmt.Sprintf("%s provides %s but nothing built by an injector needs it", describe(p), strings.Join(p.Provides, ", ")),
+			Hint:      unusedHint(p),
+			Providers: []*Provider{p},
+		})
+	}
+	return problems
+}
+
+// sharedProvider reports whether p comes from a set declared next to the
+// injector, such as internal.ServiceSet or internal.MetricsSet. Those sets
+// offer providers for application code to depend on, so leaving some of
+// them unused is expected; the providers passed to wire.Build directly are
+// still checked.
+func sharedProvider(p *Provider, inj *Injector) bool {
+	return p.Set != nil && p.Set != inj.Set && p.Set.Package == inj.Set.Package
+}
+
+func (g *Graph) missingHint(t string, from *Provider) string {
+	target := "the set of the provider that needs it"
+	if from != nil && from.Set != nil {
+		target = from.Set.Name
+	}
+	if ctors := g.constructors[t]; len(ctors) > 0 {
+		return fmt.Sprintf("add %s to %s", strings.Join(ctors, " or "), target)
+	}
+	if strings.HasPrefix(t, "*") {
+		if ctors := g.constructors[strings.TrimPrefix(t, "*")]; len(ctors) > 0 {
+			return fmt.Sprintf("%s returns a value; return %s instead, or depend on the value type", strings.Join(ctors, ", "), t)
+		}
+	} else if ctors := g.constructors["*"+t]; len(ctors) > 0 {
+		return fmt.Sprintf("%s returns *%s; depend on the pointer, or bind the interface with wire.Bind", strings.Join(ctors, ", "), t)
+	}
+	return fmt.Sprintf("write a constructor returning %s and add it to %s", t, target)
+}
+
+func unusedHint(p *Provider) string {
+	for _, t := range p.Provides {
+		if strings.HasSuffix(t, ".Handlers") {
+			return fmt.Sprintf("inject %s into internal.Handlers and call its SetupRoutes, or remove %s", t, p.Set.Name)
+		}
+	}
+	return fmt.Sprintf("remove it from %s, or add a dependency on %s", p.Set.Name, strings.Join(p.Provides, ", "))
+}
+
+// findCycles returns each dependency cycle once, starting from the
+// provider declared first
+func findCycles(providers []*Provider, byType map[string][]*Provider) [][]*Provider {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := map[*Provider]int{}
+	var stack []*Provider
+	var cycles [][]*Provider
+	seen := map[string]bool{}
+
+	var visit func(p *Provider)
+	visit = func(p *Provider) {
+		state[p] = onStack
+		stack = append(stack, p)
+		for _, t := range p.Requires {
+			for _, dep := range byType[t] {
+				switch state[dep] {
+				case unvisited:
+					visit(dep)
+				case onStack:
+					var cycle []*Provider
+					for i := len(stack) - 1; i >= 0; i-- {
+						if stack[i] == dep {
+							cycle = append([]*Provider{}, stack[i:]...)
+							break
+						}
+					}
+					if key := cycleKey(cycle); !seen[key] {
+						seen[key] = true
+						cycles = append(cycles, cycle)
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[p] = done
+	}
+	for _, p := range providers {
+		if state[p] == unvisited {
+			visit(p)
+		}
+	}
+	return cycles
+}
+
+func cycleKey(cycle []*Provider) string {
+	ids := make([]string, 0, len(cycle))
+	for _, p := range cycle {
+		ids = append(ids, p.ID)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func describe(p *Provider) string {
+	if p.Set == nil {
+		return p.Name
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.Set.Name)
+}
+
+func providerList(p *Provider) []*Provider {
+	if p == nil {
+		return nil
+	}
+	return []*Provider{p}
+}
@@ -0,0 +1,443 @@
+// Package wiregraph loads the google/wire provider sets and injectors of a
+// meba project and checks them for missing providers, unused providers and
+// cycles without running wire.
+package wiregraph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const wirePkgPath = "github.com/google/wire"
+
+// Provider kinds
+const (
+	KindFunc   = "func"
+	KindStruct = "struct"
+	KindBind   = "bind"
+	KindValue  = "value"
+	KindFields = "fields"
+)
+
+// Graph holds every wire set and injector found in a project
+type Graph struct {
+	Module    string
+	Sets      []*Set
+	Providers []*Provider
+	Injectors []*Injector
+	Warnings  []string
+
+	// constructors maps a type to the project functions returning it, used
+	// to suggest fixes for missing providers
+	constructors map[string][]string
+}
+
+// Set is a package-level wire.NewSet variable such as users.Module
+type Set struct {
+	Name      string
+	Package   string
+	Position  token.Position
+	Providers []*Provider
+	Includes  []*Set
+}
+
+// Provider is one entry of a set: a constructor, wire.Struct, wire.Bind,
+// wire.Value or wire.FieldsOf
+type Provider struct {
+	ID       string
+	Name     string
+	Kind     string
+	Set      *Set
+	Provides []string
+	Requires []string
+	Position token.Position
+}
+
+// Injector is a function calling wire.Build, e.g. internal.InitializeApp
+type Injector struct {
+	Name     string
+	Position token.Position
+	// Set holds the arguments of wire.Build
+	Set    *Set
+	Inputs []string
+	Output string
+}
+
+// Load type-checks the project in dir with the wireinject build tag and
+// collects its sets and injectors
+func Load(dir string) (*Graph, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports |
+			packages.NeedDeps | packages.NeedModule,
+		Dir:        dir,
+		BuildFlags: []string{"-tags=wireinject"},
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go packages found in %s", dir)
+	}
+
+	l := &loader{
+		graph:    &Graph{constructors: map[string][]string{}},
+		setDecls: map[types.Object]setDecl{},
+		sets:     map[types.Object]*Set{},
+	}
+	for _, pkg := range pkgs {
+		if pkg.Module != nil && l.graph.Module == "" {
+			l.graph.Module = pkg.Module.Path
+		}
+		for _, e := range pkg.Errors {
+			l.warnf("%s", e.Error())
+		}
+		l.index(pkg)
+	}
+
+	objs := make([]types.Object, 0, len(l.setDecls))
+	for obj := range l.setDecls {
+		objs = append(objs, obj)
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		pi := l.setDecls[objs[i]].position()
+		pj := l.setDecls[objs[j]].position()
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	for _, obj := range objs {
+		l.set(obj)
+	}
+	for _, inj := range l.injectors {
+		l.injector(inj)
+	}
+	return l.graph, nil
+}
+
+// setDecl is the syntax of a package-level wire.NewSet variable
+type setDecl struct {
+	pkg  *packages.Package
+	call *ast.CallExpr
+	pos  token.Pos
+}
+
+func (d setDecl) position() token.Position {
+	return d.pkg.Fset.Position(d.pos)
+}
+
+// injectorDecl is a function whose body calls wire.Build
+type injectorDecl struct {
+	pkg  *packages.Package
+	decl *ast.FuncDecl
+	call *ast.CallExpr
+}
+
+type loader struct {
+	graph     *Graph
+	setDecls  map[types.Object]setDecl
+	sets      map[types.Object]*Set
+	injectors []injectorDecl
+}
+
+func (l *loader) warnf(format string, args ...interface{}) {
+	l.graph.Warnings = append(l.graph.Warnings, fmt.Sprintf(format, args...))
+}
+
+// index records set variables, injectors and constructors of a package
+func (l *loader) index(pkg *packages.Package) {
+	if pkg.TypesInfo == nil {
+		return
+	}
+	info := pkg.TypesInfo
+	for _, file := range pkg.Syntax {
+		for _, d := range file.Decls {
+			switch d := d.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					vs, ok := spec.(*ast.ValueSpec)
+					if !ok || len(vs.Names) != len(vs.Values) {
+						continue
+					}
+					for i, v := range vs.Values {
+						call, ok := v.(*ast.CallExpr)
+						if !ok || wireFunc(info, call) != "NewSet" {
+							continue
+						}
+						if obj := info.Defs[vs.Names[i]]; obj != nil {
+							l.setDecls[obj] = setDecl{pkg: pkg, call: call, pos: vs.Names[i].Pos()}
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				fn, ok := info.Defs[d.Name].(*types.Func)
+				if !ok {
+					continue
+				}
+				if d.Body != nil {
+					var build *ast.CallExpr
+					ast.Inspect(d.Body, func(n ast.Node) bool {
+						if call, ok := n.(*ast.CallExpr); ok && wireFunc(info, call) == "Build" {
+							build = call
+						}
+						return build == nil
+					})
+					if build != nil {
+						l.injectors = append(l.injectors, injectorDecl{pkg: pkg, decl: d, call: build})
+						continue
+					}
+				}
+				sig := fn.Type().(*types.Signature)
+				isConstructor := strings.HasPrefix(fn.Name(), "New") || strings.HasPrefix(fn.Name(), "Provide")
+				if isConstructor && sig.Recv() == nil && sig.Results().Len() > 0 {
+					out := typeString(sig.Results().At(0).Type())
+					l.graph.constructors[out] = append(l.graph.constructors[out], pkg.Name+"."+fn.Name())
+				}
+			}
+		}
+	}
+}
+
+// set builds the Set for a wire.NewSet variable, resolving included sets
+func (l *loader) set(obj types.Object) *Set {
+	if s, ok := l.sets[obj]; ok {
+		return s
+	}
+	decl, ok := l.setDecls[obj]
+	if !ok {
+		return nil
+	}
+	s := &Set{
+		Name:     decl.pkg.Name + "." + obj.Name(),
+		Package:  decl.pkg.Name,
+		Position: decl.position(),
+	}
+	l.sets[obj] = s
+	l.graph.Sets = append(l.graph.Sets, s)
+	l.addArgs(s, decl.pkg, decl.call.Args)
+	return s
+}
+
+func (l *loader) injector(inj injectorDecl) {
+	info := inj.pkg.TypesInfo
+	fn := info.Defs[inj.decl.Name].(*types.Func)
+	sig := fn.Type().(*types.Signature)
+
+	i := &Injector{
+		Name:     inj.pkg.Name + "." + fn.Name(),
+		Position: inj.pkg.Fset.Position(inj.decl.Pos()),
+	}
+	i.Set = &Set{Name: "wire.Build in " + i.Name, Package: inj.pkg.Name, Position: inj.pkg.Fset.Position(inj.call.Pos())}
+	for j := 0; j < sig.Params().Len(); j++ {
+		i.Inputs = append(i.Inputs, typeString(sig.Params().At(j).Type()))
+	}
+	if sig.Results().Len() > 0 {
+		i.Output = typeString(sig.Results().At(0).Type())
+	}
+	l.addArgs(i.Set, inj.pkg, inj.call.Args)
+	l.graph.Injectors = append(l.graph.Injectors, i)
+}
+
+// addArgs adds the arguments of wire.NewSet or wire.Build to s
+func (l *loader) addArgs(s *Set, pkg *packages.Package, args []ast.Expr) {
+	info := pkg.TypesInfo
+	for _, arg := range args {
+		pos := pkg.Fset.Position(arg.Pos())
+
+		if call, ok := arg.(*ast.CallExpr); ok {
+			switch name := wireFunc(info, call); name {
+			case "NewSet":
+				// an inline set behaves like its arguments
+				l.addArgs(s, pkg, call.Args)
+			case "Struct", "Bind", "Value", "InterfaceValue", "FieldsOf":
+				if p := l.special(info, name, call); p != nil {
+					p.Position = pos
+					l.addProvider(s, p)
+				}
+			default:
+				l.warnf("%s: unsupported provider expression %s", pos, types.ExprString(arg))
+			}
+			continue
+		}
+
+		obj := objectOf(info, arg)
+		switch obj := obj.(type) {
+		case *types.Func:
+			sig := obj.Type().(*types.Signature)
+			p := &Provider{Name: qualifiedName(obj), Kind: KindFunc, Position: pos}
+			if sig.Results().Len() > 0 {
+				p.Provides = []string{typeString(sig.Results().At(0).Type())}
+			}
+			for j := 0; j < sig.Params().Len(); j++ {
+				p.Requires = append(p.Requires, typeString(sig.Params().At(j).Type()))
+			}
+			l.addProvider(s, p)
+		case *types.Var:
+			if included := l.set(obj); included != nil {
+				s.Includes = append(s.Includes, included)
+			} else {
+				l.warnf("%s: set %s is declared outside the project and was not analyzed", pos, types.ExprString(arg))
+			}
+		default:
+			l.warnf("%s: unsupported provider expression %s", pos, types.ExprString(arg))
+		}
+	}
+}
+
+// special turns wire.Struct, wire.Bind, wire.Value, wire.InterfaceValue
+// and wire.FieldsOf calls into providers
+func (l *loader) special(info *types.Info, name string, call *ast.CallExpr) *Provider {
+	if len(call.Args) == 0 {
+		return nil
+	}
+	first := newType(info, call.Args[0])
+	switch name {
+	case "Struct":
+		if first == nil {
+			return nil
+		}
+		ptr, ok := first.(*types.Pointer)
+		if !ok {
+			return nil
+		}
+		p := &Provider{
+			Name:     "wire.Struct(" + typeString(ptr.Elem()) + ")",
+			Kind:     KindStruct,
+			Provides: []string{typeString(ptr.Elem()), typeString(ptr)},
+		}
+		if st, ok := ptr.Elem().Underlying().(*types.Struct); ok {
+			fields := map[string]bool{}
+			for _, a := range call.Args[1:] {
+				if v, ok := constString(info, a); ok {
+					fields[v] = true
+				}
+			}
+			for i := 0; i < st.NumFields(); i++ {
+				f := st.Field(i)
+				if fields["*"] || fields[f.Name()] {
+					p.Requires = append(p.Requires, typeString(f.Type()))
+				}
+			}
+		}
+		return p
+	case "Bind":
+		if len(call.Args) < 2 || first == nil {
+			return nil
+		}
+		iface, impl := first, newType(info, call.Args[1])
+		if impl == nil {
+			return nil
+		}
+		return &Provider{
+			Name:     "wire.Bind(" + typeString(iface) + ", " + typeString(impl) + ")",
+			Kind:     KindBind,
+			Provides: []string{typeString(iface)},
+			Requires: []string{typeString(impl)},
+		}
+	case "Value":
+		t := info.TypeOf(call.Args[0])
+		if t == nil {
+			return nil
+		}
+		return &Provider{Name: "wire.Value(" + typeString(t) + ")", Kind: KindValue, Provides: []string{typeString(t)}}
+	case "InterfaceValue":
+		if first == nil {
+			return nil
+		}
+		return &Provider{Name: "wire.InterfaceValue(" + typeString(first) + ")", Kind: KindValue, Provides: []string{typeString(first)}}
+	case "FieldsOf":
+		if first == nil {
+			return nil
+		}
+		p := &Provider{Name: "wire.FieldsOf(" + typeString(first) + ")", Kind: KindFields, Requires: []string{typeString(first)}}
+		t := first
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if st, ok := t.Underlying().(*types.Struct); ok {
+			for _, a := range call.Args[1:] {
+				name, ok := constString(info, a)
+				if !ok {
+					continue
+				}
+				for i := 0; i < st.NumFields(); i++ {
+					if st.Field(i).Name() == name {
+						p.Provides = append(p.Provides, typeString(st.Field(i).Type()))
+					}
+				}
+			}
+		}
+		return p
+	}
+	return nil
+}
+
+func (l *loader) addProvider(s *Set, p *Provider) {
+	p.Set = s
+	p.ID = fmt.Sprintf("p%d", len(l.graph.Providers)+1)
+	s.Providers = append(s.Providers, p)
+	l.graph.Providers = append(l.graph.Providers, p)
+}
+
+// wireFunc returns the name of the wire function called, if any
+func wireFunc(info *types.Info, call *ast.CallExpr) string {
+	fn := typeutil.StaticCallee(info, call)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != wirePkgPath {
+		return ""
+	}
+	return fn.Name()
+}
+
+// newType returns T for an argument of the form new(T)
+func newType(info *types.Info, expr ast.Expr) types.Type {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil
+	}
+	if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "new" {
+		return nil
+	}
+	return info.TypeOf(call.Args[0])
+}
+
+func objectOf(info *types.Info, expr ast.Expr) types.Object {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return info.ObjectOf(e)
+	case *ast.SelectorExpr:
+		return info.ObjectOf(e.Sel)
+	case *ast.ParenExpr:
+		return objectOf(info, e.X)
+	}
+	return nil
+}
+
+func constString(info *types.Info, expr ast.Expr) (string, bool) {
+	tv, ok := info.Types[expr]
+	if !ok || tv.Value == nil || tv.Value.Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(tv.Value), true
+}
+
+func qualifiedName(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	return fn.Pkg().Name() + "." + fn.Name()
+}
+
+// typeString renders a type qualified by package name, e.g. *users.Handlers
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string { return p.Name() })
+}
@@ -0,0 +1,266 @@
+package wiregraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Formats supported by Render
+var Formats = []string{"dot", "mermaid", "json"}
+
+// Render draws the graph in the given format, marking the providers
+// involved in problems
+func (g *Graph) Render(format string, problems []*Problem) ([]byte, error) {
+	switch format {
+	case "dot":
+		return []byte(g.dot(problems)), nil
+	case "mermaid":
+		return []byte(g.mermaid(problems)), nil
+	case "json":
+		return g.json(problems)
+	default:
+		return nil, fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// flagged maps providers to the most severe problem kind they are part of
+func flagged(problems []*Problem) map[*Provider]string {
+	marks := map[*Provider]string{}
+	for _, p := range problems {
+		for _, prov := range p.Providers {
+			if marks[prov] == "" || p.IsError() {
+				marks[prov] = p.Kind
+			}
+		}
+	}
+	return marks
+}
+
+// missing returns the missing-provider problems raised by a provider
+func missing(problems []*Problem) []*Problem {
+	var out []*Problem
+	for _, p := range problems {
+		if p.Kind == ProblemMissing && len(p.Providers) > 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// allSets returns every set including injector sets, in declaration order
+func (g *Graph) allSets() []*Set {
+	sets := append([]*Set{}, g.Sets...)
+	for _, inj := range g.Injectors {
+		sets = append(sets, inj.Set)
+	}
+	return sets
+}
+
+func (g *Graph) dot(problems []*Problem) string {
+	marks := flagged(problems)
+	var b strings.Builder
+	b.WriteString("digraph wire {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n\n")
+
+	for i, s := range g.allSets() {
+		if len(s.Providers) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%q;\n", s.Name)
+		b.WriteString("    style=dashed;\n")
+		for _, p := range s.Providers {
+			attrs := fmt.Sprintf("label=%q", p.Name+"\n"+strings.Join(p.Provides, ", "))
+			switch marks[p] {
+			case "":
+			case ProblemUnused:
+				attrs += ", color=gray, fontcolor=gray"
+			default:
+				attrs += ", color=red, fontcolor=red"
+			}
+			fmt.Fprintf(&b, "    %s [%s];\n", p.ID, attrs)
+		}
+		b.WriteString("  }\n")
+	}
+
+	for _, inj := range g.Injectors {
+		fmt.Fprintf(&b, "  %q [shape=doubleoctagon, label=%q];\n", inj.Name, inj.Name+"\n"+inj.Output)
+		for _, p := range providersByType(inj.Set.Flatten())[inj.Output] {
+			fmt.Fprintf(&b, "  %q -> %s;\n", inj.Name, p.ID)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %s -> %s [label=%q];\n", e.From.ID, e.To.ID, e.Type)
+	}
+
+	for i, p := range missing(problems) {
+		fmt.Fprintf(&b, "  missing%d [label=%q, color=red, fontcolor=red, style=\"rounded,dashed\"];\n", i, "missing\n"+p.Type)
+		fmt.Fprintf(&b, "  %s -> missing%d [color=red];\n", p.Providers[0].ID, i)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *Graph) mermaid(problems []*Problem) string {
+	marks := flagged(problems)
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for i, s := range g.allSets() {
+		if len(s.Providers) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "  subgraph set%d[%q]\n", i, s.Name)
+		for _, p := range s.Providers {
+			fmt.Fprintf(&b, "    %s[%q]\n", p.ID, mermaidText(p.Name+"<br/>"+strings.Join(p.Provides, ", ")))
+		}
+		b.WriteString("  end\n")
+	}
+	for i, inj := range g.Injectors {
+		fmt.Fprintf(&b, "  inj%d{{%q}}\n", i, mermaidText(inj.Name))
+		for _, p := range providersByType(inj.Set.Flatten())[inj.Output] {
+			fmt.Fprintf(&b, "  inj%d --> %s\n", i, p.ID)
+		}
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(&b, "  %s -->|%q| %s\n", e.From.ID, mermaidText(e.Type), e.To.ID)
+	}
+	for i, p := range missing(problems) {
+		fmt.Fprintf(&b, "  missing%d[%q]:::error\n", i, mermaidText("missing "+p.Type))
+		fmt.Fprintf(&b, "  %s --> missing%d\n", p.Providers[0].ID, i)
+	}
+
+	b.WriteString("  classDef error stroke:#d33,color:#d33\n")
+	b.WriteString("  classDef unused stroke:#999,color:#999,stroke-dasharray:4\n")
+	for _, p := range g.Providers {
+		switch marks[p] {
+		case "":
+		case ProblemUnused:
+			fmt.Fprintf(&b, "  class %s unused\n", p.ID)
+		default:
+			fmt.Fprintf(&b, "  class %s error\n", p.ID)
+		}
+	}
+	return b.String()
+}
+
+// mermaidText escapes characters mermaid treats as markup inside labels
+func mermaidText(s string) string {
+	return strings.NewReplacer("*", "#42;", "\"", "#quot;").Replace(s)
+}
+
+// jsonGraph is the --format json representation of a graph
+type jsonGraph struct {
+	Module    string         `json:"module"`
+	Sets      []jsonSet      `json:"sets"`
+	Providers []jsonProvider `json:"providers"`
+	Injectors []jsonInjector `json:"injectors"`
+	Edges     []jsonEdge     `json:"edges"`
+	Problems  []jsonProblem  `json:"problems"`
+}
+
+type jsonSet struct {
+	Name      string   `json:"name"`
+	Location  string   `json:"location"`
+	Providers []string `json:"providers"`
+	Includes  []string `json:"includes"`
+}
+
+type jsonProvider struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Set      string   `json:"set"`
+	Provides []string `json:"provides"`
+	Requires []string `json:"requires"`
+	Location string   `json:"location"`
+}
+
+type jsonInjector struct {
+	Name     string   `json:"name"`
+	Output   string   `json:"output"`
+	Inputs   []string `json:"inputs"`
+	Location string   `json:"location"`
+}
+
+type jsonEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+type jsonProblem struct {
+	Kind      string   `json:"kind"`
+	Error     bool     `json:"error"`
+	Injector  string   `json:"injector,omitempty"`
+	Message   string   `json:"message"`
+	Hint      string   `json:"hint"`
+	Providers []string `json:"providers"`
+}
+
+func (g *Graph) json(problems []*Problem) ([]byte, error) {
+	out := jsonGraph{
+		Module:    g.Module,
+		Sets:      []jsonSet{},
+		Providers: []jsonProvider{},
+		Injectors: []jsonInjector{},
+		Edges:     []jsonEdge{},
+		Problems:  []jsonProblem{},
+	}
+	for _, s := range g.Sets {
+		js := jsonSet{Name: s.Name, Location: s.Position.String(), Providers: []string{}, Includes: []string{}}
+		for _, p := range s.Providers {
+			js.Providers = append(js.Providers, p.ID)
+		}
+		for _, inc := range s.Includes {
+			js.Includes = append(js.Includes, inc.Name)
+		}
+		out.Sets = append(out.Sets, js)
+	}
+	for _, p := range g.Providers {
+		out.Providers = append(out.Providers, jsonProvider{
+			ID:       p.ID,
+			Name:     p.Name,
+			Kind:     p.Kind,
+			Set:      p.Set.Name,
+			Provides: nonNil(p.Provides),
+			Requires: nonNil(p.Requires),
+			Location: p.Position.String(),
+		})
+	}
+	for _, inj := range g.Injectors {
+		out.Injectors = append(out.Injectors, jsonInjector{
+			Name:     inj.Name,
+			Output:   inj.Output,
+			Inputs:   nonNil(inj.Inputs),
+			Location: inj.Position.String(),
+		})
+	}
+	for _, e := range g.Edges() {
+		out.Edges = append(out.Edges, jsonEdge{From: e.From.ID, To: e.To.ID, Type: e.Type})
+	}
+	for _, p := range problems {
+		jp := jsonProblem{Kind: p.Kind, Error: p.IsError(), Injector: p.Injector, Message: p.Message, Hint: p.Hint, Providers: []string{}}
+		for _, prov := range p.Providers {
+			jp.Providers = append(jp.Providers, prov.ID)
+		}
+		out.Problems = append(out.Problems, jp)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}